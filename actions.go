@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fileAction is a command offered for an entry based on what kind of file
+// it is. Its output is shown in the results pane.
+type fileAction struct {
+	label   string
+	command string
+	fn      func() (string, error) // built-in action, used instead of command
+	confirm bool                   // changes system state, so ask before running
+}
+
+// runAction starts the action, showing its output in the results pane.
+func (m *model) runAction(config models.ConfigEntry, action fileAction) tea.Cmd {
+	if action.fn != nil {
+		return m.runFuncInPane(config, action.label, action.fn)
+	}
+	return m.runInPane(config, action.command)
+}
+
+// isKind reports whether config is of the given type, either as registered
+// or as detected from its file name.
+func isKind(config models.ConfigEntry, kind string) bool {
+	return config.Type == kind || models.DetectFileType(config.Path) == kind
+}
+
+// actionState is the open action menu.
+type actionState struct {
+	config     models.ConfigEntry
+	items      []fileAction
+	cursor     int
+	confirming bool
+	single     bool // holds just the run command from x; cancelling closes it
+}
+
+// shellQuote quotes s for use as a single argument to the shell
+// shellCommand runs commands with.
+func shellQuote(s string) string {
+	return shellQuoteFor(runtime.GOOS, s)
+}
+
+// shellQuoteFor quotes s for sh, or for cmd.exe on windows. cmd has no
+// escape inside double quotes, so a % is left outside them as ^%.
+func shellQuoteFor(goos, s string) string {
+	safe := "@%_-+=:,./"
+	if goos == "windows" {
+		safe = "@_-+:./\\"
+	}
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(safe, r))
+	}) < 0 {
+		return s
+	}
+	if goos == "windows" {
+		s = strings.ReplaceAll(s, `"`, `""`)
+		return `"` + strings.ReplaceAll(s, "%", `"^%"`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// actionsFor lists the actions available for config: its own Run command
+// followed by those for its file type.
+func actionsFor(config models.ConfigEntry) []fileAction {
+	var items []fileAction
+	if config.Run != "" {
+		items = append(items, fileAction{label: "Run check command", command: config.Run})
+	}
+	if isKind(config, "systemd") {
+		items = append(items, systemdActions(config)...)
+	}
+	if isKind(config, "compose") {
+		items = append(items, composeActions(config)...)
+	}
+	if isKind(config, "terraform") {
+		items = append(items, fileAction{label: "Format with terraform fmt", command: "terraform fmt -diff " + shellQuote(editor.ExpandPath(config.Path))})
+	}
+	if isKind(config, "yaml") && (hasTag(config, "k8s") || hasTag(config, "kubernetes")) {
+		items = append(items, kubernetesActions(config)...)
+	}
+	if path := editor.ExpandPath(config.Path); !isKind(config, "compose") && len(composeFilesNear(path)) > 0 {
+		items = append(items, fileAction{
+			label: "Show compose services using this file",
+			fn:    func() (string, error) { return composeReferences(path) },
+		})
+	}
+	return items
+}
+
+// hasTag reports whether config carries tag, ignoring case.
+func hasTag(config models.ConfigEntry, tag string) bool {
+	for _, t := range config.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// kubernetesActions check a manifest without changing the cluster. Schema
+// validation uses kubeconform, or kubeval when only that is installed.
+func kubernetesActions(config models.ConfigEntry) []fileAction {
+	file := shellQuote(editor.ExpandPath(config.Path))
+	validate := "kubeconform -summary " + file
+	if _, err := exec.LookPath("kubeconform"); err != nil {
+		if _, err := exec.LookPath("kubeval"); err == nil {
+			validate = "kubeval " + file
+		}
+	}
+	return []fileAction{
+		{label: "Dry-run apply (client)", command: "kubectl apply --dry-run=client -f " + file},
+		{label: "Validate against schemas", command: validate},
+	}
+}
+
+// systemdActions control the unit a unit file defines. Files under a
+// systemd/user directory are managed with systemctl --user.
+func systemdActions(config models.ConfigEntry) []fileAction {
+	path := editor.ExpandPath(config.Path)
+	unit := shellQuote(filepath.Base(path))
+	systemctl := "systemctl"
+	if strings.Contains(filepath.ToSlash(path), "/systemd/user/") {
+		systemctl = "systemctl --user"
+	}
+	return []fileAction{
+		{label: "Show unit status", command: systemctl + " status --no-pager " + unit},
+		{label: "Reload units and restart", command: systemctl + " daemon-reload && " + systemctl + " restart " + unit, confirm: true},
+	}
+}
+
+// startActions opens the action menu for the selected entry.
+func (m *model) startActions() tea.Cmd {
+	config := m.getConfigByDisplayIndex(m.cursor)
+	if config == nil {
+		return nil
+	}
+	items := actionsFor(*config)
+	if len(items) == 0 {
+		return showWarning("No actions for " + config.Name)
+	}
+	m.actions = actionState{config: *config, items: items}
+	m.mode = ModeActions
+	return nil
+}
+
+func (m model) updateActions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.actions.confirming {
+		switch msg.String() {
+		case "y", "Y":
+			return m, m.runAction(m.actions.config, m.actions.items[m.actions.cursor])
+		case "n", "N", "esc":
+			m.actions.confirming = false
+			if m.actions.single {
+				m.mode = ModeNormal
+				m.actions = actionState{}
+				return m, showStatus("Not run")
+			}
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q", "X":
+		m.mode = ModeNormal
+		m.actions = actionState{}
+	case "j", "down":
+		if m.actions.cursor < len(m.actions.items)-1 {
+			m.actions.cursor++
+		}
+	case "k", "up":
+		if m.actions.cursor > 0 {
+			m.actions.cursor--
+		}
+	case "enter":
+		action := m.actions.items[m.actions.cursor]
+		if m.settings.ConfirmRun(action.confirm) {
+			m.actions.confirming = true
+			return m, nil
+		}
+		return m, m.runAction(m.actions.config, action)
+	}
+	return m, nil
+}
+
+// renderActionsPanel lists the actions in place of the file list.
+func (m model) renderActionsPanel() string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("117")).
+		Padding(0, 1)
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render("Actions for " + m.actions.config.Name)
+	labels := make([]string, len(m.actions.items))
+	labelWidth := 0
+	for i, action := range m.actions.items {
+		labels[i] = action.label
+		if m.settings.ConfirmRun(action.confirm) {
+			labels[i] += " (asks first)"
+		}
+		labelWidth = max(labelWidth, len(labels[i]))
+	}
+
+	lines := []string{title, ""}
+	for i, action := range m.actions.items {
+		label := fmt.Sprintf("%-*s  ", labelWidth, labels[i])
+		command := "$ " + action.command
+		if action.fn != nil {
+			command = "built in"
+		}
+		command = truncate(command, max(innerWidth-len(label), 3))
+		if i == m.actions.cursor {
+			lines = append(lines, lipgloss.NewStyle().
+				Foreground(lipgloss.Color("230")).
+				Background(lipgloss.Color("62")).
+				Width(innerWidth).
+				Render(label+command))
+			continue
+		}
+		lines = append(lines, label+suitechrome.Dim(command))
+	}
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(m.mainContentHeight() - panelStyle.GetVerticalFrameSize()).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSystemdActionsUseUserManagerForUserUnits(t *testing.T) {
+	items := actionsFor(models.ConfigEntry{Path: "/home/u/.config/systemd/user/sync.service", Type: "systemd"})
+	if len(items) != 2 {
+		t.Fatalf("actions = %+v, want status and restart", items)
+	}
+	if items[0].command != "systemctl --user status --no-pager sync.service" {
+		t.Fatalf("status command = %q", items[0].command)
+	}
+	if !items[1].confirm || !strings.Contains(items[1].command, "daemon-reload") {
+		t.Fatalf("restart action = %+v, want a confirmed daemon-reload", items[1])
+	}
+}
+
+func TestConfirmedActionWaitsForYes(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{{Name: "web", Path: "/etc/systemd/system/web.service", Type: "systemd"}}
+	m.buildDisplayList()
+
+	keys := func(ks ...string) tea.Cmd {
+		var cmd tea.Cmd
+		for _, k := range ks {
+			var updated tea.Model
+			updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+			m = updated.(model)
+		}
+		return cmd
+	}
+	keys("X", "j")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if cmd != nil || !m.actions.confirming {
+		t.Fatal("restart should ask for confirmation before running")
+	}
+	if keys("n"); m.actions.confirming || m.mode != ModeActions {
+		t.Fatal("n should cancel the confirmation and stay in the menu")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for _, tc := range []struct {
+		goos, s, want string
+	}{
+		{"linux", "web.service", "web.service"},
+		{"linux", "it's here", `'it'\''s here'`},
+		{"linux", "a=b,c", "a=b,c"},
+		{"windows", `C:\srv\web.service`, `C:\srv\web.service`},
+		{"windows", `C:\My Configs\app.yaml`, `"C:\My Configs\app.yaml"`},
+		{"windows", `C:\a=b,c`, `"C:\a=b,c"`},
+		{"windows", `C:\%TEMP%\x & del y`, `"C:\"^%"TEMP"^%"\x & del y"`},
+		{"windows", "it's here", `"it's here"`},
+	} {
+		if got := shellQuoteFor(tc.goos, tc.s); got != tc.want {
+			t.Errorf("shellQuoteFor(%s, %q) = %s, want %s", tc.goos, tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestComposeUsesFindsBuildsMountsAndEnvFiles(t *testing.T) {
+	var cfg composeConfig
+	err := json.Unmarshal([]byte(`{"services": {
+		"api":   {"build": {"context": "/srv/app", "dockerfile": "Dockerfile"}, "env_file": ["/srv/app/.env"]},
+		"proxy": {"volumes": [{"type": "bind", "source": "/srv/app/nginx", "target": "/etc/nginx"}]},
+		"db":    {"volumes": [{"type": "volume", "source": "data", "target": "/var/lib/db"}],
+		          "env_file": [{"path": "/srv/app/.env", "required": true}]}
+	}}`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := composeUses(cfg, "/srv/app/Dockerfile"); len(got) != 1 || got[0] != "api: builds from this Dockerfile" {
+		t.Fatalf("Dockerfile uses = %q", got)
+	}
+	if got := composeUses(cfg, "/srv/app/nginx/nginx.conf"); len(got) != 1 || got[0] != "proxy: mounted at /etc/nginx/nginx.conf" {
+		t.Fatalf("mounted file uses = %q", got)
+	}
+	if got := composeUses(cfg, "/srv/app/.env"); len(got) != 2 {
+		t.Fatalf("env file uses = %q, want both env_file forms", got)
+	}
+}
+
+func TestDetectFileTypeRecognisesContainerFiles(t *testing.T) {
+	for path, want := range map[string]string{
+		"/srv/app/Dockerfile":         "dockerfile",
+		"/srv/app/Dockerfile.dev":     "dockerfile",
+		"/srv/app/docker-compose.yml": "compose",
+		"/srv/app/compose.yaml":       "compose",
+		"/srv/app/values.yaml":        "yaml",
+	} {
+		if got := models.DetectFileType(path); got != want {
+			t.Fatalf("DetectFileType(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestKubernetesActionsNeedK8sTag(t *testing.T) {
+	manifest := models.ConfigEntry{Path: "/srv/k8s/deploy.yaml", Type: "yaml"}
+	if items := actionsFor(manifest); len(items) != 0 {
+		t.Fatalf("untagged yaml got actions %+v", items)
+	}
+	manifest.Tags = []string{"K8s"}
+	items := actionsFor(manifest)
+	if len(items) != 2 || items[0].command != "kubectl apply --dry-run=client -f /srv/k8s/deploy.yaml" {
+		t.Fatalf("k8s actions = %+v", items)
+	}
+}
+
+func TestTerraformFilesGetFormatAction(t *testing.T) {
+	if got := models.DetectFileType("/infra/main.tf"); got != "terraform" {
+		t.Fatalf("DetectFileType(main.tf) = %q, want terraform", got)
+	}
+	items := actionsFor(models.ConfigEntry{Path: "/infra/main.tf"})
+	if len(items) != 1 || items[0].command != "terraform fmt -diff /infra/main.tf" {
+		t.Fatalf("terraform actions = %+v", items)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/suitechrome"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// heatmapMaxWeeks caps the heatmap at a year of history.
+const heatmapMaxWeeks = 52
+
+// heatmapShades colour a day by activity, from no opens to the busiest days.
+var heatmapShades = []lipgloss.Color{"237", "22", "28", "34", "40"}
+
+// heatmapLevel buckets count into a shade index relative to the busiest day.
+func heatmapLevel(count, maxCount int) int {
+	if count <= 0 || maxCount <= 0 {
+		return 0
+	}
+	level := 1 + (count-1)*(len(heatmapShades)-1)/maxCount
+	if level >= len(heatmapShades) {
+		level = len(heatmapShades) - 1
+	}
+	return level
+}
+
+// heatmapWeeks is how many week columns fit in width.
+func heatmapWeeks(width int) int {
+	weeks := (width - 6) / 2
+	if weeks > heatmapMaxWeeks {
+		weeks = heatmapMaxWeeks
+	}
+	if weeks < 1 {
+		weeks = 1
+	}
+	return weeks
+}
+
+// renderHeatmap draws a GitHub-style grid of daily opens: one column per
+// week ending with the week of today, one row per weekday starting Sunday.
+func renderHeatmap(activity map[string]int, today time.Time, weeks int) []string {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	start := today.AddDate(0, 0, -int(today.Weekday())-7*(weeks-1))
+
+	maxCount, total := 0, 0
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		count := activity[day.Format(storage.ActivityDateFormat)]
+		total += count
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+
+	// Month labels sit above the first week that starts in a new month.
+	months := []rune(strings.Repeat(" ", 2*weeks+1))
+	lastMonth, free := time.Month(0), 0
+	for w := 0; w < weeks; w++ {
+		day := start.AddDate(0, 0, 7*w)
+		if day.Month() == lastMonth {
+			continue
+		}
+		lastMonth = day.Month()
+		label := []rune(day.Format("Jan"))
+		if 2*w < free || 2*w+len(label) > len(months) {
+			continue
+		}
+		copy(months[2*w:], label)
+		free = 2*w + len(label) + 1
+	}
+
+	lines := []string{"      " + suitechrome.Dim(strings.TrimRight(string(months), " "))}
+	dayLabels := []string{"", "Mon", "", "Wed", "", "Fri", ""}
+	for weekday := 0; weekday < 7; weekday++ {
+		var row strings.Builder
+		row.WriteString(fmt.Sprintf("  %-4s", dayLabels[weekday]))
+		for w := 0; w < weeks; w++ {
+			day := start.AddDate(0, 0, 7*w+weekday)
+			if day.After(today) {
+				break
+			}
+			level := heatmapLevel(activity[day.Format(storage.ActivityDateFormat)], maxCount)
+			row.WriteString(lipgloss.NewStyle().Foreground(heatmapShades[level]).Render("■") + " ")
+		}
+		lines = append(lines, suitechrome.Dim(row.String()[:6])+row.String()[6:])
+	}
+
+	var legend strings.Builder
+	for _, shade := range heatmapShades {
+		legend.WriteString(lipgloss.NewStyle().Foreground(shade).Render("■") + " ")
+	}
+	lines = append(lines, fmt.Sprintf("  %s  %s %s%s",
+		suitechrome.Dim(fmt.Sprintf("%d opens in %d weeks", total, weeks)),
+		suitechrome.Dim("less"), legend.String(), suitechrome.Dim("more")))
+	return lines
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/watch"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// checkState is the latest automatic run of an auto_check entry's command.
+type checkState struct {
+	running bool
+	again   bool // the file changed during the run, so check once more
+	done    bool // a run has finished
+	err     error
+	summary string // first line of output
+	at      time.Time
+}
+
+// checkFinishedMsg carries the result of an automatic check of path.
+type checkFinishedMsg struct {
+	path   string
+	result runFinishedMsg
+}
+
+// checkKey is the key of config's file in m.checks and the watcher.
+func checkKey(config models.ConfigEntry) string {
+	return filepath.Clean(editor.ExpandPath(config.Path))
+}
+
+// autoChecked reports whether config's command reruns when its file changes.
+// Entries from the shared registry never do: only auto-check turned on
+// locally with c runs commands by itself.
+func autoChecked(config models.ConfigEntry) bool {
+	return config.AutoCheck && config.Run != "" && !config.Shared
+}
+
+func (m model) hasAutoCheck() bool {
+	for _, config := range m.configs {
+		if autoChecked(config) {
+			return true
+		}
+	}
+	return false
+}
+
+// startCheck runs config's command in the background. A check already
+// running for the file is repeated once it finishes instead.
+func (m *model) startCheck(config models.ConfigEntry) tea.Cmd {
+	key := checkKey(config)
+	if m.checks == nil {
+		m.checks = make(map[string]*checkState)
+	}
+	state := m.checks[key]
+	if state == nil {
+		state = &checkState{}
+		m.checks[key] = state
+	}
+	if state.running {
+		state.again = true
+		return nil
+	}
+	state.running = true
+	exec := m.commandRunner()
+	return func() tea.Msg {
+		return checkFinishedMsg{path: key, result: exec(config, config.Run)}
+	}
+}
+
+// checkChanged starts the checks of auto_check entries for a changed file.
+func (m *model) checkChanged(path string) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, config := range m.configs {
+		if autoChecked(config) && checkKey(config) == path {
+			cmds = append(cmds, m.startCheck(config))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// finishCheck records a check result, reporting when a file starts or stops
+// failing, and reruns the check if the file changed meanwhile.
+func (m *model) finishCheck(msg checkFinishedMsg) tea.Cmd {
+	state := m.checks[msg.path]
+	if state == nil {
+		return nil
+	}
+	failedBefore := state.done && state.err != nil
+	state.running = false
+	state.done = true
+	state.err = msg.result.err
+	state.at = time.Now()
+	state.summary, _, _ = strings.Cut(strings.TrimSpace(msg.result.output), "\n")
+	m.refreshRightViewport()
+
+	var status tea.Cmd
+	name := filepath.Base(msg.path)
+	switch {
+	case state.err != nil && !failedBefore:
+		status = showError(fmt.Sprintf("Check failed for %s: %v", name, state.err))
+	case state.err == nil && failedBefore:
+		status = showSuccess(fmt.Sprintf("Check passes again for %s", name))
+	}
+
+	if state.again {
+		state.again = false
+		for _, config := range m.configs {
+			if autoChecked(config) && checkKey(config) == msg.path {
+				return tea.Batch(status, m.startCheck(config))
+			}
+		}
+	}
+	return status
+}
+
+// checkBadge marks list rows with the state of their automatic check.
+func (m model) checkBadge(config models.ConfigEntry) string {
+	if !autoChecked(config) {
+		return ""
+	}
+	state := m.checks[checkKey(config)]
+	switch {
+	case state == nil:
+		return ""
+	case state.running:
+		return " ⟳"
+	case state.err != nil:
+		return " ✗"
+	case state.done:
+		return " ✓"
+	}
+	return ""
+}
+
+// checkDetail describes the latest automatic check for the details pane.
+func (m model) checkDetail(config models.ConfigEntry) string {
+	state := m.checks[checkKey(config)]
+	switch {
+	case state == nil || (!state.done && !state.running):
+		return "Auto-check: on, runs when the file changes"
+	case state.running:
+		return "Auto-check: running…"
+	case state.err != nil:
+		detail := fmt.Sprintf("✗ failed %s: %v", m.formatTime(state.at, false), state.err)
+		if state.summary != "" {
+			detail += " · " + state.summary
+		}
+		return "Auto-check: " + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(detail)
+	default:
+		return "Auto-check: " + lipgloss.NewStyle().Foreground(lipgloss.Color("82")).Render("✓ passed "+m.formatTime(state.at, false))
+	}
+}
+
+// toggleAutoCheck turns automatic checks on or off for the selected (or
+// marked) entries that have a run command, starting the file watcher and a
+// first check when they are turned on.
+func (m *model) toggleAutoCheck() tea.Cmd {
+	targets := m.markedConfigs()
+	if len(targets) == 0 {
+		if config := m.getConfigByDisplayIndex(m.cursor); config != nil {
+			targets = []models.ConfigEntry{*config}
+		}
+	}
+	var runnable []models.ConfigEntry
+	for _, config := range targets {
+		if config.Run != "" {
+			runnable = append(runnable, config)
+		}
+	}
+	if len(runnable) == 0 {
+		if len(targets) == 1 {
+			return showWarning("No run command set for " + targets[0].Name + " (add one with e)")
+		}
+		return showWarning("None of the marked files have a run command")
+	}
+
+	enable := false
+	for _, config := range runnable {
+		if !autoChecked(config) {
+			enable = true
+		}
+	}
+	configs := make([]models.ConfigEntry, len(m.configs))
+	copy(configs, m.configs)
+	for _, target := range runnable {
+		for i := range configs {
+			if configs[i].Equals(&target) {
+				configs[i].AutoCheck = enable
+				configs[i].Shared = false
+			}
+		}
+	}
+	if err := m.storage.Save(configs); err != nil {
+		return showError(fmt.Sprintf("Failed to save: %v", err))
+	}
+	m.configs = configs
+	m.marked = nil
+
+	if !enable {
+		for _, config := range runnable {
+			delete(m.checks, checkKey(config))
+		}
+		m.cacheValid = false
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		return showSuccess(fmt.Sprintf("Auto-check off for %d file(s)", len(runnable)))
+	}
+
+	cmds := []tea.Cmd{showSuccess(fmt.Sprintf("Auto-check on for %d file(s)", len(runnable)))}
+	if m.watcher == nil {
+		watcher, err := watch.New(m.watchPaths())
+		if err != nil {
+			return showError(fmt.Sprintf("File watching unavailable: %v", err))
+		}
+		m.watcher = watcher
+		cmds = append(cmds, watcher.Next())
+	}
+	for _, config := range runnable {
+		cmds = append(cmds, m.startCheck(config))
+	}
+	m.cacheValid = false
+	m.buildDisplayList()
+	m.refreshRightViewport()
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+)
+
+// backupManifest is the name of the entry list inside a backup archive.
+const backupManifest = "zap-backup.json"
+
+// registryBackup lists the entries whose files a backup holds.
+type registryBackup struct {
+	Created time.Time     `json:"created"`
+	Entries []bundleEntry `json:"entries"`
+}
+
+// backupFileName mirrors a file's location inside a backup: files under the
+// home directory go below home/, others below root/, so the archive can be
+// browsed and restored on a machine with a different home.
+func backupFileName(p string) string {
+	p = filepath.ToSlash(contractHome(p))
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return path.Join("files/home", rest)
+	}
+	p = strings.Replace(p, ":", "", 1) // C:/Users → C/Users
+	return path.Join("files/root", strings.TrimPrefix(p, "/"))
+}
+
+// splitList splits a comma-separated flag value, dropping blanks.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// backupSelected reports whether config belongs to one of projects or has
+// one of tags. With neither given every entry is selected.
+func backupSelected(config models.ConfigEntry, projects, tags []string) bool {
+	if len(projects) == 0 && len(tags) == 0 {
+		return true
+	}
+	for _, project := range projects {
+		if strings.EqualFold(config.Project, project) {
+			return true
+		}
+	}
+	for _, tag := range tags {
+		if hasTag(config, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// runBackup archives the files themselves, not just their metadata, for
+// the selected projects and tags (or the whole registry).
+func runBackup(ctx *cliContext, args []string) error {
+	const usage = "backup [--project p,...] [--tag t,...] [--out file]"
+	fs := ctx.flagSet("backup")
+	out := fs.String("out", "", "Archive to write (default zap-backup-<date>.tar.gz)")
+	projectList := fs.String("project", "", "Back up only these projects (comma-separated)")
+	tagList := fs.String("tag", "", "Back up only files with these tags (comma-separated)")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageError(usage)
+	}
+	if *out == "" {
+		*out = "zap-backup-" + time.Now().Format("2006-01-02") + ".tar.gz"
+	}
+
+	_, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	projects, tags := splitList(*projectList), splitList(*tagList)
+	var selected []models.ConfigEntry
+	for _, config := range configs {
+		if backupSelected(config, projects, tags) {
+			selected = append(selected, config)
+		}
+	}
+	if len(selected) == 0 {
+		return notFoundf("no entries match the selected projects and tags")
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	backup := registryBackup{Created: time.Now()}
+	written := map[string]bool{}
+	skipped := 0
+	for _, config := range selected {
+		data, info, err := readWithInfo(editor.ExpandPath(config.Path))
+		if err != nil {
+			skipped++
+			if !ctx.quiet {
+				fmt.Fprintf(ctx.stderr, "skipped  %s  (%v)\n", config.Name, err)
+			}
+			continue
+		}
+		entry := bundleEntry{ConfigEntry: config, File: backupFileName(config.Path)}
+		entry.Path = contractHome(config.Path)
+		if !written[entry.File] {
+			written[entry.File] = true
+			// Keep the file's permissions and modification time for restoring
+			header := &tar.Header{Name: entry.File, Mode: int64(info.Mode().Perm()), Size: int64(len(data)), ModTime: info.ModTime()}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return err
+			}
+		}
+		backup.Entries = append(backup.Entries, entry)
+	}
+
+	manifest, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, backupManifest, manifest); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if !ctx.quiet {
+		fmt.Fprintf(ctx.stderr, "backed up %d files to %s\n", len(backup.Entries), *out)
+	}
+	if skipped > 0 {
+		return invalidf("%d files could not be read and are not in the backup", skipped)
+	}
+	return nil
+}
+
+func readWithInfo(path string) ([]byte, os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(path)
+	return data, info, err
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+func TestBackupArchivesTheSelectedFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on $HOME and Unix file modes")
+	}
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	t.Setenv("HOME", home)
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	write := func(path, content string, perm os.FileMode) string {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), perm); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	configs := []models.ConfigEntry{
+		{Name: "nginx", Project: "infra", Path: write(filepath.Join(dir, "srv", "nginx.conf"), "events {}\n", 0644)},
+		{Name: "deploy key", Project: "infra", Path: write(filepath.Join(home, ".ssh", "deploy"), "KEY\n", 0600)},
+		{Name: "manifest", Tags: []string{"k8s"}, Path: write(filepath.Join(home, "k8s", "app.yaml"), "kind: Pod\n", 0644)},
+		{Name: "zshrc", Project: "dotfiles", Path: write(filepath.Join(home, ".zshrc"), "setopt\n", 0644)},
+		{Name: "gone", Project: "infra", Path: filepath.Join(dir, "gone.conf")},
+	}
+	if err := storage.New(registry).Save(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "configs.tar.gz")
+	var log bytes.Buffer
+	ctx := &cliContext{stdout: &log, stderr: &log}
+	err := runBackup(ctx, []string{"--project", "infra", "--tag", "k8s", "--out", out})
+	if exitCode(err) != exitInvalid || !bytes.Contains(log.Bytes(), []byte("skipped  gone")) {
+		t.Fatalf("the missing file should be reported: %v\n%s", err, log.String())
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	modes := map[string]int64{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(tr)
+		files[header.Name] = string(content)
+		modes[header.Name] = header.Mode
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	nginx := "files/root/" + filepath.ToSlash(filepath.Join(dir, "srv", "nginx.conf"))[1:]
+	want := []string{"files/home/.ssh/deploy", "files/home/k8s/app.yaml", nginx, backupManifest}
+	sort.Strings(want)
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("archive holds %q, want %q", names, want)
+	}
+	if files["files/home/.ssh/deploy"] != "KEY\n" || modes["files/home/.ssh/deploy"] != 0600 {
+		t.Fatal("the key should be archived with its contents and permissions")
+	}
+
+	var backup registryBackup
+	if err := json.Unmarshal([]byte(files[backupManifest]), &backup); err != nil {
+		t.Fatal(err)
+	}
+	if len(backup.Entries) != 3 || backup.Entries[1].Path != "~/.ssh/deploy" || backup.Entries[1].File != "files/home/.ssh/deploy" {
+		t.Fatalf("manifest entries = %+v", backup.Entries)
+	}
+}
+
+func TestRestoreAsksForEachChangedFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on $HOME and Unix file modes")
+	}
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	t.Setenv("HOME", home)
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	zshrc := filepath.Join(home, ".zshrc")
+	key := filepath.Join(home, ".ssh", "deploy")
+	vimrc := filepath.Join(home, ".vimrc")
+	for path, content := range map[string]string{zshrc: "setopt autocd\n", key: "KEY\n", vimrc: "set nu\n"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configs := []models.ConfigEntry{
+		{Name: "zshrc", Path: zshrc, Project: "dotfiles", Run: "zsh -n .zshrc"},
+		{Name: "deploy key", Path: key, Project: "dotfiles"},
+		{Name: "vimrc", Path: vimrc, Project: "dotfiles"},
+	}
+	if err := storage.New(registry).Save(configs); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "backup.tar.gz")
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runBackup(ctx, []string{"--out", archive}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new machine: the registry is gone, one file changed, one is missing
+	if err := os.Remove(registry); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(zshrc, []byte("setopt autocd\nalias k=kubectl\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRestore(ctx, []string{archive}); exitCode(err) != exitInvalid {
+		t.Fatalf("restore without a terminal should need --yes or --dry-run: %v", err)
+	}
+
+	out.Reset()
+	ctx.interactive = true
+	ctx.stdin = strings.NewReader("n\ny\n")
+	if err := runRestore(ctx, []string{archive}); err != nil {
+		t.Fatalf("restore: %v\n%s", err, out.String())
+	}
+	for _, want := range []string{"changed    zshrc  ~/.zshrc", "-alias k=kubectl", "no run zsh -n .zshrc", "missing    deploy key", "unchanged  vimrc", "1 restored, 1 unchanged, 1 skipped, 3 added", "1 check commands left out"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output lacks %q:\n%s", want, out.String())
+		}
+	}
+	if data, _ := os.ReadFile(zshrc); string(data) != "setopt autocd\nalias k=kubectl\n" {
+		t.Fatal("answering n should leave the file alone")
+	}
+	if info, err := os.Stat(key); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("the key should be restored with its permissions: %v", err)
+	}
+	if saved, _ := storage.New(registry).Load(); len(saved) != 3 || saved[0].Run != "" {
+		t.Fatalf("registry after restore = %+v, want the check command dropped", saved)
+	}
+
+	if err := os.Remove(registry); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := runRestore(ctx, []string{"--yes", "--allow-run", archive}); err != nil {
+		t.Fatalf("restore --allow-run: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "    run    zsh -n .zshrc") {
+		t.Fatalf("the kept check command should be listed:\n%s", out.String())
+	}
+	if saved, _ := storage.New(registry).Load(); len(saved) != 3 || saved[0].Run != "zsh -n .zshrc" || saved[0].AutoCheck {
+		t.Fatalf("registry after restore --allow-run = %+v", saved)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// batchDeleteState lists the entries a filtered delete would remove.
+type batchDeleteState struct {
+	targets []models.ConfigEntry
+	shared  int    // shared entries the filter matched, which can't be deleted
+	title   string // replaces the default question, e.g. for expired entries
+	scroll  int
+}
+
+// filtering reports whether the list is narrowed by more than the archived
+// toggle, so that deleting what it shows is a deliberate choice.
+func (m model) filtering() bool {
+	return m.searchQuery != "" || m.overdueOnly || m.unopenedOnly || m.workspace != "" || len(m.tagFilter) > 0 || m.hideMissing
+}
+
+// startBatchDelete lists every entry the current filter shows and asks for
+// their count to be typed before deleting them.
+func (m *model) startBatchDelete() tea.Cmd {
+	if !m.filtering() {
+		return showWarning("Filter the list first (/, R, U, T, M or a workspace); ctrl+x deletes what it shows")
+	}
+	state := batchDeleteState{}
+	for _, config := range m.getFilteredConfigs() {
+		if config.Shared {
+			state.shared++
+			continue
+		}
+		state.targets = append(state.targets, config)
+	}
+	if len(state.targets) == 0 {
+		return showWarning("The filter shows no files that can be deleted")
+	}
+	if !m.settings.ConfirmBulk() {
+		return m.deleteConfigs(state.targets)
+	}
+	m.confirmBatchDelete(state)
+	return nil
+}
+
+// expiredConfigs returns the temporary registrations whose time is up.
+// Shared entries are left out as they can't be deleted.
+func (m model) expiredConfigs() []models.ConfigEntry {
+	now := time.Now()
+	var expired []models.ConfigEntry
+	for _, config := range m.configs {
+		if config.Expired(now) && !config.Shared {
+			expired = append(expired, config)
+		}
+	}
+	return expired
+}
+
+// offerExpiredCleanup lists expired registrations for deletion, as zap does
+// on startup; esc keeps them.
+func (m *model) offerExpiredCleanup() {
+	if expired := m.expiredConfigs(); len(expired) > 0 {
+		m.confirmBatchDelete(batchDeleteState{
+			targets: expired,
+			title:   fmt.Sprintf("%d temporary registrations have expired. Remove them from the registry?", len(expired)),
+		})
+	}
+}
+
+func (m *model) confirmBatchDelete(state batchDeleteState) {
+	m.batchDelete = state
+	m.mode = ModeConfirmBatchDelete
+	m.textInput.SetSuggestions(nil)
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = strconv.Itoa(len(state.targets))
+	m.textInput.Focus()
+}
+
+func (m *model) closeBatchDelete() {
+	m.mode = ModeNormal
+	m.batchDelete = batchDeleteState{}
+	m.textInput.Blur()
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = ""
+}
+
+func (m model) batchDeleteBodyHeight() int {
+	return max(m.mainContentHeight()-m.runPanelStyle().GetVerticalFrameSize()-2, 1)
+}
+
+func (m model) updateBatchDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxScroll := max(len(m.batchDelete.targets)-m.batchDeleteBodyHeight(), 0)
+	switch msg.String() {
+	case "esc":
+		m.closeBatchDelete()
+		return m, showStatus("Deletion cancelled")
+	case "up":
+		m.batchDelete.scroll = max(m.batchDelete.scroll-1, 0)
+		return m, nil
+	case "down":
+		m.batchDelete.scroll = min(m.batchDelete.scroll+1, maxScroll)
+		return m, nil
+	case "enter":
+		count := len(m.batchDelete.targets)
+		if strings.TrimSpace(m.textInput.Value()) != strconv.Itoa(count) {
+			return m, showWarning(fmt.Sprintf("Type %d to delete these files, or esc to cancel", count))
+		}
+		return m, m.deleteConfigs(m.batchDelete.targets)
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// deleteConfigs removes targets from the registry in a single save.
+func (m *model) deleteConfigs(targets []models.ConfigEntry) tea.Cmd {
+	kept := removeEntries(m.configs, targets)
+	undo := m.storage.RecordDeleted(targets, time.Now())
+	if err := m.storage.Save(kept); err != nil {
+		undo() // the entries are still registered
+		m.closeBatchDelete()
+		return showError(fmt.Sprintf("Failed to save: %v", err))
+	}
+	m.configs = kept
+	for _, config := range targets {
+		delete(m.marked, config.Path)
+	}
+	m.closeBatchDelete()
+	m.cacheValid = false
+	m.buildDisplayList()
+	m.refreshRightViewport()
+	return showSuccess(fmt.Sprintf("Deleted %d files", len(targets)))
+}
+
+// renderBatchDeletePanel shows exactly which entries would be deleted.
+func (m model) renderBatchDeletePanel() string {
+	panelStyle := m.runPanelStyle().BorderForeground(lipgloss.Color("196"))
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+	state := m.batchDelete
+
+	title := state.title
+	if title == "" {
+		title = fmt.Sprintf("Delete these %d files from the registry?", len(state.targets))
+	}
+	if state.shared > 0 {
+		title += fmt.Sprintf(" (%d shared files are kept)", state.shared)
+	}
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")).Render(truncate(title, innerWidth)),
+		suitechrome.Dim(truncate("The files themselves stay on disk.", innerWidth)),
+	}
+
+	end := min(state.scroll+m.batchDeleteBodyHeight(), len(state.targets))
+	nameWidth := min(max(innerWidth/3, 12), 32)
+	for _, config := range state.targets[state.scroll:end] {
+		project := config.Project
+		if project == "" {
+			project = "General"
+		}
+		name := fmt.Sprintf("%-*s  ", nameWidth, truncate(config.Name, nameWidth))
+		lines = append(lines, name+suitechrome.Dim(truncate(project+" · "+config.Path, max(innerWidth-nameWidth-2, 3))))
+	}
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(m.mainContentHeight() - panelStyle.GetVerticalFrameSize()).
+		Render(strings.Join(lines, "\n"))
+}
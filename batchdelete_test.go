@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestBatchDeleteNeedsFilterAndTypedCount(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, storage: store}
+	m.textInput = textinput.New()
+	m.configs = []models.ConfigEntry{
+		{Name: "old api", Path: "/srv/legacy/api.conf", Project: "legacy"},
+		{Name: "old db", Path: "/srv/legacy/db.conf", Project: "legacy"},
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf", Project: "infra"},
+	}
+	m.buildDisplayList()
+	key := func(k tea.KeyMsg) tea.Cmd {
+		t.Helper()
+		updated, cmd := m.Update(k)
+		m = updated.(model)
+		return cmd
+	}
+	ctrlX := tea.KeyMsg{Type: tea.KeyCtrlX}
+
+	if key(ctrlX); m.mode != ModeNormal {
+		t.Fatal("ctrl+x without a filter should refuse")
+	}
+
+	m.searchQuery = "legacy"
+	m.buildDisplayList()
+	key(ctrlX)
+	if m.mode != ModeConfirmBatchDelete || len(m.batchDelete.targets) != 2 {
+		t.Fatalf("mode = %v, targets = %+v, want both legacy files listed", m.mode, m.batchDelete.targets)
+	}
+	screen := xansi.Strip(m.View())
+	if !strings.Contains(screen, "old api") || !strings.Contains(screen, "old db") || strings.Contains(screen, "nginx.conf") {
+		t.Fatalf("preview should list exactly the matching files:\n%s", screen)
+	}
+
+	m.textInput.SetValue("3")
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeConfirmBatchDelete || len(m.configs) != 3 {
+		t.Fatal("a wrong count must not delete anything")
+	}
+	m.textInput.SetValue("2")
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeNormal || len(m.configs) != 1 || m.configs[0].Name != "nginx" {
+		t.Fatalf("configs after delete = %+v", m.configs)
+	}
+	if saved, err := store.Load(); err != nil || len(saved) != 1 {
+		t.Fatalf("saved = %+v, %v, want one entry", saved, err)
+	}
+}
+
+func TestBatchDeleteForgetsHistoryWhenSaveFails(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := storage.New(filepath.Join(blocker, "zap-registry.json"))
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, storage: store}
+	m.configs = []models.ConfigEntry{{Name: "old api", Path: "/srv/legacy/api.conf"}}
+	m.buildDisplayList()
+
+	cmd := m.deleteConfigs(m.configs)
+	if msg, ok := cmd().(statusMsg); !ok || !strings.Contains(msg.message, "Failed to save") {
+		t.Fatalf("status = %+v, want the save error", cmd())
+	}
+	if len(m.configs) != 1 || len(store.Deleted()) != 0 {
+		t.Fatalf("configs = %+v, deleted = %+v, want the entry kept and not listed as deleted", m.configs, store.Deleted())
+	}
+}
+
+func TestExpiredEntriesAreOfferedForCleanupOnStartup(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	now := time.Now()
+	configs := []models.ConfigEntry{
+		{Name: "spike scratch", Path: "/tmp/spike/config.yaml", Expires: now.Add(-time.Hour)},
+		{Name: "next week", Path: "/tmp/trial.toml", Expires: now.Add(7 * 24 * time.Hour)},
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf"},
+	}
+	if err := store.Save(configs); err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(store, configs, settings.Settings{})
+	if m.mode != ModeConfirmBatchDelete || len(m.batchDelete.targets) != 1 {
+		t.Fatalf("mode = %v, targets = %+v, want the expired entry offered", m.mode, m.batchDelete.targets)
+	}
+	screen := xansi.Strip(m.View())
+	if !strings.Contains(screen, "1 temporary registrations have expired") || !strings.Contains(screen, "spike scratch") {
+		t.Fatalf("cleanup prompt:\n%s", screen)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.mode != ModeNormal || len(m.configs) != 3 {
+		t.Fatal("esc should keep the expired entry")
+	}
+	if screen := xansi.Strip(m.View()); !strings.Contains(screen, "⌛") {
+		t.Fatalf("the expired entry should stay flagged:\n%s", screen)
+	}
+
+	m.editRow, m.editCol = 1, 11
+	m.draft = m.configs[1]
+	m.textInput.SetValue("2w")
+	if err := m.applyEditField(); err != nil {
+		t.Fatal(err)
+	}
+	if d := m.draft.Expires.Sub(now); d < 13*24*time.Hour || d > 15*24*time.Hour {
+		t.Fatalf("2w should expire in two weeks, got %v", d)
+	}
+	m.textInput.SetValue("soon")
+	if err := m.applyEditField(); err == nil {
+		t.Fatal("an unparseable expiry should be rejected")
+	}
+}
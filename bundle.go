@@ -0,0 +1,356 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+// bundleManifest is the name of the entry list inside a bundle archive.
+const bundleManifest = "zap-bundle.json"
+
+// projectBundle is one project's entries packaged for another registry.
+type projectBundle struct {
+	Project models.Project `json:"project"`
+	Entries []bundleEntry  `json:"entries"`
+}
+
+// bundleEntry is a shared entry. File names its copy inside the archive
+// when the bundle carries the files themselves.
+type bundleEntry struct {
+	models.ConfigEntry
+	File string `json:"file,omitempty"`
+}
+
+// shareable strips the fields that only make sense on the machine that
+// recorded them and writes home-relative paths with ~. Auto-check is left
+// for each user to turn on with c.
+func shareable(config models.ConfigEntry) models.ConfigEntry {
+	config.LastOpened = time.Time{}
+	config.Added = time.Time{}
+	config.OpenCount = 0
+	config.Size = 0
+	config.Editor = ""
+	config.AutoCheck = false
+	config.Path = contractHome(config.Path)
+	return config
+}
+
+// contractHome rewrites a path under the home directory to start with ~.
+func contractHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	path = editor.ExpandPath(path)
+	if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "~/" + filepath.ToSlash(rel)
+	}
+	return path
+}
+
+func runExportProject(ctx *cliContext, args []string) error {
+	const usage = "export-project [--files] [--out file] <project>"
+	fs := ctx.flagSet("export-project")
+	withFiles := fs.Bool("files", false, "Include copies of the files (writes a .tar.gz; needs --out)")
+	out := fs.String("out", "", "Write the bundle to this file instead of stdout")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 || (*withFiles && *out == "") {
+		return usageError(usage)
+	}
+
+	store, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	name := strings.Join(rest, " ")
+	bundle := projectBundle{Project: models.Project{Name: name}}
+	if project := models.FindProject(store.Projects(), name); project != nil {
+		bundle.Project = *project
+		bundle.Project.Root = contractHome(project.Root)
+	}
+	for _, config := range configs {
+		if strings.EqualFold(config.Project, name) {
+			bundle.Project.Name = config.Project
+			bundle.Entries = append(bundle.Entries, bundleEntry{ConfigEntry: shareable(config)})
+		}
+	}
+	if len(bundle.Entries) == 0 {
+		return notFoundf("no entries in project %q", name)
+	}
+
+	var data []byte
+	if *withFiles {
+		data, err = bundleArchive(bundle)
+	} else {
+		data, err = json.MarshalIndent(bundle, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = ctx.stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		return err
+	}
+	if !ctx.quiet {
+		fmt.Fprintf(ctx.stderr, "exported %d entries from %s to %s\n", len(bundle.Entries), bundle.Project.Name, *out)
+	}
+	return nil
+}
+
+// bundleArchive packs the manifest and a copy of every entry's file into a
+// gzipped tarball. Files are stored flat under files/ with an index prefix
+// so equal base names don't collide.
+func bundleArchive(bundle projectBundle) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for i := range bundle.Entries {
+		entry := &bundle.Entries[i]
+		data, err := os.ReadFile(editor.ExpandPath(entry.Path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name, err)
+		}
+		entry.File = fmt.Sprintf("files/%d-%s", i+1, filepath.Base(entry.Path))
+		if err := writeTarFile(tw, entry.File, data); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, bundleManifest, manifest); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// readBundle loads a bundle written by export-project, returning the
+// contents of any bundled files keyed by their name in the archive.
+func readBundle(path string) (projectBundle, map[string][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return projectBundle{}, nil, err
+	}
+
+	var bundle projectBundle
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			return projectBundle{}, nil, invalidf("%s is not a zap bundle: %v", path, err)
+		}
+		return bundle, nil, nil
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return projectBundle{}, nil, invalidf("%s is not a zap bundle: %v", path, err)
+	}
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return projectBundle{}, nil, invalidf("%s is not a zap bundle: %v", path, err)
+		}
+		if files[header.Name], err = io.ReadAll(tr); err != nil {
+			return projectBundle{}, nil, err
+		}
+	}
+	manifest, ok := files[bundleManifest]
+	if !ok {
+		return projectBundle{}, nil, invalidf("%s has no %s", path, bundleManifest)
+	}
+	if err := json.Unmarshal(manifest, &bundle); err != nil {
+		return projectBundle{}, nil, invalidf("%s: %v", bundleManifest, err)
+	}
+	return bundle, files, nil
+}
+
+func runImportProject(ctx *cliContext, args []string) error {
+	const usage = "import-project [--dest dir] [--allow-run] <bundle>"
+	fs := ctx.flagSet("import-project")
+	dest := fs.String("dest", "", "Directory for bundled files (default ./<project>)")
+	allowRun := fs.Bool("allow-run", false, "Keep the bundle's check commands (listed and dropped otherwise)")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageError(usage)
+	}
+
+	bundle, files, err := readBundle(rest[0])
+	if err != nil {
+		return err
+	}
+	if bundle.Project.Name == "" || len(bundle.Entries) == 0 {
+		return invalidf("%s contains no project entries", rest[0])
+	}
+	if *dest == "" {
+		name, ok := bundleDirName(bundle.Project.Name)
+		if !ok {
+			return invalidf("project name %q can't be used as a directory; choose one with --dest", bundle.Project.Name)
+		}
+		*dest = name
+	}
+	destDir, err := filepath.Abs(editor.ExpandPath(*dest))
+	if err != nil {
+		return err
+	}
+
+	store, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	added := 0
+	dropped := 0
+	names := make(map[string]int)
+	for _, entry := range bundle.Entries {
+		if entry.File != "" {
+			names[bundledFileName(entry.File)]++
+		}
+	}
+	for _, entry := range bundle.Entries {
+		config := entry.ConfigEntry
+		config.Path = editor.ExpandPath(config.Path)
+		if entry.File != "" {
+			// Files sharing a name keep the archive's index prefix apart
+			name := bundledFileName(entry.File)
+			if names[name] > 1 {
+				name = filepath.Base(entry.File)
+			}
+			if name == "." || name == ".." || name == string(filepath.Separator) {
+				return invalidf("%s holds a file named %q", rest[0], entry.File)
+			}
+			config.Path = filepath.Join(destDir, name)
+		}
+		if dup := storage.FindDuplicates(configs, config.Path); dup != nil {
+			fmt.Fprintf(ctx.stdout, "skipped  %s  (already registered as %s)\n", config.Name, dup.Name)
+			continue
+		}
+		if entry.File != "" {
+			data, ok := files[entry.File]
+			if !ok {
+				return invalidf("bundle is missing %s for %s", entry.File, config.Name)
+			}
+			if err := writeNewFile(config.Path, data); err != nil {
+				return err
+			}
+		}
+		// Commands from someone else's registry only run once asked for
+		config.AutoCheck = false
+		if config.Run != "" && !*allowRun {
+			fmt.Fprintf(ctx.stdout, "no run   %s  %s\n", config.Name, config.Run)
+			config.Run = ""
+			dropped++
+		}
+		if models.FindByAlias(configs, config.Alias) != nil {
+			config.Alias = ""
+		}
+		config.Project = bundle.Project.Name
+		config.Added = now
+		config.ConfirmOpen = config.ConfirmOpen || models.NeedsOpenConfirmation(config.Path)
+		configs = append(configs, config)
+		added++
+		fmt.Fprintf(ctx.stdout, "added    %s  %s\n", config.Name, config.Path)
+	}
+
+	projects := store.Projects()
+	if models.FindProject(projects, bundle.Project.Name) == nil {
+		project := bundle.Project
+		project.Root = editor.ExpandPath(project.Root)
+		if len(files) > 0 {
+			project.Root = destDir
+		}
+		store.SetProjects(append(projects, project))
+	}
+	if err := store.Save(configs); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.stdout, "%d entries imported into %s\n", added, bundle.Project.Name)
+	if dropped > 0 {
+		fmt.Fprintf(ctx.stdout, "%d check commands left out; import again with --allow-run to keep them\n", dropped)
+	}
+	return nil
+}
+
+// bundledFileName is the name a bundled file is restored under: its name in
+// the archive without the index prefix bundleArchive added.
+func bundledFileName(file string) string {
+	name := filepath.Base(file)
+	if index, rest, ok := strings.Cut(name, "-"); ok && rest != "" && strings.Trim(index, "0123456789") == "" && index != "" {
+		return rest
+	}
+	return name
+}
+
+// bundleDirName is the directory a bundle's files go to without --dest: its
+// project name, as long as that names a single directory under the current
+// one. A bundle from someone else must not pick a path like ~/.ssh.
+func bundleDirName(project string) (string, bool) {
+	if project == "" || filepath.IsAbs(project) || strings.HasPrefix(project, "~") ||
+		strings.ContainsAny(project, `/\`) {
+		return "", false
+	}
+	name := filepath.Base(filepath.Clean(project))
+	if name == "." || name == ".." {
+		return "", false
+	}
+	return name, true
+}
+
+// writeNewFile writes a bundled file without replacing one that exists with
+// different contents.
+func writeNewFile(path string, data []byte) error {
+	if existing, err := os.ReadFile(path); err == nil {
+		if bytes.Equal(existing, data) {
+			return nil
+		}
+		return invalidf("%s already exists; choose another --dest", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
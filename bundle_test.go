@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+func TestProjectBundleRoundTripWithFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	src := filepath.Join(home, "infra", "nginx.conf")
+	if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("worker_processes 1;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := filepath.Join(home, "mine.json")
+	t.Setenv(registryPathEnv, registry)
+	store := storage.New(registry)
+	store.SetProjects([]models.Project{{Name: "infra", Color: "33"}})
+	if err := store.Save([]models.ConfigEntry{
+		{Name: "nginx", Path: src, Project: "infra", OpenCount: 7, Editor: "vim"},
+		{Name: "zshrc", Path: filepath.Join(home, ".zshrc"), Project: "dotfiles"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bundlePath := filepath.Join(home, "infra.tar.gz")
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runExportProject(ctx, []string{"--files", "--out", bundlePath, "INFRA"}); err != nil {
+		t.Fatalf("export-project: %v", err)
+	}
+
+	teammate := filepath.Join(home, "theirs.json")
+	t.Setenv(registryPathEnv, teammate)
+	dest := filepath.Join(home, "shared")
+	if err := runImportProject(ctx, []string{"--dest", dest, bundlePath}); err != nil {
+		t.Fatalf("import-project: %v\n%s", err, out.String())
+	}
+
+	imported := storage.New(teammate)
+	configs, err := imported.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 1 {
+		t.Fatalf("imported %d entries, want 1: %+v", len(configs), configs)
+	}
+	got := configs[0]
+	if got.Name != "nginx" || got.Project != "infra" || got.OpenCount != 0 || got.Editor != "" {
+		t.Fatalf("imported entry = %+v", got)
+	}
+	if data, err := os.ReadFile(got.Path); err != nil || string(data) != "worker_processes 1;\n" {
+		t.Fatalf("bundled file at %s = %q, %v", got.Path, data, err)
+	}
+	if project := models.FindProject(imported.Projects(), "infra"); project == nil || project.Color != "33" || project.Root != dest {
+		t.Fatalf("imported project = %+v", project)
+	}
+
+	if err := runImportProject(ctx, []string{"--dest", dest, bundlePath}); err != nil {
+		t.Fatalf("second import-project: %v", err)
+	}
+	if configs, _ := storage.New(teammate).Load(); len(configs) != 1 {
+		t.Fatalf("re-import duplicated entries: %+v", configs)
+	}
+}
+
+func TestImportProjectLeavesOutCommandsAndKeepsFileNames(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	src := filepath.Join(home, "infra", "nginx.conf")
+	if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("worker_processes 1;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(registryPathEnv, filepath.Join(home, "mine.json"))
+	if err := storage.New(filepath.Join(home, "mine.json")).Save([]models.ConfigEntry{
+		{Name: "nginx", Path: src, Project: "infra", Run: "nginx -t", AutoCheck: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	bundlePath := filepath.Join(home, "infra.tar.gz")
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runExportProject(ctx, []string{"--files", "--out", bundlePath, "infra"}); err != nil {
+		t.Fatalf("export-project: %v", err)
+	}
+
+	teammate := filepath.Join(home, "theirs.json")
+	t.Setenv(registryPathEnv, teammate)
+	dest := filepath.Join(home, "shared")
+	if err := runImportProject(ctx, []string{"--dest", dest, bundlePath}); err != nil {
+		t.Fatalf("import-project: %v\n%s", err, out.String())
+	}
+	configs, err := storage.New(teammate).Load()
+	if err != nil || len(configs) != 1 {
+		t.Fatalf("imported %+v, %v", configs, err)
+	}
+	if got := configs[0]; got.Run != "" || got.AutoCheck {
+		t.Fatalf("imported entry kept run %q / auto_check %v without --allow-run", got.Run, got.AutoCheck)
+	}
+	if configs[0].Path != filepath.Join(dest, "nginx.conf") {
+		t.Fatalf("bundled file restored at %s, want %s", configs[0].Path, filepath.Join(dest, "nginx.conf"))
+	}
+	if !bytes.Contains(out.Bytes(), []byte("nginx -t")) {
+		t.Fatalf("dropped command not listed:\n%s", out.String())
+	}
+
+	// A skipped duplicate leaves nothing behind in the destination
+	stale := filepath.Join(home, "stale.json")
+	t.Setenv(registryPathEnv, stale)
+	other := filepath.Join(home, "elsewhere", "nginx.conf")
+	if err := storage.New(stale).Save([]models.ConfigEntry{{Name: "old-nginx", Path: other}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runImportProject(ctx, []string{"--dest", filepath.Dir(other), bundlePath}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(other); !os.IsNotExist(err) {
+		t.Fatalf("skipped duplicate was still written to %s", other)
+	}
+
+	allowed := filepath.Join(home, "allowed.json")
+	t.Setenv(registryPathEnv, allowed)
+	if err := runImportProject(ctx, []string{"--dest", dest, "--allow-run", bundlePath}); err != nil {
+		t.Fatal(err)
+	}
+	if configs, _ := storage.New(allowed).Load(); len(configs) != 1 || configs[0].Run != "nginx -t" || configs[0].AutoCheck {
+		t.Fatalf("--allow-run import = %+v", configs)
+	}
+}
+
+func TestImportProjectRefusesHostileProjectNames(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	src := filepath.Join(home, "authorized_keys")
+	if err := os.WriteFile(src, []byte("ssh-ed25519 AAAA attacker\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	work := filepath.Join(home, "work")
+	if err := os.MkdirAll(work, 0755); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(work); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	for i, name := range []string{"~/.ssh", filepath.Join(home, ".ssh"), "../.ssh", "..", ".", `keys\..\..`} {
+		bundlePath := filepath.Join(home, "bundle.tar.gz")
+		data, err := bundleArchive(projectBundle{
+			Project: models.Project{Name: name},
+			Entries: []bundleEntry{{ConfigEntry: models.ConfigEntry{Name: "keys", Path: src, Project: name}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(bundlePath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv(registryPathEnv, filepath.Join(home, fmt.Sprintf("registry%d.json", i)))
+		if err := runImportProject(ctx, []string{bundlePath}); exitCode(err) != exitInvalid {
+			t.Fatalf("import of project %q = %v, want it refused", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(home, ".ssh")); !os.IsNotExist(err) {
+			t.Fatalf("project %q wrote outside the working directory", name)
+		}
+	}
+
+	// An ordinary name becomes a directory under the working one
+	data, err := bundleArchive(projectBundle{
+		Project: models.Project{Name: "infra"},
+		Entries: []bundleEntry{{ConfigEntry: models.ConfigEntry{Name: "keys", Path: src, Project: "infra"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundlePath := filepath.Join(home, "infra.tar.gz")
+	if err := os.WriteFile(bundlePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(registryPathEnv, filepath.Join(home, "ok.json"))
+	if err := runImportProject(ctx, []string{bundlePath}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(work, "infra", "authorized_keys")); err != nil {
+		t.Fatalf("default destination not used: %v", err)
+	}
+}
@@ -0,0 +1,547 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/internal/ui"
+)
+
+// Exit codes returned by subcommands and --open-first
+const (
+	exitOK        = 0
+	exitError     = 1 // unexpected failure (I/O, editor launch, ...)
+	exitNotFound  = 2 // no entry matched
+	exitInvalid   = 3 // bad arguments or a value failed validation
+	exitAmbiguous = 4 // several entries matched and none was exact
+)
+
+// cliError is an error carrying the exit code it should produce.
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string { return e.err.Error() }
+func (e *cliError) Unwrap() error { return e.err }
+
+func notFoundf(format string, args ...any) error {
+	return &cliError{code: exitNotFound, err: fmt.Errorf(format, args...)}
+}
+
+func invalidf(format string, args ...any) error {
+	return &cliError{code: exitInvalid, err: fmt.Errorf(format, args...)}
+}
+
+func ambiguousf(format string, args ...any) error {
+	return &cliError{code: exitAmbiguous, err: fmt.Errorf(format, args...)}
+}
+
+// exitCode maps a command error to the process exit code.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var ce *cliError
+	if errors.As(err, &ce) {
+		return ce.code
+	}
+	return exitError
+}
+
+// usageError reports bad command-line arguments.
+func usageError(usage string) error {
+	return invalidf("usage: zap %s", usage)
+}
+
+// cliContext carries the output streams and global flags for a command.
+type cliContext struct {
+	stdin       io.Reader
+	stdout      io.Writer
+	stderr      io.Writer
+	quiet       bool // suppress diagnostics; only requested output is written
+	interactive bool // stdin and stderr are a terminal, so commands may ask
+
+	lines *bufio.Reader // buffers stdin across readLine calls
+}
+
+// readLine reads one line of input with surrounding space trimmed.
+func (c *cliContext) readLine() string {
+	if c.lines == nil {
+		c.lines = bufio.NewReader(c.stdin)
+	}
+	line, _ := c.lines.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// flagSet returns a flag set for a subcommand that honours --quiet.
+func (c *cliContext) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if c.quiet {
+		fs.SetOutput(io.Discard)
+	} else {
+		fs.SetOutput(c.stderr)
+	}
+	return fs
+}
+
+// parse parses a subcommand's flags, treating failures as invalid usage.
+func (c *cliContext) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	rest, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, invalidf("%w", err)
+	}
+	return rest, nil
+}
+
+// cliCommand is a non-interactive subcommand run instead of the TUI.
+type cliCommand struct {
+	usage   string
+	summary string
+	run     func(ctx *cliContext, args []string) error
+}
+
+var cliCommands = map[string]cliCommand{
+	"add": {
+		usage:   "add [--name n] [--project p] [--type t] [--description d] [--alias a] [--skip-existing] <path>...",
+		summary: "Register files without opening the TUI",
+		run:     runAdd,
+	},
+	"list": {
+		usage:   "list [--archived] [--format table|names|json] [query]",
+		summary: "List registered files, optionally filtered by a search query",
+		run:     runList,
+	},
+	"rm": {
+		usage:   "rm <name>...",
+		summary: "Remove entries from the registry, leaving the files alone",
+		run:     runRm,
+	},
+	"cat": {
+		usage:   "cat [--all] <name>",
+		summary: "Print the contents of a registered file",
+		run:     runCat,
+	},
+	"usage": {
+		usage:   "usage [--out file]",
+		summary: "Export per-file open counts and last-opened times as JSON",
+		run:     runUsage,
+	},
+	"open": {
+		usage:   "open [--first] [--yes] <query>",
+		summary: "Open a file in its editor, asking which when several match",
+		run:     runOpen,
+	},
+	"recent": {
+		usage:   "recent [--limit n] [--format simple|table|json]",
+		summary: "List the most recently opened files, e.g. for a shell prompt",
+		run:     runRecent,
+	},
+	"pick": {
+		usage:   "pick [--powershell] [query]",
+		summary: "List matching files for a picker: tab-separated, or CSV for PowerShell",
+		run:     runPick,
+	},
+	"script-filter": {
+		usage:   "script-filter [query]",
+		summary: "List matching files as Alfred/Raycast Script Filter JSON",
+		run:     runScriptFilter,
+	},
+	"path": {
+		usage:   "path [--strict] <name>",
+		summary: "Print the expanded path of a registered file",
+		run:     runPath,
+	},
+	"export-project": {
+		usage:   "export-project [--files] [--out file] <project>",
+		summary: "Export one project's entries (and optionally the files) as a bundle",
+		run:     runExportProject,
+	},
+	"backup": {
+		usage:   "backup [--project p,...] [--tag t,...] [--out file]",
+		summary: "Archive the registered files themselves, with their entries",
+		run:     runBackup,
+	},
+	"restore": {
+		usage:   "restore [--yes] [--dry-run] [--allow-run] <archive>",
+		summary: "Write the files in a zap backup back to their paths, asking for each",
+		run:     runRestore,
+	},
+	"checksum": {
+		usage:   "checksum [--clear] (--all | <name>...)",
+		summary: "Pin files' current checksums so zap verify flags any change",
+		run:     runChecksum,
+	},
+	"fetch": {
+		usage:   "fetch [--name n] [--project p] [--description d] [--force] <url> <dest>",
+		summary: "Download a file and register it, keeping the URL as its source",
+		run:     runFetch,
+	},
+	"import-project": {
+		usage:   "import-project [--dest dir] [--allow-run] <bundle>",
+		summary: "Register the entries of a project bundle",
+		run:     runImportProject,
+	},
+	"lint": {
+		usage:   "lint [--fix]",
+		summary: "Flag placeholder values, stray whitespace, undetected types and duplicates",
+		run:     runLint,
+	},
+	"script": {
+		usage:   "script [--demo|--live] [--size WxH] <file|->",
+		summary: "Replay keys against the TUI without a terminal and check what it shows",
+		run:     runScript,
+	},
+	"update": {
+		usage:   "update [--check] [--force] [--version tag]",
+		summary: "Download the latest release for this platform and replace zap",
+		run:     runUpdate,
+	},
+	"version": {
+		usage:   "version",
+		summary: "Print the version, commit and build date",
+		run:     runVersion,
+	},
+	"verify": {
+		usage:   "verify [--fix]",
+		summary: "Check for missing files, moved files and changed checksums",
+		run:     runVerify,
+	},
+}
+
+// isCLICommand reports whether name is a subcommand rather than a query.
+func isCLICommand(name string) bool {
+	_, ok := cliCommands[name]
+	return ok
+}
+
+// runCLICommand runs a subcommand and returns the process exit code.
+func runCLICommand(name string, args []string) int {
+	ctx := &cliContext{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr,
+		interactive: isTerminal(os.Stdin) && isTerminal(os.Stderr)}
+	ctx.quiet, args = extractQuiet(args)
+
+	registry, args, err := extractRegistry(args)
+	if err == nil {
+		err = useRegistry(registry)
+	}
+	if err == nil {
+		err = cliCommands[name].run(ctx, args)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil && !ctx.quiet {
+		fmt.Fprintf(ctx.stderr, "zap %s: %v\n", name, err)
+	}
+	return exitCode(err)
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// extractQuiet removes the global --quiet flag from a command's arguments.
+func extractQuiet(args []string) (bool, []string) {
+	quiet := false
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--quiet" || arg == "-quiet" {
+			quiet = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return quiet, rest
+}
+
+// printCommandUsage lists the subcommands for flag.Usage.
+func printCommandUsage(w io.Writer) {
+	fmt.Fprintf(w, "Commands:\n")
+	width := 0
+	for _, cmd := range cliCommands {
+		width = max(width, len(cmd.usage))
+	}
+	for _, name := range sortedCommandNames() {
+		cmd := cliCommands[name]
+		fmt.Fprintf(w, "  zap %-*s  %s\n", width, cmd.usage, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nAll commands accept --quiet to suppress error messages and --registry <name> to use another registry.\n")
+	fmt.Fprintf(w, "Exit codes: 0 ok, 1 error, 2 not found, 3 invalid usage, 4 ambiguous name.\n\n")
+}
+
+func sortedCommandNames() []string {
+	names := make([]string, 0, len(cliCommands))
+	for name := range cliCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadRegistry resolves and loads the registry and settings for a command.
+func loadRegistry() (*storage.Storage, []models.ConfigEntry, settings.Settings, error) {
+	configFile, err := resolveRegistryPath()
+	if err != nil {
+		return nil, nil, settings.Settings{}, err
+	}
+
+	store := storage.New(configFile)
+	configs, err := loadConfigs(store)
+	if err != nil {
+		return nil, nil, settings.Settings{}, fmt.Errorf("failed to load configs: %w", err)
+	}
+
+	prefs, err := settings.Load(settings.PathFor(configFile))
+	if err != nil {
+		return nil, nil, settings.Settings{}, fmt.Errorf("failed to load settings: %w", err)
+	}
+	store.SetRelativePaths(prefs.RelativePaths)
+	if configs, err = withShared(configs, prefs); err != nil {
+		return nil, nil, settings.Settings{}, err
+	}
+	return store, configs, prefs, nil
+}
+
+// resolveEntry finds the entry a command refers to. An exact name match
+// (ignoring case) wins, followed by an entry's alias; unless strict is set,
+// the name may instead match exactly one entry as a substring or, failing
+// that, as a fuzzy pattern.
+func resolveEntry(configs []models.ConfigEntry, name string, strict bool) (models.ConfigEntry, error) {
+	needle := foldCase(strings.TrimSpace(name))
+	if needle == "" {
+		return models.ConfigEntry{}, invalidf("no entry name given")
+	}
+
+	for _, config := range configs {
+		if foldCase(config.Name) == needle {
+			return config, nil
+		}
+	}
+	if config := models.FindByAlias(configs, needle); config != nil {
+		return *config, nil
+	}
+
+	var partial, fuzzy []models.ConfigEntry
+	for _, config := range configs {
+		lower := foldCase(config.Name)
+		if strings.Contains(lower, needle) {
+			partial = append(partial, config)
+		} else if fuzzyMatch(needle, lower) {
+			fuzzy = append(fuzzy, config)
+		}
+	}
+
+	candidates := partial
+	if len(candidates) == 0 {
+		candidates = fuzzy
+	}
+	if strict || len(candidates) == 0 {
+		return models.ConfigEntry{}, notFoundf("no entry matches %q", name)
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	names := make([]string, len(candidates))
+	for i, config := range candidates {
+		names[i] = config.Name
+	}
+	return models.ConfigEntry{}, ambiguousf("%q is ambiguous: %s", name, strings.Join(names, ", "))
+}
+
+func runCat(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("cat")
+	all := fs.Bool("all", false, "Print files over the large_file_kb limit too")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usageError("cat [--all] <name>")
+	}
+
+	_, configs, prefs, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	config, err := resolveEntry(configs, strings.Join(rest, " "), false)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(editor.ExpandPath(config.Path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && !*all && info.Size() > prefs.LargeFileBytes() {
+		return invalidf("%s is %s, over the %s large_file_kb limit; use --all to print it anyway",
+			config.Name, formatSize(info.Size()), formatSize(prefs.LargeFileBytes()))
+	}
+
+	_, err = io.Copy(ctx.stdout, f)
+	return err
+}
+
+func runPath(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("path")
+	strict := fs.Bool("strict", false, "Require an exact name match")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usageError("path [--strict] <name>")
+	}
+
+	_, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	config, err := resolveEntry(configs, strings.Join(rest, " "), *strict)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(ctx.stdout, editor.ExpandPath(config.Path))
+	return nil
+}
+
+// usageRecord is one entry in the zap usage export.
+type usageRecord struct {
+	Name       string     `json:"name"`
+	Path       string     `json:"path"`
+	Project    string     `json:"project,omitempty"`
+	Type       string     `json:"type,omitempty"`
+	OpenCount  int        `json:"open_count"`
+	LastOpened *time.Time `json:"last_opened"`
+}
+
+func runUsage(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("usage")
+	out := fs.String("out", "", "Write the JSON to this file instead of stdout")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageError("usage [--out file]")
+	}
+
+	_, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+
+	records := make([]usageRecord, 0, len(configs))
+	for _, config := range storage.SortByRecentlyOpened(configs) {
+		record := usageRecord{
+			Name:      config.Name,
+			Path:      config.Path,
+			Project:   config.Project,
+			Type:      config.Type,
+			OpenCount: config.OpenCount,
+		}
+		if !config.LastOpened.IsZero() {
+			lastOpened := config.LastOpened
+			record.LastOpened = &lastOpened
+		}
+		records = append(records, record)
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		_, err = ctx.stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0644)
+}
+
+// runRecent prints the files opened most recently, newest first. It reads
+// the registry only, so it is quick enough to run from a shell prompt or
+// startup script; archived entries and files never opened are left out.
+func runRecent(ctx *cliContext, args []string) error {
+	const usage = "recent [--limit n] [--format simple|table|json]"
+	fs := ctx.flagSet("recent")
+	limit := fs.Int("limit", 5, "Show at most this many files (0 for all)")
+	format := fs.String("format", "table", "simple (names only), table or json")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 || *limit < 0 {
+		return usageError(usage)
+	}
+	switch *format {
+	case "simple", "table", "json":
+	default:
+		return invalidf("unknown format %q; use simple, table or json", *format)
+	}
+
+	_, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	var recent []models.ConfigEntry
+	for _, config := range storage.SortByRecentlyOpened(configs) {
+		if config.LastOpened.IsZero() || *limit > 0 && len(recent) == *limit {
+			break
+		}
+		if !config.Archived {
+			recent = append(recent, config)
+		}
+	}
+
+	switch *format {
+	case "simple":
+		for _, config := range recent {
+			fmt.Fprintln(ctx.stdout, config.Name)
+		}
+	case "json":
+		records := make([]usageRecord, len(recent))
+		for i, config := range recent {
+			lastOpened := config.LastOpened
+			records[i] = usageRecord{Name: config.Name, Path: config.Path, Project: config.Project,
+				Type: config.Type, OpenCount: config.OpenCount, LastOpened: &lastOpened}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = ctx.stdout.Write(append(data, '\n'))
+		return err
+	default:
+		now := time.Now()
+		nameWidth, agoWidth := 0, 0
+		ago := make([]string, len(recent))
+		for i, config := range recent {
+			ago[i] = ui.FormatTime(config.LastOpened, now, ui.DateRelative, false)
+			nameWidth = max(nameWidth, len(config.Name))
+			agoWidth = max(agoWidth, len(ago[i]))
+		}
+		for i, config := range recent {
+			fmt.Fprintf(ctx.stdout, "%-*s  %-*s  %s\n", nameWidth, config.Name, agoWidth, ago[i], contractHome(config.Path))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LFroesch/zap/internal/importer"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+func TestResolveEntryPrefersExactName(t *testing.T) {
+	configs := []models.ConfigEntry{
+		{Name: "nginx site", Path: "/etc/nginx/sites-enabled/default"},
+		{Name: "Nginx", Path: "/etc/nginx/nginx.conf"},
+	}
+
+	config, err := resolveEntry(configs, "nginx", false)
+	if err != nil {
+		t.Fatalf("resolveEntry error = %v", err)
+	}
+	if config.Path != "/etc/nginx/nginx.conf" {
+		t.Fatalf("resolveEntry = %q, want exact name match", config.Path)
+	}
+}
+
+func TestResolveEntryReportsAmbiguousMatches(t *testing.T) {
+	configs := []models.ConfigEntry{
+		{Name: "nginx site", Path: "/a"},
+		{Name: "nginx upstream", Path: "/b"},
+	}
+
+	_, err := resolveEntry(configs, "ngin", false)
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("resolveEntry error = %v, want ambiguous match error", err)
+	}
+}
+
+func TestResolveEntryFallsBackToFuzzyUnlessStrict(t *testing.T) {
+	configs := []models.ConfigEntry{
+		{Name: "nginx.conf", Path: "/etc/nginx/nginx.conf"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+
+	config, err := resolveEntry(configs, "ngxcf", false)
+	if err != nil || config.Name != "nginx.conf" {
+		t.Fatalf("resolveEntry fuzzy = %+v, %v; want nginx.conf", config, err)
+	}
+	if _, err := resolveEntry(configs, "ngxcf", true); err == nil {
+		t.Fatal("expected strict resolution to reject a fuzzy match")
+	}
+	if _, err := resolveEntry(configs, "nginx", true); err == nil {
+		t.Fatal("expected strict resolution to reject a substring match")
+	}
+}
+
+func TestResolveEntryAcceptsAlias(t *testing.T) {
+	configs := []models.ConfigEntry{
+		{Name: "nginx site", Path: "/a", Alias: "ng"},
+		{Name: "nginx upstream", Path: "/b"},
+	}
+
+	config, err := resolveEntry(configs, "NG", true)
+	if err != nil || config.Path != "/a" {
+		t.Fatalf("resolveEntry alias = %+v, %v; want /a", config, err)
+	}
+}
+
+func TestExitCodeClassifiesResolveErrors(t *testing.T) {
+	configs := []models.ConfigEntry{
+		{Name: "nginx site", Path: "/a"},
+		{Name: "nginx upstream", Path: "/b"},
+	}
+
+	_, err := resolveEntry(configs, "caddy", false)
+	if got := exitCode(err); got != exitNotFound {
+		t.Fatalf("exitCode(not found) = %d, want %d", got, exitNotFound)
+	}
+	_, err = resolveEntry(configs, "nginx", false)
+	if got := exitCode(err); got != exitAmbiguous {
+		t.Fatalf("exitCode(ambiguous) = %d, want %d", got, exitAmbiguous)
+	}
+	if got := exitCode(usageError("path <name>")); got != exitInvalid {
+		t.Fatalf("exitCode(usage) = %d, want %d", got, exitInvalid)
+	}
+}
+
+func TestVerifyConfigsProposesMovedFile(t *testing.T) {
+	dir := t.TempDir()
+	moved := filepath.Join(dir, "new", "app.yaml")
+	if err := os.MkdirAll(filepath.Dir(moved), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(moved, []byte("key: value\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configs := []models.ConfigEntry{
+		{Name: "app", Path: filepath.Join(dir, "old", "app.yaml"), Size: 11},
+		{Name: "present", Path: moved},
+	}
+
+	missing, changed := verifyConfigs(configs, importer.DefaultOptions(nil))
+	if !changed || configs[1].Size != 11 {
+		t.Fatalf("size of existing file not refreshed: %+v", configs[1])
+	}
+	if len(missing) != 1 || missing[0].index != 0 {
+		t.Fatalf("missing = %+v, want the moved entry", missing)
+	}
+	// The new location is already registered by "present", so it is not offered.
+	if len(missing[0].candidates) != 0 {
+		t.Fatalf("candidates = %v, want registered paths excluded", missing[0].candidates)
+	}
+
+	configs = configs[:1]
+	missing, _ = verifyConfigs(configs, importer.DefaultOptions(nil))
+	if len(missing) != 1 || len(missing[0].candidates) != 1 || missing[0].candidates[0] != moved {
+		t.Fatalf("missing = %+v, want %s proposed", missing, moved)
+	}
+}
+
+func TestLintConfigsFixesHygieneIssues(t *testing.T) {
+	configs := []models.ConfigEntry{
+		{Name: " nginx ", Path: "/etc/nginx/nginx.conf", Type: "txt", Description: "File description", OpenCount: 2},
+		{Name: "nginx copy", Path: "/etc/nginx/nginx.conf", Type: "ini", Description: "dup", OpenCount: 3},
+		{Name: "notes", Path: "/home/me/notes", Type: "txt"},
+	}
+
+	issues := lintConfigs(configs)
+	var fixable, manual []string
+	for _, issue := range issues {
+		if issue.fix == nil {
+			manual = append(manual, issue.message)
+			continue
+		}
+		fixable = append(fixable, issue.message)
+		issue.fix(&configs[issue.index])
+	}
+	if len(fixable) != 4 {
+		t.Fatalf("fixable issues = %q, want whitespace, placeholder, type and duplicate", fixable)
+	}
+	if len(manual) != 1 || manual[0] != "description is empty" {
+		t.Fatalf("manual issues = %q, want only the empty description", manual)
+	}
+	if c := configs[0]; c.Name != "nginx" || c.Description != "" || c.Type != "ini" {
+		t.Fatalf("fixed entry = %+v", c)
+	}
+
+	kept, removed := compactConfigs(configs)
+	if len(kept) != 2 || len(removed) != 1 || kept[0].OpenCount != 5 {
+		t.Fatalf("compactConfigs kept %+v, removed %+v; want duplicate merged", kept, removed)
+	}
+}
+
+func TestVerifyFlagsFilesThatNoLongerMatchTheirChecksum(t *testing.T) {
+	dir := t.TempDir()
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	path := filepath.Join(dir, "sshd_config")
+	if err := os.WriteFile(path, []byte("PermitRootLogin no\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.New(registry).Save([]models.ConfigEntry{{Name: "sshd", Path: path}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runChecksum(ctx, []string{"sshd"}); err != nil {
+		t.Fatalf("checksum: %v", err)
+	}
+	if err := runVerify(ctx, nil); err != nil {
+		t.Fatalf("verify of an unchanged file: %v\n%s", err, out.String())
+	}
+
+	if err := os.WriteFile(path, []byte("PermitRootLogin yes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	err := runVerify(ctx, nil)
+	if exitCode(err) != exitInvalid || !strings.Contains(out.String(), "modified  sshd") {
+		t.Fatalf("verify after a change: %v\n%s", err, out.String())
+	}
+}
+
+func TestRecentListsLastOpenedFilesNewestFirst(t *testing.T) {
+	registry := filepath.Join(t.TempDir(), "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	now := time.Now()
+	configs := []models.ConfigEntry{
+		{Name: "zshrc", Path: "/home/me/.zshrc", LastOpened: now.Add(-3 * time.Hour)},
+		{Name: "never", Path: "/etc/never.conf"},
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf", LastOpened: now.Add(-5 * time.Minute)},
+		{Name: "old", Path: "/etc/old.conf", LastOpened: now.Add(-time.Minute), Archived: true},
+		{Name: "tmux", Path: "/home/me/.tmux.conf", LastOpened: now.Add(-48 * time.Hour)},
+	}
+	if err := storage.New(registry).Save(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runRecent(ctx, []string{"--limit", "2", "--format", "simple"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "nginx\nzshrc\n" {
+		t.Fatalf("simple output = %q, want the two newest unarchived names", got)
+	}
+
+	out.Reset()
+	if err := runRecent(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "nginx  5m ago") || !strings.Contains(lines[2], "2d ago") {
+		t.Fatalf("table output:\n%s", out.String())
+	}
+
+	if err := runRecent(ctx, []string{"--format", "yaml"}); exitCode(err) != exitInvalid {
+		t.Fatalf("unknown format: %v", err)
+	}
+}
+
+func TestUsageExportsOpenCountsAsJSON(t *testing.T) {
+	dir := t.TempDir()
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	now := time.Now()
+	configs := []models.ConfigEntry{
+		{Name: "never", Path: "/etc/never.conf"},
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf", Project: "infra", Type: "ini"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+	configs[1].RecordOpen(now.Add(-time.Hour))
+	configs[1].RecordOpen(now.Add(-time.Minute))
+	configs[2].RecordOpen(now.Add(-2 * time.Hour))
+	if err := storage.New(registry).Save(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runUsage(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+		t.Fatalf("usage output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(records) != 3 {
+		t.Fatalf("usage records = %v, want one per entry", records)
+	}
+	var keys []string
+	for key := range records[0] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if want := []string{"last_opened", "name", "open_count", "path", "project", "type"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("record keys = %q, want %q", keys, want)
+	}
+	for i, want := range []struct {
+		name  string
+		count float64
+	}{{"nginx", 2}, {"zshrc", 1}, {"never", 0}} {
+		if records[i]["name"] != want.name || records[i]["open_count"] != want.count {
+			t.Errorf("record %d = %v, want %s opened %v times", i, records[i], want.name, want.count)
+		}
+	}
+	if records[2]["last_opened"] != nil {
+		t.Errorf("never opened entry has last_opened %v, want null", records[2]["last_opened"])
+	}
+
+	file := filepath.Join(dir, "usage.json")
+	out.Reset()
+	if err := runUsage(ctx, []string{"--out", file}); err != nil || out.Len() != 0 {
+		t.Fatalf("usage --out = %v, printed %q", err, out.String())
+	}
+	if data, err := os.ReadFile(file); err != nil || !bytes.Contains(data, []byte(`"open_count": 2`)) {
+		t.Fatalf("usage file = %q, %v", data, err)
+	}
+}
+
+func TestCompactConfigsKeepsTheDuplicatesFields(t *testing.T) {
+	configs := []models.ConfigEntry{
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf", Tags: []string{"web"}, OpenCount: 1},
+		{Name: "nginx main", Path: "/etc/nginx/nginx.conf", Alias: "ng", Run: "nginx -t", Description: "Main config",
+			ReviewEvery: "90d", Source: "https://example.com/nginx.conf", Checksum: "sha256:ab", Tags: []string{"web", "prod"}, OpenCount: 2},
+		{Name: "nginx other", Path: "/etc/nginx/nginx.conf", Run: "nginx -T"},
+	}
+
+	issues := lintConfigs(configs)
+	var conflicting []lintIssue
+	for _, issue := range issues {
+		if issue.index == 2 && strings.HasPrefix(issue.message, "same file") {
+			conflicting = append(conflicting, issue)
+		}
+	}
+	if len(conflicting) != 1 || conflicting[0].fix != nil || !strings.Contains(conflicting[0].message, "run command") {
+		t.Fatalf("issues for the conflicting duplicate = %+v, want one reported without a fix", conflicting)
+	}
+
+	kept, removed := compactConfigs(configs)
+	if len(kept) != 2 || len(removed) != 1 || removed[0].Name != "nginx main" {
+		t.Fatalf("compactConfigs kept %+v, removed %+v; want only the compatible duplicate merged", kept, removed)
+	}
+	got := kept[0]
+	if got.Alias != "ng" || got.Run != "nginx -t" || got.Description != "Main config" || got.ReviewEvery != "90d" ||
+		got.Source == "" || got.Checksum != "sha256:ab" || got.OpenCount != 3 {
+		t.Fatalf("merged entry lost fields: %+v", got)
+	}
+	if strings.Join(got.Tags, ",") != "web,prod" || !containsString(got.PreviousNames, "nginx main") {
+		t.Fatalf("merged tags %q, previous names %q", got.Tags, got.PreviousNames)
+	}
+}
+
+func TestLintFixRecordsMergedDuplicatesAsDeleted(t *testing.T) {
+	registry := filepath.Join(t.TempDir(), "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	if err := storage.New(registry).Save([]models.ConfigEntry{
+		{Name: "hosts", Path: "/etc/hosts", Type: "hosts", Description: "Hosts"},
+		{Name: "hosts copy", Path: "/etc/hosts", Description: "Hosts"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runLint(&cliContext{stdout: &out, stderr: &out, quiet: true}, []string{"--fix"}); err != nil {
+		t.Fatalf("lint --fix: %v\n%s", err, out.String())
+	}
+	store := storage.New(registry)
+	configs, err := store.Load()
+	if err != nil || len(configs) != 1 {
+		t.Fatalf("registry after lint --fix = %+v, %v", configs, err)
+	}
+	if deleted := store.Deleted(); len(deleted) != 1 || deleted[0].Name != "hosts copy" {
+		t.Fatalf("recently deleted = %+v, want the merged duplicate", deleted)
+	}
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// columnGap separates list columns
+const columnGap = 2
+
+// minColumnWidth keeps a squeezed or shrunk column readable
+const minColumnWidth = 3
+
+var columnLabels = map[string]string{
+	"name":        "Name",
+	"project":     "Project",
+	"type":        "Type",
+	"path":        "Path",
+	"description": "Description",
+	"alias":       "Alias",
+	"last_opened": "Opened",
+	"added":       "Added",
+	"open_count":  "Opens",
+	"size":        "Size",
+	"tags":        "Tags",
+}
+
+// columnState is the column arrangement mode. The columns being arranged
+// are those of the preset in use, or m.settings.Columns when preset is -1;
+// before restores them if the changes are dropped.
+type columnState struct {
+	cursor int
+	preset int
+	before []settings.Column
+}
+
+// listColumns returns the columns the list shows: those being arranged, or
+// those set for the terminal's width.
+func (m model) listColumns() []settings.Column {
+	if m.mode == ModeColumns {
+		return m.arranged()
+	}
+	return m.settings.ColumnsFor(m.width)
+}
+
+// arranged returns the columns the arrangement mode is changing.
+func (m model) arranged() []settings.Column {
+	if m.columns.preset >= 0 {
+		return m.settings.ColumnPresets[m.columns.preset].Columns
+	}
+	return m.settings.Columns
+}
+
+// setArranged replaces the columns the arrangement mode is changing.
+func (m *model) setArranged(columns []settings.Column) {
+	if m.columns.preset < 0 {
+		m.settings.Columns = columns
+		return
+	}
+	presets := append([]settings.ColumnPreset(nil), m.settings.ColumnPresets...)
+	presets[m.columns.preset].Columns = columns
+	m.settings.ColumnPresets = presets
+}
+
+// markWidth is the room the mark column takes at the start of list rows.
+func (m model) markWidth() int {
+	if len(m.marked) > 0 {
+		return 2
+	}
+	return 0
+}
+
+// columnValue is what the list shows for field in config's row.
+func (m model) columnValue(config models.ConfigEntry, field string) string {
+	switch field {
+	case "name":
+		return config.Name
+	case "project":
+		return config.Project
+	case "type":
+		return config.Type
+	case "path":
+		return config.Path
+	case "description":
+		return config.Description
+	case "alias":
+		return config.Alias
+	case "tags":
+		return formatTags(config.Tags)
+	case "last_opened":
+		if config.LastOpened.IsZero() {
+			return "never"
+		}
+		return m.formatTime(config.LastOpened, true)
+	case "added":
+		if config.Added.IsZero() {
+			return ""
+		}
+		return m.formatTime(config.Added, true)
+	case "open_count":
+		return strconv.Itoa(config.OpenCount)
+	case "size":
+		if config.Size == 0 {
+			return ""
+		}
+		return formatSize(config.Size)
+	}
+	return ""
+}
+
+// columnWidths fits columns into width: fixed widths are kept and the
+// columns without one share what is left.
+func columnWidths(columns []settings.Column, width int) []int {
+	widths := make([]int, len(columns))
+	free := width - columnGap*(len(columns)-1)
+	flexible := 0
+	for i, c := range columns {
+		if c.Width > 0 {
+			widths[i] = c.Width
+			free -= c.Width
+		} else {
+			flexible++
+		}
+	}
+	for i, c := range columns {
+		if c.Width == 0 {
+			widths[i] = max(free/flexible, minColumnWidth)
+			free -= widths[i]
+			flexible--
+		}
+	}
+	return widths
+}
+
+// columnView is the part of the configured columns that fits the list
+// after scrolling: the columns shown with their widths and their index in
+// the configured columns, and the columns left out on either side.
+type columnView struct {
+	columns     []settings.Column
+	widths      []int
+	index       []int
+	left, right []settings.Column
+}
+
+// columnLayout scrolls past m.colOffset columns and shows as many of the
+// rest as fit in width. The name column is pinned so rows stay
+// identifiable. While arranging, the highlighted column is kept in view.
+func (m model) columnLayout(width int) columnView {
+	all := m.listColumns()
+	scrollable := 0 // columns other than name, the ones ←/→ move through
+	cursorAt := -1  // the highlighted column's place among them
+	for i, c := range all {
+		if c.Field == "name" {
+			continue
+		}
+		if i == m.columns.cursor {
+			cursorAt = scrollable
+		}
+		scrollable++
+	}
+	maxOffset := max(scrollable-1, 0)
+	offset := min(max(m.colOffset, 0), maxOffset)
+	if m.mode == ModeColumns && cursorAt >= 0 {
+		offset = min(offset, cursorAt)
+	}
+	for {
+		view := fitColumns(all, offset, width)
+		if m.mode != ModeColumns || offset >= maxOffset || slices.Contains(view.index, m.columns.cursor) {
+			return view
+		}
+		offset++
+	}
+}
+
+// fitColumns shows the name column and the others from the offset-th on,
+// dropping columns from the end until they fit in width with every
+// automatic column at least as wide as its label. The name column and one
+// other are always shown.
+func fitColumns(all []settings.Column, offset, width int) columnView {
+	var view columnView
+	for i, c := range all {
+		if c.Field != "name" && len(view.left) < offset {
+			view.left = append(view.left, c)
+			continue
+		}
+		view.columns = append(view.columns, c)
+		view.index = append(view.index, i)
+	}
+	for {
+		view.widths = columnWidths(view.columns, width)
+		if len(view.columns) <= 2 || columnsFit(view.columns, view.widths, width) {
+			return view
+		}
+		last := len(view.columns) - 1
+		if view.columns[last].Field == "name" {
+			last--
+		}
+		view.right = append([]settings.Column{view.columns[last]}, view.right...)
+		view.columns = slices.Delete(view.columns, last, last+1)
+		view.index = slices.Delete(view.index, last, last+1)
+	}
+}
+
+func columnsFit(columns []settings.Column, widths []int, width int) bool {
+	total := columnGap * (len(columns) - 1)
+	for i, c := range columns {
+		if c.Width == 0 && widths[i] < max(lipgloss.Width(columnLabels[c.Field]), minColumnWidth) {
+			return false
+		}
+		total += widths[i]
+	}
+	return total <= width
+}
+
+// columnsWidth is the room list rows have for their columns.
+func (m model) columnsWidth() int {
+	left, _ := m.panelWidths()
+	return max(left-4, 12) - m.markWidth()
+}
+
+// scrolledColumns names the columns scrolled out of view on each side, e.g.
+// "◀ Name | Description ▶", or "" when every column is shown.
+func scrolledColumns(view columnView) string {
+	labels := func(columns []settings.Column) string {
+		names := make([]string, len(columns))
+		for i, c := range columns {
+			names[i] = columnLabels[c.Field]
+		}
+		return strings.Join(names, ", ")
+	}
+	var parts []string
+	if len(view.left) > 0 {
+		parts = append(parts, "◀ "+labels(view.left))
+	}
+	if len(view.right) > 0 {
+		parts = append(parts, labels(view.right)+" ▶")
+	}
+	return strings.Join(parts, " | ")
+}
+
+// columnRow lays out config's list row in the columns in view, with badges
+// following the name.
+func (m model) columnRow(config models.ConfigEntry, badges string, width int) string {
+	// Tags follow the name unless they have a column of their own
+	if tags := formatTags(config.Tags); tags != "" && !hasColumn(m.listColumns(), "tags") {
+		badges += " " + tags
+	}
+	view := m.columnLayout(width)
+	columns := view.columns
+	if len(columns) == 1 && columns[0].Field == "name" && len(view.right) == 0 {
+		return config.Name + badges
+	}
+	cells := make([]string, len(columns))
+	for i, c := range columns {
+		value := m.columnValue(config, c.Field)
+		if c.Field == "name" {
+			value += badges
+		}
+		cells[i] = padCell(truncate(value, view.widths[i]), view.widths[i], i == len(columns)-1)
+	}
+	return strings.Join(cells, strings.Repeat(" ", columnGap))
+}
+
+// columnHeader labels the list columns, highlighting the one being arranged.
+// A list showing only names has no header outside the arrangement mode.
+func (m model) columnHeader(width int) string {
+	all := m.listColumns()
+	if m.mode != ModeColumns && len(all) == 1 && all[0].Field == "name" {
+		return ""
+	}
+	width -= m.markWidth()
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("243"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Underline(true)
+	view := m.columnLayout(width)
+	cells := make([]string, len(view.columns))
+	for i, c := range view.columns {
+		label := truncate(columnLabels[c.Field], view.widths[i])
+		if m.mode == ModeColumns && view.index[i] == m.columns.cursor {
+			label = selectedStyle.Render(label)
+		} else {
+			label = labelStyle.Render(label)
+		}
+		cells[i] = padCell(label, view.widths[i], i == len(view.columns)-1)
+	}
+	header := strings.Join(cells, strings.Repeat(" ", columnGap))
+	return strings.Repeat(" ", m.markWidth()) + truncate(header, width)
+}
+
+// scrollColumns moves the columns in view one to the left or right, stopping
+// once the first or last column is shown.
+func (m *model) scrollColumns(delta int) {
+	view := m.columnLayout(m.columnsWidth())
+	if delta < 0 && len(view.left) > 0 || delta > 0 && len(view.right) > 0 {
+		m.colOffset = len(view.left) + delta
+	}
+}
+
+// padCell fills s out to width, except in the last column where trailing
+// spaces would only be trimmed again.
+func padCell(s string, width int, last bool) string {
+	if last {
+		return s
+	}
+	if gap := width - lipgloss.Width(s); gap > 0 {
+		return s + strings.Repeat(" ", gap)
+	}
+	return s
+}
+
+// startColumns enters the column arrangement mode for the columns in use
+// at the terminal's width.
+func (m *model) startColumns() tea.Cmd {
+	m.columns = columnState{preset: m.settings.ColumnPresetFor(m.width)}
+	m.columns.before = m.arranged()
+	m.setArranged(m.settings.ColumnsFor(m.width))
+	m.mode = ModeColumns
+	return nil
+}
+
+// columnWidth is the width the highlighted column is shown at now.
+func (m model) columnWidth() int {
+	view := m.columnLayout(m.columnsWidth())
+	for i, index := range view.index {
+		if index == m.columns.cursor {
+			return view.widths[i]
+		}
+	}
+	return minColumnWidth
+}
+
+func (m model) updateColumns(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	columns := m.arranged()
+	cursor := m.columns.cursor
+	switch msg.String() {
+	case "esc":
+		m.setArranged(m.columns.before)
+		m.mode = ModeNormal
+		return m, showStatus("Column changes discarded")
+	case "enter":
+		path := settings.PathFor(m.storage.GetFilePath())
+		var err error
+		if m.columns.preset < 0 {
+			err = settings.Set(path, "columns", columns)
+		} else {
+			err = settings.Set(path, "column_presets", m.settings.ColumnPresets)
+		}
+		if err != nil {
+			return m, showError(fmt.Sprintf("Failed to save columns: %v", err))
+		}
+		m.mode = ModeNormal
+		return m, showSuccess("Columns saved to " + settings.FileName)
+	case "left", "h":
+		m.columns.cursor = max(cursor-1, 0)
+	case "right", "l":
+		m.columns.cursor = min(cursor+1, len(columns)-1)
+	case "shift+left", "alt+left", "H":
+		if cursor > 0 {
+			columns[cursor-1], columns[cursor] = columns[cursor], columns[cursor-1]
+			m.columns.cursor--
+		}
+	case "shift+right", "alt+right", "L":
+		if cursor < len(columns)-1 {
+			columns[cursor+1], columns[cursor] = columns[cursor], columns[cursor+1]
+			m.columns.cursor++
+		}
+	case "+", "=":
+		columns[cursor].Width = m.columnWidth() + 1
+	case "-":
+		columns[cursor].Width = max(m.columnWidth()-1, minColumnWidth)
+	case "0":
+		columns[cursor].Width = 0
+	case "a":
+		for _, field := range settings.ColumnFields {
+			if !hasColumn(columns, field) {
+				columns = append(columns[:cursor+1], append([]settings.Column{{Field: field}}, columns[cursor+1:]...)...)
+				m.setArranged(columns)
+				m.columns.cursor++
+				return m, nil
+			}
+		}
+		return m, showWarning("Every field already has a column")
+	case "x", "d":
+		if columns[cursor].Field == "name" {
+			return m, showWarning("The name column can't be removed")
+		}
+		m.setArranged(append(columns[:cursor], columns[cursor+1:]...))
+		m.columns.cursor = min(cursor, len(columns)-2)
+	}
+	return m, nil
+}
+
+func hasColumn(columns []settings.Column, field string) bool {
+	for _, c := range columns {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestColumnWidthsShareWhatFixedColumnsLeave(t *testing.T) {
+	columns := []settings.Column{{Field: "name"}, {Field: "project", Width: 10}, {Field: "path"}}
+	if got, want := columnWidths(columns, 40), []int{13, 10, 13}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("columnWidths = %v, want %v", got, want)
+	}
+	if got := columnWidths(columns, 10); got[0] != minColumnWidth || got[2] != minColumnWidth {
+		t.Fatalf("squeezed columns = %v, want at least %d wide", got, minColumnWidth)
+	}
+}
+
+func TestArrangeColumnsAndSaveThemToSettings(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, settings.FileName)
+	if err := os.WriteFile(settingsPath, []byte(`{"watch": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := model{width: 120, height: 24, editRow: -1, editCol: -1, deleteIndex: -1,
+		storage: storage.New(filepath.Join(dir, "zap-registry.json"))}
+	m.configs = []models.ConfigEntry{{Name: "nginx", Project: "webapp", Type: "ini", Path: "/etc/nginx/nginx.conf"}}
+	m.buildDisplayList()
+
+	key := func(k tea.KeyMsg) {
+		t.Helper()
+		updated, _ := m.Update(k)
+		m = updated.(model)
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	key(runes("L"))
+	if m.mode != ModeColumns {
+		t.Fatalf("L should arrange columns, mode = %v", m.mode)
+	}
+	key(runes("a")) // project after name
+	key(runes("a")) // type after project
+	key(tea.KeyMsg{Type: tea.KeyShiftLeft})
+	key(runes("0"))
+	for i := 0; i < 3; i++ {
+		key(runes("-"))
+	}
+	if got := m.settings.Columns; len(got) != 3 || got[0].Field != "name" || got[1].Field != "type" || got[2].Field != "project" {
+		t.Fatalf("columns = %+v, want name, type, project", got)
+	}
+	typeWidth := m.settings.Columns[1].Width
+	if typeWidth == 0 {
+		t.Fatal("- should fix the column's width")
+	}
+	screen := xansi.Strip(m.View())
+	if !strings.Contains(screen, "Name") || !strings.Contains(screen, "Type") || !strings.Contains(screen, "webapp") {
+		t.Fatalf("list should show the column header and values:\n%s", screen)
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeNormal {
+		t.Fatalf("enter should save and close, mode = %v", m.mode)
+	}
+	saved, err := settings.Load(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []settings.Column{{Field: "name"}, {Field: "type", Width: typeWidth}, {Field: "project"}}
+	if !saved.Watch || !reflect.DeepEqual(saved.Columns, want) {
+		t.Fatalf("saved settings = %+v, want watch kept and columns %+v", saved, want)
+	}
+
+	key(runes("L"))
+	key(runes("x"))
+	key(tea.KeyMsg{Type: tea.KeyEsc})
+	if !reflect.DeepEqual(m.settings.Columns, want) {
+		t.Fatalf("esc should discard changes, columns = %+v", m.settings.Columns)
+	}
+}
+
+func TestScrollingColumnsNamesTheHiddenOnes(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.settings.Columns = []settings.Column{{Field: "name"}, {Field: "project"}, {Field: "path"}, {Field: "description"}}
+	m.configs = []models.ConfigEntry{{Name: "nginx", Project: "webapp", Path: "/etc/nginx/nginx.conf", Description: "reverse proxy"}}
+	m.buildDisplayList()
+	key := func(k tea.KeyType) string {
+		t.Helper()
+		updated, _ := m.Update(tea.KeyMsg{Type: k})
+		m = updated.(model)
+		return xansi.Strip(m.View())
+	}
+
+	if screen := xansi.Strip(m.View()); !strings.Contains(screen, "Files  Description ▶") {
+		t.Fatalf("the column that doesn't fit should be named:\n%s", screen)
+	}
+	if screen := key(tea.KeyRight); !strings.Contains(screen, "◀ Project | Description ▶") {
+		t.Fatalf("after → both sides should be named:\n%s", screen)
+	}
+	key(tea.KeyRight)
+	screen := key(tea.KeyRight)
+	if m.colOffset != 2 || !strings.Contains(screen, "◀ Project, Path") || !strings.Contains(screen, "reverse proxy") {
+		t.Fatalf("→ should stop once the last column shows, offset %d:\n%s", m.colOffset, screen)
+	}
+	pinned := false
+	for _, line := range strings.Split(screen, "\n") {
+		if strings.Contains(line, "│ nginx ") && strings.Contains(line, "reverse proxy") {
+			pinned = true
+		}
+	}
+	if !pinned {
+		t.Fatalf("the name column should stay pinned while scrolled:\n%s", screen)
+	}
+	key(tea.KeyLeft)
+	if screen := key(tea.KeyLeft); m.colOffset != 0 || strings.Contains(screen, "◀") {
+		t.Fatalf("← should scroll back to the first column, offset %d:\n%s", m.colOffset, screen)
+	}
+}
+
+func TestColumnPresetsFollowTheTerminalWidth(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, settings.FileName)
+	m := model{width: 70, height: 24, editRow: -1, editCol: -1, deleteIndex: -1,
+		storage: storage.New(filepath.Join(dir, "zap-registry.json"))}
+	m.settings.Columns = []settings.Column{{Field: "name"}, {Field: "project"}, {Field: "path"}, {Field: "description"}}
+	m.settings.ColumnPresets = []settings.ColumnPreset{
+		{MaxWidth: 120, Columns: []settings.Column{{Field: "name"}, {Field: "project"}, {Field: "path"}}},
+		{MaxWidth: 80, Columns: []settings.Column{{Field: "name"}, {Field: "path"}}},
+	}
+	m.configs = []models.ConfigEntry{{Name: "nginx", Project: "webapp", Path: "/etc/nginx/nginx.conf", Description: "reverse proxy"}}
+	m.buildDisplayList()
+	fields := func() string {
+		var got []string
+		for _, c := range m.listColumns() {
+			got = append(got, c.Field)
+		}
+		return strings.Join(got, ",")
+	}
+
+	for _, tc := range []struct {
+		width int
+		want  string
+	}{
+		{70, "name,path"},
+		{80, "name,path"},
+		{100, "name,project,path"},
+		{200, "name,project,path,description"},
+	} {
+		m.width = tc.width
+		if got := fields(); got != tc.want {
+			t.Errorf("columns at width %d = %s, want %s", tc.width, got, tc.want)
+		}
+	}
+
+	m.width = 70
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}) // project after name
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	saved, err := settings.Load(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Columns) != 0 || len(saved.ColumnPresets) != 2 || len(saved.ColumnPresets[1].Columns) != 3 {
+		t.Fatalf("L should change the preset in use, saved %+v", saved)
+	}
+	if got := fields(); got != "name,project,path" {
+		t.Fatalf("columns after arranging = %s", got)
+	}
+	if m.settings.ColumnPresets[0].Columns[1].Field != "project" || len(m.settings.Columns) != 4 {
+		t.Fatalf("other column sets should be left alone: %+v", m.settings)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+)
+
+// composeSearchDepth is how many parent directories are searched for
+// compose files that may use a file.
+const composeSearchDepth = 3
+
+// composeFileNames are the file names docker compose looks for.
+var composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// composeActions validate a compose file with docker compose itself.
+func composeActions(config models.ConfigEntry) []fileAction {
+	file := shellQuote(editor.ExpandPath(config.Path))
+	return []fileAction{
+		{label: "Validate compose file", command: "docker compose -f " + file + " config --quiet && echo 'compose file is valid'"},
+		{label: "Show resolved compose config", command: "docker compose -f " + file + " config"},
+	}
+}
+
+// composeFilesNear returns compose files in path's directory and its
+// parents, nearest first.
+func composeFilesNear(path string) []string {
+	var found []string
+	dir := filepath.Dir(path)
+	for i := 0; i <= composeSearchDepth; i++ {
+		for _, name := range composeFileNames {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				found = append(found, candidate)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return found
+}
+
+// composeConfig is the part of `docker compose config --format json` output
+// needed to find which services use a file. Paths in it are absolute.
+type composeConfig struct {
+	Services map[string]struct {
+		Build *struct {
+			Context    string `json:"context"`
+			Dockerfile string `json:"dockerfile"`
+		} `json:"build"`
+		Volumes []struct {
+			Type   string `json:"type"`
+			Source string `json:"source"`
+			Target string `json:"target"`
+		} `json:"volumes"`
+		EnvFile json.RawMessage `json:"env_file"`
+	} `json:"services"`
+}
+
+// envFiles reads env_file, which compose renders either as a list of paths
+// or as a list of {path, required} objects depending on its version.
+func envFiles(raw json.RawMessage) []string {
+	var paths []string
+	if json.Unmarshal(raw, &paths) == nil {
+		return paths
+	}
+	var objects []struct {
+		Path string `json:"path"`
+	}
+	json.Unmarshal(raw, &objects)
+	for _, o := range objects {
+		paths = append(paths, o.Path)
+	}
+	return paths
+}
+
+// composeUses lists how services in cfg use path: as a Dockerfile, an
+// env file, or through a bind mount of the file or a directory holding it.
+func composeUses(cfg composeConfig, path string) []string {
+	var uses []string
+	for name, service := range cfg.Services {
+		if b := service.Build; b != nil && b.Context != "" {
+			dockerfile := b.Dockerfile
+			if dockerfile == "" {
+				dockerfile = "Dockerfile"
+			}
+			if !filepath.IsAbs(dockerfile) {
+				dockerfile = filepath.Join(b.Context, dockerfile)
+			}
+			if filepath.Clean(dockerfile) == path {
+				uses = append(uses, fmt.Sprintf("%s: builds from this Dockerfile", name))
+			}
+		}
+		for _, v := range service.Volumes {
+			if v.Type != "bind" || v.Source == "" {
+				continue
+			}
+			source := filepath.Clean(v.Source)
+			if source == path {
+				uses = append(uses, fmt.Sprintf("%s: mounted at %s", name, v.Target))
+			} else if rel, err := filepath.Rel(source, path); err == nil && !strings.HasPrefix(rel, "..") {
+				uses = append(uses, fmt.Sprintf("%s: mounted at %s", name, filepath.Join(v.Target, rel)))
+			}
+		}
+		for _, env := range envFiles(service.EnvFile) {
+			if filepath.Clean(env) == path {
+				uses = append(uses, fmt.Sprintf("%s: env_file", name))
+			}
+		}
+	}
+	sort.Strings(uses)
+	return uses
+}
+
+// composeReferences reports which services in nearby compose files use path.
+func composeReferences(path string) (string, error) {
+	path = filepath.Clean(path)
+	files := composeFilesNear(path)
+	if len(files) == 0 {
+		return "", fmt.Errorf("no compose file found near %s", path)
+	}
+
+	var out strings.Builder
+	for _, file := range files {
+		ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+		data, err := exec.CommandContext(ctx, "docker", "compose", "-f", file, "config", "--format", "json").Output()
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+		if err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				err = fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			if timedOut {
+				err = fmt.Errorf("timed out after %s", runTimeout)
+			}
+			fmt.Fprintf(&out, "%s\n  could not read: %v\n", file, err)
+			continue
+		}
+		var cfg composeConfig
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			fmt.Fprintf(&out, "%s\n  could not parse: %v\n", file, err)
+			continue
+		}
+		uses := composeUses(cfg, path)
+		fmt.Fprintln(&out, file)
+		if len(uses) == 0 {
+			fmt.Fprintln(&out, "  no services use this file")
+		}
+		for _, use := range uses {
+			fmt.Fprintln(&out, "  "+use)
+		}
+	}
+	return out.String(), nil
+}
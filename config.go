@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/LFroesch/zap/internal/editor"
 	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
 	"github.com/LFroesch/zap/internal/storage"
 )
 
@@ -16,6 +18,14 @@ const (
 )
 
 func resolveRegistryPath() (string, error) {
+	if registryOverride != "" {
+		return registryOverride, nil
+	}
+	return defaultRegistryPath()
+}
+
+// defaultRegistryPath is the registry used without --registry.
+func defaultRegistryPath() (string, error) {
 	if override := os.Getenv(registryPathEnv); override != "" {
 		return override, nil
 	}
@@ -52,6 +62,23 @@ func loadConfigs(store *storage.Storage) ([]models.ConfigEntry, error) {
 	return store.Load()
 }
 
+// withShared adds the entries of the shared registry named in settings, if
+// any, underneath configs.
+func withShared(configs []models.ConfigEntry, prefs settings.Settings) ([]models.ConfigEntry, error) {
+	if prefs.Shared == "" {
+		return configs, nil
+	}
+	path := editor.ExpandPath(prefs.Shared)
+	if exists, err := fileExists(path); err != nil || !exists {
+		return nil, fmt.Errorf("shared registry %s not found", path)
+	}
+	shared, err := storage.New(path).Load()
+	if err != nil {
+		return nil, fmt.Errorf("shared registry: %w", err)
+	}
+	return storage.MergeShared(configs, shared), nil
+}
+
 func fileExists(path string) (bool, error) {
 	_, err := os.Stat(path)
 	if err == nil {
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pendingOpen is an open waiting for y/n because a target is marked
+// confirm_open. A line above zero opens a single target at that line.
+type pendingOpen struct {
+	targets   []models.ConfigEntry
+	editorCmd string
+	remember  bool
+	line      int
+}
+
+// needsConfirmation reports whether any target asks before being opened.
+func needsConfirmation(targets []models.ConfigEntry, prefs settings.Settings) bool {
+	if !prefs.ConfirmOpen() {
+		return false
+	}
+	for _, config := range targets {
+		if config.ConfirmOpen {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *model) askOpen(open pendingOpen) tea.Cmd {
+	m.pendingOpen = open
+	m.mode = ModeConfirmOpen
+	return nil
+}
+
+func (m model) updateOpenConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	open := m.pendingOpen
+	switch msg.String() {
+	case "y", "Y":
+		m.mode = ModeNormal
+		m.pendingOpen = pendingOpen{}
+		if open.line > 0 {
+			return m, m.launchConfigAt(open.targets[0], open.editorCmd, open.line)
+		}
+		return m, m.launchConfigs(open.targets, open.editorCmd, open.remember)
+	case "n", "N", "esc":
+		m.mode = ModeNormal
+		m.pendingOpen = pendingOpen{}
+		return m, showStatus("Not opened")
+	}
+	return m, nil
+}
+
+// toggleConfirmOpen turns the y/n prompt before opening on or off for the
+// selected (or marked) entries.
+func (m *model) toggleConfirmOpen() tea.Cmd {
+	return m.toggleFlag(func(c *models.ConfigEntry) *bool { return &c.ConfirmOpen },
+		"%d file(s) will ask before opening", "%d file(s) open without asking")
+}
+
+// toggleFlag flips a boolean entry field for the selected (or marked)
+// entries and saves them: on for all unless every one already has it.
+// onMsg and offMsg format the number of entries changed.
+func (m *model) toggleFlag(flag func(*models.ConfigEntry) *bool, onMsg, offMsg string) tea.Cmd {
+	targets := m.markedConfigs()
+	if len(targets) == 0 {
+		if config := m.getConfigByDisplayIndex(m.cursor); config != nil {
+			targets = []models.ConfigEntry{*config}
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	enable := false
+	for i := range targets {
+		if !*flag(&targets[i]) {
+			enable = true
+		}
+	}
+	configs := make([]models.ConfigEntry, len(m.configs))
+	copy(configs, m.configs)
+	for _, target := range targets {
+		for i := range configs {
+			if configs[i].Equals(&target) {
+				*flag(&configs[i]) = enable
+				configs[i].Shared = false
+			}
+		}
+	}
+	if err := m.storage.Save(configs); err != nil {
+		return showError(fmt.Sprintf("Failed to save: %v", err))
+	}
+	m.configs = configs
+	m.marked = nil
+	m.cacheValid = false
+	m.buildDisplayList()
+	m.refreshRightViewport()
+	if enable {
+		return showSuccess(fmt.Sprintf(onMsg, len(targets)))
+	}
+	return showSuccess(fmt.Sprintf(offMsg, len(targets)))
+}
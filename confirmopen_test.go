@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestNeedsOpenConfirmation(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/etc/nginx/nginx.conf":         true,
+		"/root/.bashrc":                 true,
+		"scp://prod//etc/app.yaml":      true,
+		"/home/me/.ssh/id_ed25519":      true,
+		"/home/me/app/.env.production":  true,
+		"/home/me/certs/server.key":     true,
+		"/home/me/.zshrc":               false,
+		"/home/me/app/config.yaml":      false,
+		filepath.Join(t.TempDir(), "x"): false,
+	} {
+		if got := models.NeedsOpenConfirmation(path); got != want {
+			t.Errorf("NeedsOpenConfirmation(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestConfirmOpenAsksBeforeLaunching(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, storage: store, dryRun: true}
+	m.configs = []models.ConfigEntry{{Name: "prod nginx", Path: "/etc/nginx/nginx.conf", ConfirmOpen: true}}
+	m.buildDisplayList()
+	key := func(k tea.KeyMsg) tea.Cmd {
+		t.Helper()
+		updated, cmd := m.Update(k)
+		m = updated.(model)
+		return cmd
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	if cmd := key(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || m.mode != ModeConfirmOpen {
+		t.Fatalf("enter should ask first, mode = %v", m.mode)
+	}
+	if screen := xansi.Strip(m.View()); !strings.Contains(screen, "Open 'prod nginx'?") {
+		t.Fatalf("status bar should ask:\n%s", screen)
+	}
+	key(runes("n"))
+	if m.mode != ModeNormal || m.pendingOpen.targets != nil {
+		t.Fatalf("n should cancel, mode = %v", m.mode)
+	}
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd := key(runes("y")); cmd == nil || m.mode != ModeNormal {
+		t.Fatalf("y should launch the editor, mode = %v", m.mode)
+	}
+
+	key(runes("!"))
+	if m.configs[0].ConfirmOpen {
+		t.Fatal("! should turn the prompt off")
+	}
+	if saved, _ := store.Load(); len(saved) != 1 || saved[0].ConfirmOpen {
+		t.Fatalf("saved = %+v", saved)
+	}
+	if cmd := key(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil || m.mode != ModeNormal {
+		t.Fatal("enter should open straight away once the prompt is off")
+	}
+}
+
+func TestOpenCommandConfirmsMarkedEntries(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses the true command as the editor")
+	}
+	dir := t.TempDir()
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "true")
+	path := filepath.Join(dir, "sudoers")
+	if err := os.WriteFile(path, []byte("root ALL=(ALL) ALL\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := storage.New(registry)
+	if err := store.Save([]models.ConfigEntry{{Name: "sudoers", Path: path, ConfirmOpen: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runOpen(ctx, []string{"sudoers"}); exitCode(err) != exitInvalid {
+		t.Fatalf("without a terminal the open should be refused: %v", err)
+	}
+	ctx.interactive = true
+	ctx.stdin = strings.NewReader("n\n")
+	if err := runOpen(ctx, []string{"sudoers"}); err == nil || !strings.Contains(out.String(), "Open sudoers ("+path+")? [y/N]") {
+		t.Fatalf("answering n: %v\n%s", err, out.String())
+	}
+	if err := runOpen(ctx, []string{"--yes", "sudoers"}); err != nil {
+		t.Fatalf("--yes: %v", err)
+	}
+	if saved, _ := store.Load(); saved[0].OpenCount != 1 {
+		t.Fatalf("only the --yes open should count, got %d", saved[0].OpenCount)
+	}
+}
+
+func TestConfirmationPolicies(t *testing.T) {
+	off, on := false, true
+	prefs := settings.Settings{Confirm: settings.Confirmations{Delete: &off, Open: &off, Run: &on}}
+	targets := []models.ConfigEntry{{Name: "sudoers", ConfirmOpen: true}}
+	if needsConfirmation(targets, prefs) || !needsConfirmation(targets, settings.Settings{}) {
+		t.Fatal("open: false should skip asking, and asking is the default")
+	}
+	if !prefs.ConfirmRun(false) || (settings.Settings{}).ConfirmRun(false) || !(settings.Settings{}).ConfirmRun(true) {
+		t.Fatal("run: true should ask for every command, unset only for system-changing ones")
+	}
+
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	configs := []models.ConfigEntry{
+		{Name: "nginx", Path: "/etc/nginx.conf", Run: "nginx -t"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+	m := model{width: 100, height: 24, storage: store, settings: prefs, configs: configs, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(configs[0])
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	if m.mode != ModeActions || !m.actions.confirming {
+		t.Fatalf("x with run: true should ask first, mode = %v", m.mode)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.mode != ModeNormal {
+		t.Fatalf("declining the run should return to the list, mode = %v", m.mode)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+	m = updated.(model)
+	if m.mode != ModeNormal || len(m.configs) != 1 || m.configs[0].Name != "zshrc" {
+		t.Fatalf("D with delete: false should delete at once, configs = %+v", m.configs)
+	}
+
+	// Opening at a section follows open: false like every other open
+	m.dryRun = true
+	if cmd := m.openConfigAt(targets[0], 12); cmd == nil || m.mode != ModeNormal {
+		t.Fatalf("opening at a section with open: false should not ask, mode = %v", m.mode)
+	}
+	m.settings = settings.Settings{}
+	if cmd := m.openConfigAt(targets[0], 12); cmd != nil || m.mode != ModeConfirmOpen || m.pendingOpen.line != 12 {
+		t.Fatalf("opening at a section should ask by default, mode = %v", m.mode)
+	}
+}
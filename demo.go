@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+// demoFile is one example file written for --demo and the registry entry
+// that lists it.
+type demoFile struct {
+	path    string // relative to the demo directory
+	content string
+	entry   models.ConfigEntry
+	opened  time.Duration // how long ago it was last opened, 0 if never
+}
+
+var demoProjects = []models.Project{
+	{Name: "webapp", Description: "Storefront API and its reverse proxy", Color: "39"},
+	{Name: "homelab", Description: "Monitoring and services on the home server", Color: "208"},
+	{Name: "dotfiles", Description: "Shell and tool configuration", Color: "141"},
+}
+
+var demoFiles = []demoFile{
+	{
+		path: "webapp/docker-compose.yml",
+		content: `services:
+  api:
+    build: .
+    env_file: .env
+    ports:
+      - "8080:8080"
+  proxy:
+    image: nginx:1.27
+    volumes:
+      - ./nginx.conf:/etc/nginx/nginx.conf:ro
+    ports:
+      - "80:80"
+`,
+		entry:  models.ConfigEntry{Name: "compose", Alias: "dc", Project: "webapp", Description: "API and proxy services", OpenCount: 14, Run: "docker compose config -q"},
+		opened: 2 * time.Hour,
+	},
+	{
+		path: "webapp/nginx.conf",
+		content: `events {}
+
+http {
+    server {
+        listen 80;
+        location / {
+            proxy_pass http://api:8080;
+        }
+    }
+}
+`,
+		entry:  models.ConfigEntry{Name: "nginx", Project: "webapp", Description: "Reverse proxy in front of the API", OpenCount: 6, ReviewEvery: "90d"},
+		opened: 120 * 24 * time.Hour,
+	},
+	{
+		path: "webapp/.env",
+		content: `DATABASE_URL=postgres://shop:secret@db:5432/shop
+STRIPE_KEY=sk_test_123
+LOG_LEVEL=info
+LOG_LEVEL=debug
+`,
+		entry:  models.ConfigEntry{Name: "env", Project: "webapp", Description: "API secrets and settings", OpenCount: 9},
+		opened: 26 * time.Hour,
+	},
+	{
+		path: "homelab/prometheus.yml",
+		content: `global:
+  scrape_interval: 30s
+
+scrape_configs:
+  - job_name: node
+    static_configs:
+      - targets: ["nas:9100", "pi:9100"]
+`,
+		entry:  models.ConfigEntry{Name: "prometheus", Project: "homelab", Description: "Scrape targets", OpenCount: 3, Tags: []string{"monitoring"}},
+		opened: 9 * 24 * time.Hour,
+	},
+	{
+		path: "homelab/backup.service",
+		content: `[Unit]
+Description=Nightly backup
+
+[Service]
+Type=oneshot
+ExecStart=/usr/local/bin/backup.sh
+`,
+		entry: models.ConfigEntry{Name: "backup unit", Project: "homelab", Description: "Nightly backup job"},
+	},
+	{
+		path: "homelab/backup.log",
+		content: `2024-05-01 03:00:01 backup started
+2024-05-01 03:04:12 uploaded 1.2 GB
+2024-05-01 03:04:13 backup finished
+`,
+		entry:  models.ConfigEntry{Name: "backup log", Project: "homelab", Description: "Output of the nightly backup", OpenCount: 2},
+		opened: 3 * 24 * time.Hour,
+	},
+	{
+		path: "dotfiles/zshrc",
+		content: `export EDITOR=nvim
+alias gs='git status'
+alias k=kubectl
+`,
+		entry:  models.ConfigEntry{Name: "zshrc", Project: "dotfiles", Description: "Shell aliases and exports", OpenCount: 21},
+		opened: 30 * time.Minute,
+	},
+	{
+		path: "dotfiles/ssh_config",
+		content: `Host nas
+    HostName 192.168.1.10
+    User admin
+
+Host pi
+    HostName 192.168.1.20
+    User pi
+`,
+		entry:  models.ConfigEntry{Name: "ssh config", Project: "dotfiles", Description: "Hosts on the home network", OpenCount: 5},
+		opened: 5 * 24 * time.Hour,
+	},
+	{
+		path: "dotfiles/gitconfig",
+		content: `[user]
+    name = Demo User
+    email = demo@example.com
+[alias]
+    co = checkout
+`,
+		entry: models.ConfigEntry{Name: "gitconfig", Project: "dotfiles", Description: "Git identity and aliases", Archived: true},
+	},
+}
+
+// setupDemo writes the example files and a registry listing them to a new
+// temporary directory, so --demo never reads or writes the real registry.
+// cleanup removes the directory.
+func setupDemo() (store *storage.Storage, configs []models.ConfigEntry, cleanup func(), err error) {
+	dir, err := os.MkdirTemp("", "zap-demo-")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("create demo directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	now := time.Now()
+	for i, file := range demoFiles {
+		path := filepath.Join(dir, file.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			cleanup()
+			return nil, nil, nil, err
+		}
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			cleanup()
+			return nil, nil, nil, err
+		}
+
+		config := file.entry
+		config.Path = path
+		config.Type = models.DetectFileType(path)
+		config.Size = int64(len(file.content))
+		config.Added = now.Add(-time.Duration(60-i) * 24 * time.Hour)
+		if file.opened > 0 {
+			config.LastOpened = now.Add(-file.opened)
+		}
+		configs = append(configs, config)
+	}
+
+	store = storage.New(filepath.Join(dir, "zap-registry.json"))
+	projects := make([]models.Project, len(demoProjects))
+	for i, project := range demoProjects {
+		project.Root = filepath.Join(dir, project.Name)
+		projects[i] = project
+	}
+	store.SetProjects(projects)
+	store.SetOnboarded(true)
+	for day := 0; day < 60; day++ {
+		for n := 0; n < (day*7)%5; n++ {
+			store.RecordActivity(now.Add(-time.Duration(day) * 24 * time.Hour))
+		}
+	}
+	if err := store.Save(configs); err != nil {
+		cleanup()
+		return nil, nil, nil, err
+	}
+	return store, configs, cleanup, nil
+}
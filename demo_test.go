@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDemoRegistryLivesInATemporaryDirectory(t *testing.T) {
+	store, configs, cleanup, err := setupDemo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(store.GetFilePath())
+	if len(configs) != len(demoFiles) {
+		t.Fatalf("got %d demo entries, want %d", len(configs), len(demoFiles))
+	}
+	for _, config := range configs {
+		if _, err := os.Stat(config.Path); err != nil {
+			t.Errorf("demo file for %s: %v", config.Name, err)
+		}
+	}
+	saved, err := store.Load()
+	if err != nil || len(saved) != len(configs) || len(store.Projects()) != len(demoProjects) {
+		t.Fatalf("reloaded demo registry: %d entries, %d projects, %v", len(saved), len(store.Projects()), err)
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("demo directory %s left behind", dir)
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// diffContext is how many unchanged lines surround each change in a diff
+const diffContext = 3
+
+// maxDiffCells bounds the line-comparison table, so huge files are refused
+// instead of exhausting memory
+const maxDiffCells = 4_000_000
+
+var sourceClient = &http.Client{Timeout: 30 * time.Second}
+
+// isURL reports whether source is fetched over HTTP rather than read from disk.
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// readSource loads an entry's canonical source from a URL or a path, reading
+// no more than limit bytes.
+func readSource(source string, limit int64) ([]byte, error) {
+	var r io.Reader
+	if isURL(source) {
+		resp, err := sourceClient.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", source, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(editor.ExpandPath(source))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("source is over the %s large_file_kb limit", formatSize(limit))
+	}
+	return data, nil
+}
+
+// startSourceDiff compares the selected entry's file against its canonical
+// source and shows the differences in the results pane.
+func (m *model) startSourceDiff() tea.Cmd {
+	config := m.getConfigByDisplayIndex(m.cursor)
+	if config == nil {
+		return nil
+	}
+	if config.Source == "" {
+		return showWarning("No source set for " + config.Name + " (add one with e)")
+	}
+	limit := m.settings.LargeFileBytes()
+	entry := *config
+	return m.runFuncInPane(entry, "diff "+entry.Source+" "+filepath.Base(entry.Path), func() (string, error) {
+		return sourceDiff(entry, limit)
+	})
+}
+
+// sourceDiff returns a unified diff from entry's source to its file, and an
+// error describing the drift when they differ.
+func sourceDiff(entry models.ConfigEntry, limit int64) (string, error) {
+	source, err := readSource(entry.Source, limit)
+	if err != nil {
+		return "", fmt.Errorf("read source: %w", err)
+	}
+	local, err := readSource(entry.Path, limit)
+	if err != nil {
+		return "", fmt.Errorf("read file: %w", err)
+	}
+	if editor.IsBinary(source) || editor.IsBinary(local) {
+		if string(source) == string(local) {
+			return "binary files are identical", nil
+		}
+		return "", fmt.Errorf("binary files differ")
+	}
+
+	lines, changed, err := unifiedDiff(entry.Source, entry.Path, splitLines(source), splitLines(local))
+	if err != nil {
+		return "", err
+	}
+	if changed == 0 {
+		return "file matches its source", nil
+	}
+
+	removed := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	added := lipgloss.NewStyle().Foreground(lipgloss.Color("82"))
+	hunk := lipgloss.NewStyle().Foreground(lipgloss.Color("117"))
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = hunk.Render(line)
+		case i < 2:
+			// --- and +++ headers
+		case strings.HasPrefix(line, "-"):
+			lines[i] = removed.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = added.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n"), fmt.Errorf("%d line(s) differ from the source", changed)
+}
+
+// splitLines splits text into lines without their line endings.
+func splitLines(data []byte) []string {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added.
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff describes how to turn a into b in unified diff format,
+// returning the output lines and how many lines were removed or added.
+func unifiedDiff(aName, bName string, a, b []string) ([]string, int, error) {
+	ops, err := diffOps(a, b)
+	if err != nil {
+		return nil, 0, err
+	}
+	changed := 0
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return nil, 0, nil
+	}
+
+	out := []string{"--- " + aName, "+++ " + bName}
+	for start := 0; start < len(ops); {
+		// Find the next change and the extent of its hunk, merging changes
+		// whose context would overlap
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		end := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*diffContext {
+				break
+			}
+		}
+		from := max(first-diffContext, start)
+		to := min(end+diffContext, len(ops))
+
+		aLine, bLine := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != '+' {
+				aLine++
+			}
+			if op.kind != '-' {
+				bLine++
+			}
+		}
+		aCount, bCount := 0, 0
+		var body []string
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+			body = append(body, string(op.kind)+op.text)
+		}
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@", hunkRange(aLine, aCount), hunkRange(bLine, bCount)))
+		out = append(out, body...)
+		start = to
+	}
+	return out, changed, nil
+}
+
+// hunkRange formats a hunk's start line and length; empty ranges start on
+// the line before, as diff -u writes them.
+func hunkRange(line, count int) string {
+	if count == 0 {
+		line--
+	}
+	if count == 1 {
+		return fmt.Sprint(line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// diffOps builds an edit script from a to b using a longest common
+// subsequence of lines, after setting aside their common start and end.
+func diffOps(a, b []string) ([]diffOp, error) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(midA)+1)*(len(midB)+1) > maxDiffCells {
+		return nil, fmt.Errorf("files differ in too many lines to compare")
+	}
+
+	// lcs[i][j] is the LCS length of midA[i:] and midB[j:]
+	lcs := make([][]int32, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			ops = append(ops, diffOp{' ', midA[i]})
+			i++
+			j++
+		case j == len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', midA[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', midB[j]})
+			j++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops, nil
+}
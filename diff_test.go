@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+)
+
+func TestUnifiedDiffGroupsChangesIntoHunks(t *testing.T) {
+	a := strings.Split("a b c d e f g h i j k l m n o", " ")
+	b := strings.Split("a b C d e f g h i j k l m o p", " ")
+	lines, changed, err := unifiedDiff("old", "new", a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"--- old", "+++ new",
+		"@@ -1,6 +1,6 @@", " a", " b", "-c", "+C", " d", " e", " f",
+		"@@ -11,5 +11,5 @@", " k", " l", " m", "-n", " o", "+p",
+	}
+	if changed != 4 || strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("diff (%d changed):\n%s", changed, strings.Join(lines, "\n"))
+	}
+
+	if lines, changed, _ := unifiedDiff("old", "new", a, a); changed != 0 || lines != nil {
+		t.Fatalf("identical input gave %q", lines)
+	}
+}
+
+func TestSourceDiffComparesAgainstURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("listen 80;\nworkers 4;\n"))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "nginx.conf")
+	if err := os.WriteFile(path, []byte("listen 8080;\nworkers 4;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entry := models.ConfigEntry{Name: "nginx", Path: path, Source: srv.URL + "/nginx.conf"}
+
+	out, err := sourceDiff(entry, 1<<20)
+	if err == nil || !strings.Contains(err.Error(), "2 line(s) differ") {
+		t.Fatalf("err = %v, want drift reported", err)
+	}
+	if !strings.Contains(out, "-listen 80;") || !strings.Contains(out, "+listen 8080;") {
+		t.Fatalf("diff output:\n%s", out)
+	}
+
+	if err := os.WriteFile(path, []byte("listen 80;\nworkers 4;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := sourceDiff(entry, 1<<20); err != nil || out != "file matches its source" {
+		t.Fatalf("matching file: %q, %v", out, err)
+	}
+}
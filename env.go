@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/editor"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// envVar is one assignment in a .env file.
+type envVar struct {
+	key   string
+	empty bool
+	line  int
+}
+
+// parseEnv reads the assignments in a .env file, skipping comments and
+// blank lines. An optional leading "export" is ignored.
+func parseEnv(data string) []envVar {
+	var vars []envVar
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		vars = append(vars, envVar{key: strings.TrimSpace(key), empty: value == "", line: n})
+	}
+	return vars
+}
+
+// envDuplicates returns each key assigned more than once with its lines, in
+// order of first appearance.
+func envDuplicates(vars []envVar) []string {
+	lines := map[string][]string{}
+	var order []string
+	for _, v := range vars {
+		if _, seen := lines[v.key]; !seen {
+			order = append(order, v.key)
+		}
+		lines[v.key] = append(lines[v.key], fmt.Sprint(v.line))
+	}
+	var dups []string
+	for _, key := range order {
+		if len(lines[key]) > 1 {
+			dups = append(dups, fmt.Sprintf("%s (lines %s)", key, strings.Join(lines[key], ", ")))
+		}
+	}
+	return dups
+}
+
+// envPreviewLines lists a .env file's variable names with their values
+// masked, so secrets never reach the screen.
+func envPreviewLines(path string) ([]string, error) {
+	data, err := os.ReadFile(editor.ExpandPath(path))
+	if err != nil {
+		return nil, err
+	}
+	vars := parseEnv(string(data))
+	if len(vars) == 0 {
+		return []string{"(no variables)"}, nil
+	}
+
+	warn := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	var lines []string
+	for _, dup := range envDuplicates(vars) {
+		lines = append(lines, warn.Render("⚠ duplicate key: "+dup))
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	for _, v := range vars {
+		value := "••••••"
+		if v.empty {
+			value = "(empty)"
+		}
+		lines = append(lines, fmt.Sprintf("%s = %s", v.key, value))
+	}
+	return lines, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+)
+
+func TestEnvFilesAreDetected(t *testing.T) {
+	for _, name := range []string{".env", ".env.local", "prod.env"} {
+		if got := models.DetectFileType("/app/" + name); got != "env" {
+			t.Errorf("DetectFileType(%q) = %q, want env", name, got)
+		}
+	}
+}
+
+func TestEnvPreviewMasksValuesAndFlagsDuplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	data := "# database\nexport DB_PASSWORD=hunter2\nAPI_KEY=\"sk-secret\"\n\nEMPTY=\nDB_PASSWORD=other\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := envPreviewLines(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := strings.Join(lines, "\n")
+	for _, secret := range []string{"hunter2", "sk-secret", "other"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("preview leaks %q:\n%s", secret, out)
+		}
+	}
+	for _, want := range []string{"DB_PASSWORD (lines 2, 6)", "API_KEY = ••••••", "EMPTY = (empty)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("preview missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+// runFetch downloads a file and registers it in one step. The URL is kept as
+// the entry's source so d can later show how the copy has drifted.
+func runFetch(ctx *cliContext, args []string) error {
+	const usage = "fetch [--name n] [--project p] [--description d] [--force] <url> <dest>"
+	fs := ctx.flagSet("fetch")
+	name := fs.String("name", "", "Entry name (default: the file name)")
+	project := fs.String("project", "", "Project (default: the project whose root holds dest)")
+	description := fs.String("description", "", "Description (default: where the file came from)")
+	force := fs.Bool("force", false, "Overwrite dest if it exists")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 2 {
+		return usageError(usage)
+	}
+	rawURL := rest[0]
+	u, err := url.Parse(rawURL)
+	if err != nil || !isURL(rawURL) || u.Host == "" {
+		return invalidf("%q is not an http(s) URL", rawURL)
+	}
+
+	dest, err := filepath.Abs(editor.ExpandPath(rest[1]))
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dest); (err == nil && info.IsDir()) || strings.HasSuffix(rest[1], "/") {
+		dest = filepath.Join(dest, urlFileName(u))
+	}
+
+	store, configs, prefs, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	if dup := storage.FindDuplicates(configs, dest); dup != nil {
+		return invalidf("%s is already registered as %s", dest, dup.Name)
+	}
+	if _, err := os.Stat(dest); err == nil && !*force {
+		return invalidf("%s already exists; use --force to overwrite it", dest)
+	}
+
+	data, err := readSource(rawURL, prefs.LargeFileBytes())
+	if err != nil {
+		return fmt.Errorf("download %s: %w", rawURL, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return err
+	}
+
+	config := models.ConfigEntry{
+		Name:        *name,
+		Path:        dest,
+		Type:        models.DetectFileType(dest),
+		Project:     *project,
+		Description: *description,
+		Source:      rawURL,
+		Added:       time.Now(),
+		Size:        int64(len(data)),
+		ConfirmOpen: models.NeedsOpenConfirmation(dest),
+	}
+	if config.Name == "" {
+		config.Name = filepath.Base(dest)
+	}
+	if config.Project == "" {
+		config.Project = models.ProjectForPath(store.Projects(), dest)
+	}
+	if config.Description == "" {
+		config.Description = "Fetched from " + u.Host
+	}
+	if err := store.Save(append(configs, config)); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.stdout, "fetched  %s  %s (%s)\n", config.Name, dest, formatSize(config.Size))
+	return nil
+}
+
+// urlFileName is the file name a URL's path ends in, for downloads saved
+// into a directory.
+func urlFileName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		return "download"
+	}
+	return name
+}
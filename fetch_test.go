@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+func TestFetchDownloadsAndRegisters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("set -g mouse on\n"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	store := storage.New(registry)
+	store.SetProjects([]models.Project{{Name: "dotfiles", Root: filepath.Join(dir, "dots")}})
+	if err := store.Save(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out}
+	url := srv.URL + "/raw/tmux.conf"
+	if err := runFetch(ctx, []string{url, filepath.Join(dir, "dots") + "/"}); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	dest := filepath.Join(dir, "dots", "tmux.conf")
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "set -g mouse on\n" {
+		t.Fatalf("downloaded file = %q, %v", data, err)
+	}
+	configs, err := storage.New(registry).Load()
+	if err != nil || len(configs) != 1 {
+		t.Fatalf("registry = %+v, %v", configs, err)
+	}
+	got := configs[0]
+	if got.Name != "tmux.conf" || got.Path != dest || got.Type != "ini" || got.Project != "dotfiles" || got.Source != url {
+		t.Fatalf("registered entry = %+v", got)
+	}
+
+	if err := runFetch(ctx, []string{url, filepath.Join(dir, "other.conf")}); err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	if err := runFetch(ctx, []string{url, filepath.Join(dir, "other.conf")}); exitCode(err) != exitInvalid {
+		t.Fatalf("fetching onto a registered file: %v", err)
+	}
+}
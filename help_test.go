@@ -26,3 +26,17 @@ func TestHelpViewRenders(t *testing.T) {
 		t.Fatalf("expected help view height <= %d, got %d", m.height, got)
 	}
 }
+
+func TestHelpFooterShowsBuildInfo(t *testing.T) {
+	oldCommit, oldDate := commit, buildDate
+	commit, buildDate = "0123456789abcdef", "2024-05-01T10:00:00Z"
+	defer func() { commit, buildDate = oldCommit, oldDate }()
+
+	if got := versionLine(); !strings.Contains(got, "0123456789ab") || !strings.Contains(got, "built 2024-05-01T10:00:00Z") {
+		t.Fatalf("versionLine = %q", got)
+	}
+	m := model{width: 100, height: 24, mode: ModeHelp}
+	if view := m.View(); !strings.Contains(view, "built 2024-05-01") {
+		t.Fatal("expected the help screen to show the build details")
+	}
+}
@@ -2,13 +2,22 @@ package main
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"math"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/LFroesch/zap/internal/editor"
 	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
 	"github.com/LFroesch/zap/internal/storage"
 	"github.com/LFroesch/zap/internal/ui"
 
@@ -18,11 +27,21 @@ import (
 
 type statusMsg struct {
 	message string
+	level   ui.StatusLevel
 }
 
+// showStatus shows an informational message in the status bar.
 func showStatus(msg string) tea.Cmd {
+	return showStatusLevel(ui.StatusInfo, msg)
+}
+
+func showSuccess(msg string) tea.Cmd { return showStatusLevel(ui.StatusSuccess, msg) }
+func showWarning(msg string) tea.Cmd { return showStatusLevel(ui.StatusWarn, msg) }
+func showError(msg string) tea.Cmd   { return showStatusLevel(ui.StatusError, msg) }
+
+func showStatusLevel(level ui.StatusLevel, msg string) tea.Cmd {
 	return func() tea.Msg {
-		return statusMsg{message: msg}
+		return statusMsg{message: msg, level: level}
 	}
 }
 
@@ -34,10 +53,25 @@ func (m model) statusBarHeight() int {
 	return 1
 }
 
+// editAssistHeight is 1 while the edit input has a validation hint or
+// completions to show beneath the list.
+func (m model) editAssistHeight() int {
+	if m.mode != ModeEdit && m.mode != ModeAdd {
+		return 0
+	}
+	if hint, _ := m.editFieldHint(); hint != "" {
+		return 1
+	}
+	if len(m.visibleSuggestions()) > 0 {
+		return 1
+	}
+	return 0
+}
+
 func (m model) mainContentHeight() int {
 	// Header and status are single-line bars. Avoid rendering them here because
 	// help mode status text depends on help sizing, which would recurse.
-	height := m.height - m.headerHeight() - m.statusBarHeight()
+	height := m.height - m.headerHeight() - m.statusBarHeight() - m.editAssistHeight()
 	if height < 3 {
 		return 3
 	}
@@ -46,40 +80,51 @@ func (m model) mainContentHeight() int {
 
 func (m *model) startEdit() tea.Cmd {
 	if len(m.configs) == 0 {
-		return showStatus("❌ No files to edit")
+		return showError("No files to edit")
 	}
 
 	displayIndex := m.cursor
 	m.editRow = m.getOriginalIndexByDisplayIndex(displayIndex)
 	if m.editRow == -1 {
-		return showStatus("❌ Invalid selection")
+		return showError("Invalid selection")
 	}
 
 	m.mode = ModeEdit
+	m.draft = m.configs[m.editRow]
+	m.draft.Tags = append([]string(nil), m.draft.Tags...)
+	m.draftDirty = false
 	m.editCol = 0
 	m.loadEditField()
 	m.textInput.Focus()
+	m.refreshRightViewport()
 	return nil
 }
 
 func (m *model) startFileEdit() tea.Cmd {
 	config := m.getConfigByDisplayIndex(m.cursor)
 	if config == nil {
-		return showStatus("❌ No file selected")
+		return showError("No file selected")
+	}
+	if config.ReadOnly {
+		return showWarning(config.Name + " is read-only (l to unlock it)")
 	}
 
 	path := editor.ExpandPath(config.Path)
+	if err := m.checkFileSize(path); err != nil {
+		return showError(fmt.Sprintf("Can't edit inline: %v", err))
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return showStatus(fmt.Sprintf("❌ Failed to read file: %v", err))
+		return showError(fmt.Sprintf("Failed to read file: %v", err))
 	}
 	if bytes.IndexByte(data, 0) >= 0 {
-		return showStatus("❌ Binary files can't be edited inline")
+		return showError("Binary files can't be edited inline")
 	}
 
 	m.mode = ModeFileEdit
 	m.fileEditPath = path
 	m.fileEditLabel = config.Name
+	m.fileEditOrig = string(data)
 	m.fileEditArea.SetValue(string(data))
 	m.resizeFileEditArea()
 	for m.fileEditArea.Line() > 0 {
@@ -97,16 +142,7 @@ func (m *model) resizeFileEditArea() {
 		panelHeight = 3
 	}
 
-	leftWidth := m.width * 38 / 100
-	if leftWidth < 18 {
-		leftWidth = 18
-	}
-	rightWidth := m.width - leftWidth - 1
-	if rightWidth < 12 {
-		rightWidth = 12
-		leftWidth = m.width - rightWidth - 1
-	}
-
+	_, rightWidth := m.panelWidths()
 	contentWidth := rightWidth - 4
 	if contentWidth < 12 {
 		contentWidth = 12
@@ -120,6 +156,23 @@ func (m *model) resizeFileEditArea() {
 	m.fileEditArea.SetHeight(areaHeight)
 }
 
+// hasUnsavedChanges reports whether quitting now would drop work in progress:
+// a half-finished add, a modified metadata field, an open project edit, or an
+// unsaved inline edit.
+func (m model) hasUnsavedChanges() bool {
+	switch m.mode {
+	case ModeAdd:
+		return true
+	case ModeEdit:
+		return m.draftDirty || m.textInput.Value() != m.editOriginal
+	case ModeFileEdit:
+		return m.fileEditArea.Value() != m.fileEditOrig
+	case ModeProjectEdit:
+		return true
+	}
+	return false
+}
+
 func (m model) helpPageSize() int {
 	return ui.HelpBodyHeight(m.mainContentHeight())
 }
@@ -134,41 +187,84 @@ func (m model) maxHelpScroll() int {
 }
 
 func (m *model) addNewConfig() tea.Cmd {
-	newConfig := models.ConfigEntry{
-		Name:        "New File",
-		Path:        "~/path/to/file",
+	m.mode = ModeAdd
+	m.editRow = -1
+	m.draft = models.ConfigEntry{
+		Name:        placeholderName,
+		Path:        placeholderPath,
 		Type:        "txt",
 		Project:     "",
-		Description: "File description",
+		Description: placeholderDescription,
 	}
-
-	m.configs = append(m.configs, newConfig)
-	m.cacheValid = false
-	m.mode = ModeAdd
-	m.editRow = len(m.configs) - 1
+	m.draftDirty = false
 	m.editCol = 0
 	m.loadEditField()
 	m.textInput.Focus()
-	m.buildDisplayList()
 	m.refreshRightViewport()
 
-	// Move cursor to new entry
-	displayIndex := m.findConfigDisplayIndex(newConfig)
-	if displayIndex != -1 {
-		m.cursor = displayIndex
-		m.ensureCursorInBounds()
-		m.refreshRightViewport()
+	return showStatus("➕ Adding new file (Tab to next field, Enter to save)")
+}
+
+// addFromClipboard starts adding a file whose path was copied, with the
+// path, name, type and project already filled in.
+func (m *model) addFromClipboard() tea.Cmd {
+	text, err := readClipboard()
+	if err != nil {
+		return showError(fmt.Sprintf("Clipboard error: %v", err))
+	}
+	path, ok := clipboardPath(text)
+	if !ok {
+		return showWarning("The clipboard doesn't hold a file path")
+	}
+	if dup := storage.FindDuplicates(m.configs, path); dup != nil {
+		return showWarning(fmt.Sprintf("%s is already registered as '%s'", path, dup.Name))
 	}
 
-	return showStatus("➕ Adding new file (Tab to next field, Enter to save)")
+	m.addNewConfig()
+	entry := entryForPath(path, m.projects)
+	m.draft.Path, m.draft.Name, m.draft.Type, m.draft.Project = entry.Path, entry.Name, entry.Type, entry.Project
+	m.draftDirty = true
+	m.loadEditField()
+	m.refreshRightViewport()
+	return showStatus("➕ Adding " + path + " from the clipboard (Tab to next field, Enter to save)")
 }
 
-func (m *model) loadEditField() {
-	if m.editRow < 0 || m.editRow >= len(m.configs) {
-		return
+// clipboardPath picks a file path out of copied text: the first line, with
+// surrounding quotes, a file:// prefix and a trailing :line[:col] from an
+// error message removed.
+func clipboardPath(text string) (string, bool) {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	line = strings.Trim(strings.TrimSpace(line), "\"'`")
+	if u, err := url.Parse(line); err == nil && u.Scheme == "file" {
+		line = u.Path
 	}
+	if line == "" || !strings.ContainsAny(line, `/\`) || strings.Contains(line, "://") {
+		return "", false
+	}
+
+	path := editor.ExpandPath(line)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if !editor.FileExists(path) {
+		// nginx.conf:12 or main.go:40:7
+		trimmed := path
+		for i := 0; i < 2; i++ {
+			colon := strings.LastIndex(trimmed, ":")
+			if colon < 0 || colon == len(trimmed)-1 || strings.Trim(trimmed[colon+1:], "0123456789") != "" {
+				break
+			}
+			trimmed = trimmed[:colon]
+			if editor.FileExists(trimmed) {
+				return trimmed, true
+			}
+		}
+	}
+	return path, true
+}
 
-	config := m.configs[m.editRow]
+func (m *model) loadEditField() {
+	config := m.draft
 	var value string
 	switch m.editCol {
 	case 0:
@@ -177,73 +273,330 @@ func (m *model) loadEditField() {
 		value = config.Project
 	case 2:
 		value = config.Path
+		// A new file in a project with a root starts out in that root
+		if project := models.FindProject(m.projects, config.Project); m.mode == ModeAdd &&
+			value == placeholderPath && project != nil && project.Root != "" {
+			value = contractHome(project.Root) + string(filepath.Separator)
+		}
 	case 3:
+		// The input filters the type list; start unfiltered on the current type.
+		m.typeCursor = 0
+		for i, option := range m.typeOptionsFor("") {
+			if strings.EqualFold(option, config.Type) {
+				m.typeCursor = i
+			}
+		}
+	case 4:
 		value = config.Description
+	case 5:
+		value = config.ReviewEvery
+	case 6:
+		value = config.Run
+	case 7:
+		value = config.Alias
+	case 8:
+		value = config.Source
+	case 9:
+		value = config.Secret
+	case 10:
+		value = strings.Join(config.Keywords, ", ")
+	case 11:
+		if !config.Expires.IsZero() {
+			value = config.Expires.Format("2006-01-02")
+		}
 	}
 
+	m.textInput.SetSuggestions(m.editSuggestions())
+	m.editOriginal = value
 	m.textInput.SetValue(value)
 	m.textInput.SetCursor(len(value))
 }
 
-func (m *model) saveEdit() error {
-	if m.editRow < 0 || m.editRow >= len(m.configs) {
-		return fmt.Errorf("invalid edit row")
+// editSuggestions returns completion candidates for the current edit field,
+// or for the tag prompt, drawn from values already present in the registry.
+func (m *model) editSuggestions() []string {
+	if m.mode == ModeTagEdit || m.mode == ModeTagFilter {
+		return m.tagSuggestions()
 	}
+	switch m.editCol {
+	case 1: // Project
+		return uniqueValues(m.configs, func(c models.ConfigEntry) []string {
+			return []string{c.Project}
+		})
+	case 3: // Type
+		return m.typeOptionsFor("")
+	case 5: // Review
+		return uniqueValues(m.configs, func(c models.ConfigEntry) []string {
+			return []string{c.ReviewEvery}
+		})
+	case 6: // Run
+		return uniqueValues(m.configs, func(c models.ConfigEntry) []string {
+			return []string{c.Run}
+		})
+	}
+	return nil
+}
 
-	value := strings.TrimSpace(m.textInput.Value())
+// tagSuggestions completes the last tag of a comma-separated list with the
+// registry's tags that the list doesn't hold yet.
+func (m *model) tagSuggestions() []string {
+	value := m.textInput.Value()
+	head := value[:strings.LastIndex(value, ",")+1]
+	rest := value[len(head):]
+	head += rest[:len(rest)-len(strings.TrimLeft(rest, " "))]
+	listed := make(map[string]bool)
+	for _, tag := range models.ParseKeywords(head) {
+		listed[strings.ToLower(tag)] = true
+	}
+	var out []string
+	for _, tag := range m.allTags() {
+		if !listed[strings.ToLower(tag)] {
+			out = append(out, head+tag)
+		}
+	}
+	return out
+}
 
-	switch m.editCol {
-	case 0: // Name
-		if value == "" {
-			return fmt.Errorf("name cannot be empty")
+// typeOptions returns the selectable types filtered by the current input.
+func (m model) typeOptions() []string {
+	return m.typeOptionsFor(strings.TrimSpace(m.textInput.Value()))
+}
+
+// typeOptionsFor returns known types plus those already in the registry whose
+// name contains filter.
+func (m model) typeOptionsFor(filter string) []string {
+	all := uniqueValues(m.configs, func(c models.ConfigEntry) []string {
+		return append(models.KnownFileTypes(), c.Type)
+	})
+	if len(m.configs) == 0 {
+		all = models.KnownFileTypes()
+	}
+	filter = strings.ToLower(filter)
+	var options []string
+	for _, option := range all {
+		if strings.Contains(strings.ToLower(option), filter) {
+			options = append(options, option)
 		}
-		m.configs[m.editRow].Name = value
-	case 1: // Project
-		m.configs[m.editRow].Project = value
-	case 2: // Path
-		if value == "" {
-			return fmt.Errorf("path cannot be empty")
+	}
+	return options
+}
+
+// visibleSuggestions returns the completions matching the current input,
+// excluding the value already typed.
+func (m model) visibleSuggestions() []string {
+	if m.editCol == 3 {
+		return m.typeOptions()
+	}
+	value := strings.TrimSpace(m.textInput.Value())
+	var out []string
+	for _, s := range m.textInput.MatchedSuggestions() {
+		if !strings.EqualFold(s, value) {
+			out = append(out, s)
 		}
-		expandedPath := editor.ExpandPath(value)
+	}
+	return out
+}
 
-		if m.mode == ModeAdd || m.configs[m.editRow].Path != expandedPath {
-			if dup := storage.FindDuplicates(m.configs, expandedPath); dup != nil && !dup.Equals(&m.configs[m.editRow]) {
-				return fmt.Errorf("file already registered as '%s'", dup.Name)
+// uniqueValues collects the distinct non-empty values returned by field,
+// deduplicated case-insensitively and sorted.
+func uniqueValues(configs []models.ConfigEntry, field func(models.ConfigEntry) []string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, c := range configs {
+		for _, v := range field(c) {
+			v = strings.TrimSpace(v)
+			key := strings.ToLower(v)
+			if v == "" || seen[key] {
+				continue
 			}
+			seen[key] = true
+			values = append(values, v)
 		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return strings.ToLower(values[i]) < strings.ToLower(values[j])
+	})
+	return values
+}
 
-		m.configs[m.editRow].Path = expandedPath
+// applyEditField validates the input and stores it in the draft. Nothing is
+// written to disk until commitEdit.
+func (m *model) applyEditField() error {
+	value := strings.TrimSpace(m.textInput.Value())
+	if err := m.editFieldError(m.editCol, value); err != nil {
+		return err
+	}
+
+	before := m.draft
+	switch m.editCol {
+	case 0: // Name
+		m.draft.Name = value
+	case 1: // Project
+		m.draft.Project = value
+	case 2: // Path
+		expandedPath := m.draftPath(value)
+		m.draft.Path = expandedPath
 
 		// Auto-detect file type
-		if m.configs[m.editRow].Type == "" || m.configs[m.editRow].Type == "txt" {
-			m.configs[m.editRow].Type = models.DetectFileType(expandedPath)
+		if m.draft.Type == "" || m.draft.Type == "txt" {
+			m.draft.Type = models.DetectFileType(expandedPath)
 		}
-	case 3: // Description
-		m.configs[m.editRow].Description = value
+		// Files under a project's root join that project
+		if m.draft.Project == "" {
+			m.draft.Project = models.ProjectForPath(m.projects, expandedPath)
+		}
+	case 3: // Type
+		m.draft.Type = value
+	case 4: // Description
+		m.draft.Description = value
+	case 5: // Review
+		m.draft.ReviewEvery = value
+	case 6: // Run
+		m.draft.Run = value
+	case 7: // Alias
+		m.draft.Alias = value
+	case 8: // Source
+		m.draft.Source = value
+	case 9: // Secret
+		m.draft.Secret = value
+	case 10: // Keywords
+		m.draft.Keywords = models.ParseKeywords(value)
+	case 11: // Expires
+		// The field shows only the date; keep the exact time unless it changed
+		if value != m.editOriginal {
+			m.draft.Expires, _ = models.ParseExpiry(value, time.Now())
+		}
+	}
+	if m.draft.Name != before.Name || m.draft.Project != before.Project || m.draft.Path != before.Path ||
+		m.draft.Type != before.Type || m.draft.Description != before.Description ||
+		m.draft.ReviewEvery != before.ReviewEvery || m.draft.Run != before.Run || m.draft.Alias != before.Alias ||
+		m.draft.Source != before.Source || m.draft.Secret != before.Secret ||
+		strings.Join(m.draft.Keywords, ",") != strings.Join(before.Keywords, ",") ||
+		!m.draft.Expires.Equal(before.Expires) {
+		m.draftDirty = true
 	}
 
-	if err := m.storage.Save(m.configs); err != nil {
+	m.refreshRightViewport()
+	return nil
+}
+
+// commitEdit applies the current field and writes the whole draft to the
+// registry in a single save.
+func (m *model) commitEdit() error {
+	if err := m.applyEditField(); err != nil {
 		return err
 	}
 
+	if m.draftDirty {
+		m.draft.Shared = false // edits to a shared entry become a local override
+	}
+
+	configs := make([]models.ConfigEntry, len(m.configs))
+	copy(configs, m.configs)
+	if m.editRow < 0 {
+		var err error
+		if configs, err = registerEntry(m.configs, m.draft, time.Now()); err != nil {
+			return err
+		}
+		m.draft = configs[len(configs)-1]
+	} else if m.editRow < len(configs) {
+		if name := m.draft.Name; name != configs[m.editRow].Name {
+			m.draft.Name = configs[m.editRow].Name
+			m.draft.Rename(name)
+		}
+		configs[m.editRow] = m.draft
+	} else {
+		return fmt.Errorf("invalid edit row")
+	}
+
+	if err := m.storage.Save(configs); err != nil {
+		return err
+	}
+	m.configs = configs
 	m.cacheValid = false
 	m.buildDisplayList()
-	m.refreshRightViewport()
+	if displayIndex := m.findConfigDisplayIndex(m.draft); displayIndex != -1 {
+		m.cursor = displayIndex
+		m.ensureCursorInBounds()
+	}
 	return nil
 }
 
-func (m *model) cancelEdit() {
-	// If we were adding and canceled, remove the entry
-	if m.mode == ModeAdd && m.editRow >= 0 && m.editRow < len(m.configs) {
-		if m.configs[m.editRow].Path == "~/path/to/file" {
-			m.configs = append(m.configs[:m.editRow], m.configs[m.editRow+1:]...)
-			m.cacheValid = false
+// draftPath expands a path typed into the edit form, taking a relative
+// path as relative to the root of the draft's project.
+func (m *model) draftPath(value string) string {
+	return models.PathInProject(m.projects, m.draft.Project, editor.ExpandPath(value))
+}
+
+// editFieldError reports why value can't be stored in the given edit column.
+func (m *model) editFieldError(col int, value string) error {
+	switch col {
+	case 0: // Name
+		if value == "" {
+			return fmt.Errorf("name cannot be empty")
+		}
+	case 2: // Path
+		if value == "" {
+			return fmt.Errorf("path cannot be empty")
+		}
+		expandedPath := m.draftPath(value)
+		if dup := storage.FindDuplicates(m.configs, expandedPath); dup != nil && (m.editRow < 0 || dup != &m.configs[m.editRow]) {
+			return fmt.Errorf("file already registered as '%s'", dup.Name)
+		}
+	case 3: // Type
+		if len(m.typeOptions()) == 0 {
+			return fmt.Errorf("no known type matches '%s'", value)
+		}
+	case 5: // Review
+		if value != "" {
+			if _, err := models.ParseInterval(value); err != nil {
+				return err
+			}
+		}
+	case 7: // Alias
+		if strings.ContainsAny(value, " \t") {
+			return fmt.Errorf("alias cannot contain spaces")
+		}
+		if dup := models.FindByAlias(m.configs, value); dup != nil && (m.editRow < 0 || dup != &m.configs[m.editRow]) {
+			return fmt.Errorf("alias already used by '%s'", dup.Name)
+		}
+	case 9: // Secret
+		if value != "" {
+			if _, _, err := parseSecretRef(value); err != nil {
+				return err
+			}
+		}
+	case 11: // Expires
+		if _, err := models.ParseExpiry(value, time.Now()); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
+// editFieldHint validates the value being typed and returns a hint for the
+// user. isError is false for warnings that don't block saving.
+func (m model) editFieldHint() (hint string, isError bool) {
+	if m.mode != ModeEdit && m.mode != ModeAdd {
+		return "", false
+	}
+	value := strings.TrimSpace(m.textInput.Value())
+	if err := m.editFieldError(m.editCol, value); err != nil {
+		return err.Error(), true
+	}
+	if m.editCol == 2 && !editor.FileExists(m.draftPath(value)) {
+		return "path does not exist yet", false
+	}
+	return "", false
+}
+
+// cancelEdit leaves edit mode, discarding the draft.
+func (m *model) cancelEdit() {
 	m.mode = ModeNormal
 	m.editRow = -1
 	m.editCol = -1
+	m.draft = models.ConfigEntry{}
+	m.draftDirty = false
 	m.textInput.Blur()
 	m.textInput.SetValue("")
 	m.buildDisplayList()
@@ -268,6 +621,7 @@ func (m *model) saveFileEdit() error {
 	m.fileEditArea.Blur()
 	m.fileEditPath = ""
 	m.fileEditLabel = ""
+	m.fileEditOrig = ""
 	m.refreshRightViewport()
 	return nil
 }
@@ -277,9 +631,254 @@ func (m *model) cancelFileEdit() {
 	m.fileEditArea.Blur()
 	m.fileEditPath = ""
 	m.fileEditLabel = ""
+	m.fileEditOrig = ""
 	m.refreshRightViewport()
 }
 
+// openConfigs launches the editor once for all targets; the opens are
+// recorded when the launch succeeds. With remember set, editorCmd becomes
+// each entry's preferred editor. Targets marked confirm_open are asked
+// about first.
+func (m *model) openConfigs(targets []models.ConfigEntry, editorCmd string, remember bool) tea.Cmd {
+	if len(targets) == 0 {
+		return nil
+	}
+	if needsConfirmation(targets, m.settings) {
+		return m.askOpen(pendingOpen{targets: targets, editorCmd: editorCmd, remember: remember})
+	}
+	return m.launchConfigs(targets, editorCmd, remember)
+}
+
+func (m *model) launchConfigs(targets []models.ConfigEntry, editorCmd string, remember bool) tea.Cmd {
+	opts, cmd := m.readOnlyOptions(targets, editorCmd)
+	if cmd != nil {
+		return cmd
+	}
+
+	var paths []string
+	for _, config := range targets {
+		paths = append(paths, config.Path)
+	}
+	if remember {
+		m.rememberEditor(targets, editorCmd)
+	}
+
+	label := targets[0].Name
+	if len(targets) > 1 {
+		label = fmt.Sprintf("%d files", len(targets))
+	}
+	return editor.OpenPaths(paths, editorCmd, label, opts)
+}
+
+// readOnlyOptions returns the options to open targets with: in view mode when
+// one of them is read-only. An editor without a view mode can't be trusted
+// with a read-only file, so a lone one is paged instead and several are
+// refused; the returned command then replaces the launch.
+func (m *model) readOnlyOptions(targets []models.ConfigEntry, editorCmd string) (editor.Options, tea.Cmd) {
+	opts := m.editorOptions(editorCmd)
+	for _, config := range targets {
+		if !config.ReadOnly {
+			continue
+		}
+		if editor.SupportsReadOnly(editorCmd) {
+			opts.ReadOnly = true
+			return opts, nil
+		}
+		if len(targets) == 1 {
+			return opts, editor.Page(config.Path, config.Name, opts)
+		}
+		return opts, showWarning(fmt.Sprintf("%s is read-only and %s has no read-only mode; open it on its own to page it", config.Name, editorCmd))
+	}
+	return opts, nil
+}
+
+// openConfigAt opens a single entry in its editor with the cursor on line.
+func (m *model) openConfigAt(config models.ConfigEntry, line int) tea.Cmd {
+	if needsConfirmation([]models.ConfigEntry{config}, m.settings) {
+		return m.askOpen(pendingOpen{targets: []models.ConfigEntry{config}, editorCmd: m.editorFor(config), line: line})
+	}
+	return m.launchConfigAt(config, m.editorFor(config), line)
+}
+
+func (m *model) launchConfigAt(config models.ConfigEntry, editorCmd string, line int) tea.Cmd {
+	opts, cmd := m.readOnlyOptions([]models.ConfigEntry{config}, editorCmd)
+	if cmd != nil {
+		return cmd
+	}
+	opts.Line = line
+	return editor.OpenPathWith(config.Path, editorCmd, config.Name, opts)
+}
+
+// usageFlushDelay is how long recorded opens wait before being saved, so
+// launching the editor never waits on a registry write and opens in quick
+// succession are saved together.
+const usageFlushDelay = 2 * time.Second
+
+// flushUsageMsg asks the model to save opens recorded since the last save.
+type flushUsageMsg struct{}
+
+func flushUsageLater() tea.Cmd {
+	return tea.Tick(usageFlushDelay, func(time.Time) tea.Msg { return flushUsageMsg{} })
+}
+
+// flushUsage saves the registry if opens were recorded since it was last
+// saved. It also runs when the program exits.
+func (m *model) flushUsage() error {
+	if !m.usageDirty {
+		return nil
+	}
+	if err := m.storage.Save(m.configs); err != nil {
+		return err
+	}
+	m.usageDirty = false
+	return nil
+}
+
+// rememberEditor makes editorCmd the preferred editor of the registry
+// entries matching targets. The registry is saved later by flushUsage.
+func (m *model) rememberEditor(targets []models.ConfigEntry, editorCmd string) {
+	for _, config := range targets {
+		for i := range m.configs {
+			if m.configs[i].Equals(&config) {
+				m.configs[i].Editor = editorCmd
+				break
+			}
+		}
+	}
+	m.usageDirty = true
+}
+
+// recordOpens bumps the usage counters of the registry entries for paths
+// once the editor has started on them, so a failed launch leaves recency
+// untouched. The registry is saved later by flushUsage.
+func (m *model) recordOpens(paths []string) {
+	now := time.Now()
+	m.storage.RecordActivity(now)
+	for _, path := range paths {
+		for i := range m.configs {
+			if m.configs[i].Path == path {
+				m.configs[i].RecordOpen(now)
+			}
+		}
+	}
+	m.usageDirty = true
+	m.cacheValid = false
+	m.buildDisplayList()
+	m.refreshRightViewport()
+}
+
+// formatTime renders a timestamp in the date_format setting. dateOnly drops
+// the time of day where the format allows it.
+func (m model) formatTime(t time.Time, dateOnly bool) string {
+	return ui.FormatTime(t, time.Now(), m.settings.DateFormat, dateOnly)
+}
+
+// overdueCount counts entries whose review is due.
+func (m model) overdueCount() int {
+	now := time.Now()
+	count := 0
+	for i := range m.configs {
+		if m.configs[i].ReviewOverdue(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// recentConfigs returns up to limit entries that have been opened, most
+// recent first.
+func (m model) recentConfigs(limit int) []models.ConfigEntry {
+	var recent []models.ConfigEntry
+	for _, config := range storage.SortByRecentlyOpened(m.configs) {
+		if config.LastOpened.IsZero() || len(recent) == limit {
+			break
+		}
+		if !m.inWorkspace(config) || !m.visible(config) {
+			continue
+		}
+		recent = append(recent, config)
+	}
+	return recent
+}
+
+// markedConfigs returns the marked entries in display order.
+func (m model) markedConfigs() []models.ConfigEntry {
+	var marked []models.ConfigEntry
+	for _, display := range m.displayConfigs {
+		if !display.isHeader && display.config != nil && m.marked[display.config.Path] {
+			marked = append(marked, *display.config)
+		}
+	}
+	return marked
+}
+
+// editorOptions returns the options for launching editorCmd derived from the
+// user's settings.
+func (m model) editorOptions(editorCmd string) editor.Options {
+	return editor.Options{Wait: m.settings.EditorWait, DryRun: m.dryRun, Kind: editorKind(m.settings, editorCmd)}
+}
+
+// editorKind is the editor_kinds setting for editorCmd, matched on the whole
+// command or else on its program, e.g. "code" for "code --new-window".
+func editorKind(prefs settings.Settings, editorCmd string) string {
+	if kind, ok := prefs.EditorKinds[editorCmd]; ok {
+		return kind
+	}
+	fields := strings.Fields(editorCmd)
+	if len(fields) == 0 {
+		return ""
+	}
+	for name, kind := range prefs.EditorKinds {
+		if named := strings.Fields(name); len(named) > 0 && named[0] == fields[0] {
+			return kind
+		}
+	}
+	return ""
+}
+
+// editorFor returns the entry's remembered editor, or else the one set for
+// its type, when it is still installed, falling back to the default editor.
+// An editor may carry arguments, as in "code --new-window"; only its first
+// word has to be installed.
+func (m model) editorFor(config models.ConfigEntry) string {
+	return preferredEditor(config, m.settings, m.editor)
+}
+
+func preferredEditor(config models.ConfigEntry, prefs settings.Settings, fallback string) string {
+	for _, editorCmd := range []string{config.Editor, prefs.TypeEditor(config.Type)} {
+		fields := strings.Fields(editorCmd)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(fields[0]); err == nil {
+			return editorCmd
+		}
+	}
+	return fallback
+}
+
+// defaultEditor is the editor set in settings, or else $VISUAL, $EDITOR or
+// VS Code.
+func defaultEditor(store *storage.Storage, prefs settings.Settings) string {
+	if prefs.Editor != "" {
+		return prefs.Editor
+	}
+	return store.GetEditor()
+}
+
+// knownEditors lists the default editor and every editor remembered on an entry.
+func (m model) knownEditors() []string {
+	editors := uniqueValues(m.configs, func(c models.ConfigEntry) []string {
+		return []string{c.Editor}
+	})
+	for _, e := range editors {
+		if e == m.editor {
+			return editors
+		}
+	}
+	return append([]string{m.editor}, editors...)
+}
+
 func (m *model) refreshRightViewport() {
 	content := m.buildRightPanelContent()
 	m.rightViewport.SetContent(content)
@@ -287,7 +886,13 @@ func (m *model) refreshRightViewport() {
 }
 
 func (m *model) buildRightPanelContent() string {
+	if m.mode == ModeProjectEdit {
+		return m.projectPanelContent()
+	}
 	config := m.getConfigByDisplayIndex(m.cursor)
+	if m.mode == ModeEdit || m.mode == ModeAdd {
+		config = &m.draft
+	}
 	if config == nil {
 		return "No file selected"
 	}
@@ -299,16 +904,95 @@ func (m *model) buildRightPanelContent() string {
 
 	var lines []string
 	lines = append(lines, "Name: "+config.Name)
-	lines = append(lines, "Project: "+project)
+	if config.Alias != "" {
+		lines = append(lines, "Alias: "+config.Alias)
+	}
+	if len(config.PreviousNames) > 0 {
+		lines = append(lines, "Formerly: "+strings.Join(config.PreviousNames, ", "))
+	}
+	lines = append(lines, "Project: "+lipgloss.NewStyle().Foreground(m.projectColor(config.Project)).Render(project))
 	lines = append(lines, "Type: "+config.Type)
+	if len(config.Tags) > 0 {
+		lines = append(lines, "Tags: "+formatTags(config.Tags))
+	}
 	lines = append(lines, "Path: "+config.Path)
 	if config.Description != "" {
 		lines = append(lines, "Desc: "+config.Description)
 	}
+	if config.Editor != "" {
+		lines = append(lines, "Editor: "+config.Editor)
+	}
+	if config.Run != "" {
+		lines = append(lines, "Run: "+config.Run)
+	}
+	if config.Source != "" {
+		lines = append(lines, "Source: "+config.Source)
+	}
+	if config.ReadOnly {
+		lines = append(lines, "Read-only: opens in view mode"+lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render("  (l to unlock)"))
+	}
+	if config.ConfirmOpen {
+		lines = append(lines, "Opening: asks first"+lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render("  (! to toggle)"))
+	}
+	if config.Secret != "" {
+		lines = append(lines, "Secret: "+config.Secret+lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render("  (V to view)"))
+	}
+	if config.Checksum != "" {
+		lines = append(lines, m.checksumDetail(*config))
+	}
+	if autoChecked(*config) {
+		lines = append(lines, m.checkDetail(*config))
+	}
+	if config.Archived {
+		lines = append(lines, "Archived: yes")
+	}
+	if config.Shared {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render("⇅ Shared from the team registry; edits are saved as a local override"))
+	}
+	changedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	if m.changedSinceStart(*config) {
+		lines = append(lines, changedStyle.Render("✱ Changed while zap was open"))
+	}
+	if !config.LastOpened.IsZero() {
+		lines = append(lines, fmt.Sprintf("Opened: %s · %d times", m.formatTime(config.LastOpened, false), config.OpenCount))
+	}
+	if !config.Added.IsZero() {
+		lines = append(lines, "Added: "+m.formatTime(config.Added, true))
+	}
+	if info, err := os.Stat(editor.ExpandPath(config.Path)); err == nil && !config.LastOpened.IsZero() && info.ModTime().After(config.LastOpened) {
+		lines = append(lines, changedStyle.Render("Modified since last open ("+m.formatTime(info.ModTime(), false)+")"))
+	}
+	if due, ok := config.ReviewDue(); ok {
+		review := fmt.Sprintf("Review: every %s · due %s", config.ReviewEvery, m.formatTime(due, true))
+		if config.ReviewOverdue(time.Now()) {
+			review += " " + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("(overdue)")
+		}
+		lines = append(lines, review)
+	}
+	if !config.Expires.IsZero() {
+		expires := "Expires: " + m.formatTime(config.Expires, true)
+		if config.Expired(time.Now()) {
+			expires += " " + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("(expired)")
+		}
+		lines = append(lines, expires)
+	}
 
-	lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true).Render("Preview:"))
+	if m.hidePreview {
+		return strings.Join(lines, "\n")
+	}
+	previewTitle := "Preview:"
+	if isKind(*config, "env") {
+		previewTitle = "Variables (values hidden):"
+	}
+	lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true).Render(previewTitle))
 
-	preview, err := buildPreviewLines(config.Path, 200)
+	var preview []string
+	var err error
+	if isKind(*config, "env") {
+		preview, err = envPreviewLines(config.Path)
+	} else {
+		preview, err = buildPreviewLines(config.Path, m.settings.LargeFileBytes(), previewType(*config))
+	}
 	if err != nil {
 		lines = append(lines, "  unavailable: "+err.Error())
 	} else {
@@ -320,7 +1004,21 @@ func (m *model) buildRightPanelContent() string {
 	return strings.Join(lines, "\n")
 }
 
-func buildPreviewLines(path string, maxBytes int64) ([]string, error) {
+// previewDumpLen is how many bytes of a binary file the preview dumps
+const previewDumpLen = 256
+
+// previewType is the type config's preview is highlighted as: the
+// registered type, or the one detected from the file name for txt entries.
+func previewType(config models.ConfigEntry) string {
+	if config.Type == "" || config.Type == "txt" {
+		return models.DetectFileType(config.Path)
+	}
+	return config.Type
+}
+
+// buildPreviewLines returns the first lines of path, reading no more than
+// limit bytes of it, highlighted for fileType.
+func buildPreviewLines(path string, limit int64, fileType string) ([]string, error) {
 	expanded := editor.ExpandPath(path)
 	info, err := os.Stat(expanded)
 	if err != nil {
@@ -329,29 +1027,153 @@ func buildPreviewLines(path string, maxBytes int64) ([]string, error) {
 	if info.IsDir() {
 		return []string{"directory: " + filepath.Base(expanded)}, nil
 	}
-	if info.Size() > maxBytes*1024 {
-		return []string{"preview skipped (file > 200KB)"}, nil
-	}
 
-	data, err := os.ReadFile(expanded)
+	data, truncated, err := readLimited(expanded, limit)
 	if err != nil {
 		return nil, err
 	}
-	if bytes.IndexByte(data, 0) >= 0 {
-		return []string{"binary file preview skipped"}, nil
+	if editor.IsBinary(data) {
+		// Show a hex dump rather than writing control characters to the terminal
+		dump := data[:min(len(data), previewDumpLen)]
+		lines := []string{fmt.Sprintf("binary file, %s; first %d bytes:", formatSize(info.Size()), len(dump))}
+		return append(lines, strings.Split(strings.TrimSuffix(hex.Dump(dump), "\n"), "\n")...), nil
 	}
 
 	text := strings.ReplaceAll(string(data), "\r\n", "\n")
 	rawLines := strings.Split(text, "\n")
-	if len(rawLines) == 0 {
+	if truncated {
+		// The last line was cut off by the limit
+		rawLines = rawLines[:len(rawLines)-1]
+	}
+	if len(rawLines) == 0 && !truncated {
 		return []string{"(empty file)"}, nil
 	}
 
-	if len(rawLines) > 400 {
+	more := len(rawLines) > 400
+	if more {
 		rawLines = rawLines[:400]
-		rawLines = append(rawLines, "...")
 	}
-	return rawLines, nil
+	lines := ui.Highlight(fileType, path, rawLines)
+	if more {
+		lines = append(lines, "...")
+	}
+	if truncated {
+		note := fmt.Sprintf("(large file: showing the first %s of %s)", formatSize(limit), formatSize(info.Size()))
+		lines = append([]string{note, ""}, lines...)
+	}
+	return lines, nil
+}
+
+// readLimited reads up to limit bytes of path, reporting whether the file
+// holds more.
+func readLimited(path string, limit int64) (data []byte, truncated bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	data, err = io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(data)) > limit {
+		return data[:limit], true, nil
+	}
+	return data, false, nil
+}
+
+// checksumDetail compares a file with its pinned checksum for the details
+// pane. Files over the large_file_kb limit are left to zap verify.
+func (m model) checksumDetail(config models.ConfigEntry) string {
+	label := "Checksum: " + shortChecksum(config.Checksum)
+	path := editor.ExpandPath(config.Path)
+	if err := m.checkFileSize(path); err != nil {
+		return label + " (not checked here, run zap verify)"
+	}
+	sum, err := fileChecksum(path)
+	switch {
+	case err != nil:
+		return label
+	case sum != config.Checksum:
+		return label + " " + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("✗ content changed")
+	default:
+		return label + " " + lipgloss.NewStyle().Foreground(lipgloss.Color("82")).Render("✓ matches")
+	}
+}
+
+// checkFileSize refuses files over the large_file_kb setting for actions
+// that read a whole file at once.
+func (m model) checkFileSize(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if limit := m.settings.LargeFileBytes(); info.Size() > limit {
+		return fmt.Errorf("%s is %s, over the %s large_file_kb limit", filepath.Base(path), formatSize(info.Size()), formatSize(limit))
+	}
+	return nil
+}
+
+// sortCustom is the sort mode that applies the sort setting's expression
+const sortCustom = 4
+
+// sortColumn is the sort mode set with < and >, ordering by one of the
+// sortColumns. It sits outside the S cycle.
+const sortColumn = 5
+
+// sortColumns are the entry fields < and > step through, each starting in
+// the direction that puts the most useful entries first.
+var sortColumns = []struct {
+	label string
+	key   storage.SortKey
+}{
+	{"name", storage.SortKey{Field: "name"}},
+	{"project", storage.SortKey{Field: "project"}},
+	{"type", storage.SortKey{Field: "type"}},
+	{"path", storage.SortKey{Field: "path"}},
+	{"last opened", storage.SortKey{Field: "last_opened", Desc: true}},
+	{"opens", storage.SortKey{Field: "open_count", Desc: true}},
+	{"added", storage.SortKey{Field: "added", Desc: true}},
+	{"size", storage.SortKey{Field: "size", Desc: true}},
+}
+
+// columnSortKey returns the key the column sort currently applies
+func (m model) columnSortKey() storage.SortKey {
+	key := sortColumns[m.sortCol].key
+	if m.sortReverse {
+		key.Desc = !key.Desc
+	}
+	return key
+}
+
+// columnSortName describes the column sort for the header and status line
+func (m model) columnSortName() string {
+	arrow := "↑"
+	if m.columnSortKey().Desc {
+		arrow = "↓"
+	}
+	return sortColumns[m.sortCol].label + " " + arrow
+}
+
+// sortModeCount is the number of modes S cycles through; the custom mode
+// only exists when a sort expression is configured.
+func (m model) sortModeCount() int {
+	if m.sortSpec != nil {
+		return sortCustom + 1
+	}
+	return sortCustom
+}
+
+// groupsByProject reports whether the current sort keeps each project's
+// entries together, so the list can show project headers.
+func (m model) groupsByProject() bool {
+	switch m.sortMode {
+	case sortCustom:
+		return len(m.sortSpec) > 0 && m.sortSpec[0].Field == "project"
+	case sortColumn:
+		return sortColumns[m.sortCol].key.Field == "project"
+	}
+	return m.sortMode == 0
 }
 
 func (m *model) getSortedConfigs() []models.ConfigEntry {
@@ -362,15 +1184,19 @@ func (m *model) getSortedConfigs() []models.ConfigEntry {
 	var sorted []models.ConfigEntry
 	switch m.sortMode {
 	case 0: // Project
-		sorted = storage.SortConfigs(m.configs)
+		sorted = storage.SortConfigs(m.configs, m.collation)
 	case 1: // Recent
 		sorted = storage.SortByRecentlyOpened(m.configs)
 	case 2: // Name
-		sorted = storage.SortByName(m.configs)
+		sorted = storage.SortByName(m.configs, m.collation)
 	case 3: // Path
-		sorted = storage.SortByPath(m.configs)
+		sorted = storage.SortByPath(m.configs, m.collation)
+	case sortCustom:
+		sorted = storage.SortBySpec(m.configs, m.sortSpec, m.collation)
+	case sortColumn:
+		sorted = storage.SortBySpec(m.configs, storage.SortSpec{m.columnSortKey()}, m.collation)
 	default:
-		sorted = storage.SortConfigs(m.configs)
+		sorted = storage.SortConfigs(m.configs, m.collation)
 	}
 
 	m.sortedCache = sorted
@@ -381,68 +1207,336 @@ func (m *model) getSortedConfigs() []models.ConfigEntry {
 func (m *model) getFilteredConfigs() []models.ConfigEntry {
 	sorted := m.getSortedConfigs()
 
-	if m.searchQuery == "" {
+	if m.searchQuery == "" && !m.overdueOnly && !m.unopenedOnly && m.workspace == "" && len(m.tagFilter) == 0 && !m.hidesAny() {
 		return sorted
 	}
 
-	query := strings.ToLower(m.searchQuery)
+	opts := m.searchOptions()
+	query := opts.fold(m.searchQuery)
+	groups := parseQuery(m.searchQuery, opts.fold)
+	now := time.Now()
 	var filtered []models.ConfigEntry
 
 	for _, config := range sorted {
-		if m.matchesSearch(config, query) {
+		if m.overdueOnly && !config.ReviewOverdue(now) {
+			continue
+		}
+		if m.unopenedOnly && !config.LastOpened.IsZero() {
+			continue
+		}
+		if len(m.tagFilter) > 0 && !hasAnyTag(config, m.tagFilter) {
+			continue
+		}
+		if !m.inWorkspace(config) || !m.visible(config) {
+			continue
+		}
+		if matchesSearch(config, groups, opts) {
 			filtered = append(filtered, config)
 		}
 	}
 
+	if m.rankedSearch() {
+		// Rank by the best match of any term the entry has to contain
+		var wanted []string
+		for _, group := range groups {
+			for _, term := range group {
+				if !term.negate {
+					wanted = append(wanted, term.text)
+				}
+			}
+		}
+		if len(wanted) == 0 {
+			wanted = []string{""}
+		}
+		scores := make(map[string]float64, len(filtered))
+		for _, config := range filtered {
+			best := math.Inf(-1)
+			for _, term := range wanted {
+				best = max(best, searchRank(config, term, now, opts))
+			}
+			scores[config.Path] = best
+		}
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return scores[filtered[i].Path] > scores[filtered[j].Path]
+		})
+	} else if opts.basename && query != "" {
+		// Files named exactly what was typed come first in plain search too.
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return opts.basenameIs(filtered[i], query) && !opts.basenameIs(filtered[j], query)
+		})
+	}
+
 	return filtered
 }
 
+// hidesAny reports whether a visibility toggle may hide entries.
+func (m model) hidesAny() bool {
+	if m.hideMissing {
+		return true
+	}
+	if m.showArchived {
+		return false
+	}
+	for i := range m.configs {
+		if m.configs[i].Archived {
+			return true
+		}
+	}
+	return false
+}
+
+// visible applies the archived and missing-file toggles.
+func (m model) visible(config models.ConfigEntry) bool {
+	if config.Archived && !m.showArchived {
+		return false
+	}
+	return !m.hideMissing || m.fileExists(config.Path)
+}
+
+// fileExists reports whether path exists, remembering the answer so list
+// rebuilds don't stat every entry. Watcher events and r clear it.
+func (m model) fileExists(path string) bool {
+	key := filepath.Clean(editor.ExpandPath(path))
+	if exists, ok := m.exists[key]; ok {
+		return exists
+	}
+	exists := editor.FileExists(path)
+	if m.exists != nil {
+		m.exists[key] = exists
+	}
+	return exists
+}
+
+// toggleArchived archives the marked files, or the selected one, in a single
+// save. If every target is already archived they are restored instead.
+func (m *model) toggleArchived() tea.Cmd {
+	targets := m.markedConfigs()
+	if len(targets) == 0 {
+		if config := m.getConfigByDisplayIndex(m.cursor); config != nil {
+			targets = []models.ConfigEntry{*config}
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	archive := false
+	for _, config := range targets {
+		if !config.Archived {
+			archive = true
+		}
+	}
+
+	configs := make([]models.ConfigEntry, len(m.configs))
+	copy(configs, m.configs)
+	for _, target := range targets {
+		for i := range configs {
+			if configs[i].Equals(&target) {
+				configs[i].Archived = archive
+				configs[i].Shared = false
+			}
+		}
+	}
+	if err := m.storage.Save(configs); err != nil {
+		return showError(fmt.Sprintf("Failed to save: %v", err))
+	}
+	m.configs = configs
+	m.marked = nil
+	m.cacheValid = false
+	m.buildDisplayList()
+	m.refreshRightViewport()
+
+	verb := "Archived"
+	if !archive {
+		verb = "Restored"
+	}
+	if len(targets) == 1 {
+		return showStatus(fmt.Sprintf("%s %s", verb, targets[0].Name))
+	}
+	return showStatus(fmt.Sprintf("%s %d files", verb, len(targets)))
+}
+
+// inWorkspace reports whether config belongs to the active workspace.
+func (m model) inWorkspace(config models.ConfigEntry) bool {
+	if m.workspace == "" {
+		return true
+	}
+	ws, ok := m.settings.Workspaces[m.workspace]
+	return !ok || ws.Includes(config.Project, config.Tags)
+}
+
+// cycleWorkspace switches to the next (or previous) workspace, passing
+// through "all files" between the last and first.
+func (m *model) cycleWorkspace(step int) {
+	names := append([]string{""}, m.settings.WorkspaceNames()...)
+	current := 0
+	for i, name := range names {
+		if name == m.workspace {
+			current = i
+		}
+	}
+	m.workspace = names[(current+step+len(names))%len(names)]
+	m.buildDisplayList()
+	m.refreshRightViewport()
+}
+
+// rankedSearch reports whether results are ordered by relevance rather than
+// the sort mode, which is the case for fuzzy searches.
+func (m model) rankedSearch() bool {
+	return m.fuzzyMode && m.searchQuery != ""
+}
+
+// searchRank blends how well query fuzzy-matches an entry with how often and
+// how recently the entry is opened, so daily-used files float above
+// equally good matches that are never touched. With opts.basename the
+// file's base name counts as much as the entry name and an exact base name
+// match outranks everything but an alias.
+func searchRank(config models.ConfigEntry, query string, now time.Time, opts searchOptions) float64 {
+	fold := opts.fold
+	if config.Alias != "" && fold(config.Alias) == query {
+		return math.Inf(1) // an alias names exactly one entry
+	}
+	match := 2 * fuzzyScore(query, fold(config.Name))
+	base := fuzzyScore(query, fold(filepath.Base(config.Path)))
+	if opts.basename {
+		base *= 2
+		if opts.basenameIs(config, query) {
+			base += 1000
+		}
+	}
+	match = max(match, base)
+	for _, field := range append([]string{config.Project, config.Description}, config.SearchTerms()...) {
+		if score := fuzzyScore(query, fold(field)); score > match {
+			match = score
+		}
+	}
+
+	usage := 2 * math.Log2(1+float64(config.OpenCount))
+	if !config.LastOpened.IsZero() {
+		switch age := now.Sub(config.LastOpened); {
+		case age < 24*time.Hour:
+			usage += 6
+		case age < 7*24*time.Hour:
+			usage += 4
+		case age < 30*24*time.Hour:
+			usage += 2
+		}
+	}
+	return float64(match) + usage
+}
+
 func (m *model) getFilteredConfigsCount() int {
 	return len(m.getFilteredConfigs())
 }
 
-func (m *model) matchesSearch(config models.ConfigEntry, query string) bool {
-	if m.fuzzyMode {
-		return fuzzyMatch(query, strings.ToLower(config.Name)) ||
-			fuzzyMatch(query, strings.ToLower(config.Project)) ||
-			fuzzyMatch(query, strings.ToLower(config.Path)) ||
-			fuzzyMatch(query, strings.ToLower(config.Description))
+// fuzzyScore rates a fuzzy match of pattern in text, or returns 0 when it
+// doesn't match. Consecutive characters and matches at the start of a word
+// score higher.
+func fuzzyScore(pattern, text string) int {
+	if pattern == "" || !fuzzyMatch(pattern, text) {
+		return 0
 	}
 
-	// Normal substring search
-	return strings.Contains(strings.ToLower(config.Name), query) ||
-		strings.Contains(strings.ToLower(config.Project), query) ||
-		strings.Contains(strings.ToLower(config.Type), query) ||
-		strings.Contains(strings.ToLower(config.Path), query) ||
-		strings.Contains(strings.ToLower(config.Description), query)
+	p := []rune(pattern)
+	score, patternIdx := 0, 0
+	prevMatched := false
+	prev := rune(0)
+	for textIdx, r := range []rune(text) {
+		if patternIdx == len(p) {
+			break
+		}
+		if r != p[patternIdx] {
+			prevMatched = false
+			prev = r
+			continue
+		}
+		score++
+		if prevMatched {
+			score += 2
+		}
+		if textIdx == 0 || strings.ContainsRune("/._- ", prev) {
+			score += 3
+		}
+		prevMatched = true
+		patternIdx++
+		prev = r
+	}
+	return score
 }
 
 func fuzzyMatch(pattern, text string) bool {
 	if pattern == "" {
 		return true
 	}
-	if text == "" {
-		return false
-	}
 
+	p := []rune(pattern)
 	patternIdx := 0
-	textIdx := 0
-
-	for textIdx < len(text) && patternIdx < len(pattern) {
-		if text[textIdx] == pattern[patternIdx] {
+	for _, r := range text {
+		if r == p[patternIdx] {
 			patternIdx++
+			if patternIdx == len(p) {
+				return true
+			}
 		}
-		textIdx++
 	}
+	return false
+}
+
+// watchPaths returns the expanded paths of all registered files.
+func (m model) watchPaths() []string {
+	paths := make([]string, len(m.configs))
+	for i, config := range m.configs {
+		paths[i] = editor.ExpandPath(config.Path)
+	}
+	return paths
+}
 
-	return patternIdx == len(pattern)
+// changedSinceStart reports whether the watcher saw config's file change.
+func (m model) changedSinceStart(config models.ConfigEntry) bool {
+	return m.changed[filepath.Clean(editor.ExpandPath(config.Path))]
+}
+
+// syncWatcher points the watcher at the registry's files when they differ
+// from the last sync, so search and filter changes don't touch it.
+func (m *model) syncWatcher() {
+	if m.watcher == nil {
+		return
+	}
+	paths := m.watchPaths()
+	if slices.Equal(paths, m.watched) {
+		return
+	}
+	m.watcher.Sync(paths)
+	m.watched = paths
 }
 
 // buildDisplayList creates a flattened list of display items (headers + configs)
 func (m *model) buildDisplayList() {
+	m.syncWatcher()
+	if m.exists == nil {
+		m.exists = make(map[string]bool)
+	}
+
 	filteredConfigs := m.getFilteredConfigs()
 	m.displayConfigs = []displayConfig{}
 
+	// Project headers show how many files they hold and how many are missing
+	grouped := m.groupsByProject() && !m.rankedSearch()
+	counts := make(map[string]int)
+	missing := make(map[string]int)
+	if grouped {
+		for _, config := range filteredConfigs {
+			project := config.Project
+			if project == "" {
+				project = "General"
+			}
+			counts[project]++
+			if !m.fileExists(config.Path) {
+				missing[project]++
+			}
+		}
+	}
+
 	var lastProject string
 
 	for i, config := range filteredConfigs {
@@ -451,11 +1545,16 @@ func (m *model) buildDisplayList() {
 			displayProject = "General"
 		}
 
-		// Add project header only when sorting by project (mode 0)
-		if m.sortMode == 0 && displayProject != lastProject {
+		// Add project header only when sorting by project first
+		if grouped && displayProject != lastProject {
+			summary := fmt.Sprint(counts[displayProject])
+			if n := missing[displayProject]; n > 0 {
+				summary += fmt.Sprintf(", %d missing", n)
+			}
 			m.displayConfigs = append(m.displayConfigs, displayConfig{
 				isHeader:    true,
-				headerText:  fmt.Sprintf("📂 %s", displayProject),
+				headerText:  fmt.Sprintf("📂 %s (%s)", displayProject, summary),
+				project:     config.Project,
 				configIndex: -1,
 			})
 			lastProject = displayProject
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/importer"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// importState holds the checklist shown before scanned files are registered.
+type importState struct {
+	title      string
+	root       string // scanned directory; paths are shown relative to it
+	candidates []importer.Candidate
+	selected   []bool
+	cursor     int // index into visible()
+	filter     string
+	filtering  bool
+}
+
+// visible returns the candidate indexes matching the filter.
+func (s importState) visible() []int {
+	query := strings.ToLower(s.filter)
+	var out []int
+	for i, c := range s.candidates {
+		if query == "" || strings.Contains(strings.ToLower(c.Path), query) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (s importState) selectedCount() int {
+	count := 0
+	for _, sel := range s.selected {
+		if sel {
+			count++
+		}
+	}
+	return count
+}
+
+// startImportPrompt asks for the directory to scan.
+func (m *model) startImportPrompt() tea.Cmd {
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "~/"
+	}
+	m.mode = ModeImportDir
+	m.textInput.SetSuggestions(nil)
+	m.textInput.SetValue(dir)
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
+	return nil
+}
+
+func (m model) updateImportDir(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeNormal
+		m.textInput.Blur()
+		m.textInput.SetValue("")
+		return m, nil
+	case "enter":
+		root := editor.ExpandPath(strings.TrimSpace(m.textInput.Value()))
+		m.textInput.Blur()
+		m.textInput.SetValue("")
+		if info, err := os.Stat(root); err == nil && info.Mode().IsRegular() {
+			return m, m.startBookmarkImport(root)
+		}
+
+		candidates, err := importer.Scan(root, importer.DefaultOptions(m.settings.IgnorePatterns()), m.registeredPaths())
+		if err != nil {
+			m.mode = ModeNormal
+			return m, showError(fmt.Sprintf("Scan failed: %v", err))
+		}
+		if len(candidates) == 0 {
+			m.mode = ModeNormal
+			return m, showStatus("No new files found in " + root)
+		}
+		m.startImport(importState{title: "Import from " + root, root: root, candidates: candidates}, true)
+		return m, nil
+	case "ctrl+r":
+		m.textInput.Blur()
+		m.textInput.SetValue("")
+		return m, m.startRecentImport()
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// registeredPaths returns the expanded paths of every entry, for leaving
+// them out of imports.
+func (m model) registeredPaths() map[string]bool {
+	paths := make(map[string]bool, len(m.configs))
+	for _, config := range m.configs {
+		paths[editor.ExpandPath(config.Path)] = true
+	}
+	return paths
+}
+
+// startImport shows the checklist for imp's candidates, suggesting a project
+// for files under a project root.
+func (m *model) startImport(imp importState, selectAll bool) {
+	for i := range imp.candidates {
+		if project := models.ProjectForPath(m.projects, imp.candidates[i].Path); project != "" {
+			imp.candidates[i].Project = project
+		}
+	}
+	imp.selected = make([]bool, len(imp.candidates))
+	for i := range imp.selected {
+		imp.selected[i] = selectAll
+	}
+	m.imp = imp
+	m.mode = ModeImport
+}
+
+// startRecentImport suggests files from VS Code's recently opened list and
+// from editor commands in the shell history. Nothing is preselected since
+// these are often one-off edits.
+func (m *model) startRecentImport() tea.Cmd {
+	var paths []string
+	if configDir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, importer.VSCodeRecent(configDir)...)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		editors := append([]string{"vim", "nvim", "vi", "nano", "emacs", "micro", "hx", "code", "subl", "sudoedit"}, m.knownEditors()...)
+		paths = append(paths, importer.ShellHistory(home, editors)...)
+	}
+
+	candidates := importer.Recent(paths, m.registeredPaths())
+	if len(candidates) == 0 {
+		m.mode = ModeNormal
+		return showStatus("No new files found in VS Code or shell history")
+	}
+	m.startImport(importState{title: "Import recently edited files (VS Code, shell history)", candidates: candidates}, false)
+	return nil
+}
+
+// startBookmarkImport suggests the files named in a bookmarks export or a
+// plain list of paths.
+func (m *model) startBookmarkImport(path string) tea.Cmd {
+	candidates, err := importer.Bookmarks(path, m.registeredPaths())
+	if err != nil {
+		m.mode = ModeNormal
+		return showError(fmt.Sprintf("Failed to read %s: %v", path, err))
+	}
+	if len(candidates) == 0 {
+		m.mode = ModeNormal
+		return showStatus("No new files listed in " + path)
+	}
+	m.startImport(importState{title: "Import from " + path, candidates: candidates}, true)
+	return nil
+}
+
+func (m model) updateImport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	imp := &m.imp
+
+	if imp.filtering {
+		switch msg.String() {
+		case "enter", "esc":
+			imp.filtering = false
+		case "backspace":
+			if imp.filter != "" {
+				runes := []rune(imp.filter)
+				imp.filter = string(runes[:len(runes)-1])
+			}
+		default:
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				imp.filter += string(msg.Runes)
+			}
+		}
+		imp.cursor = 0
+		return m, nil
+	}
+
+	visible := imp.visible()
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = ModeNormal
+		m.imp = importState{}
+		return m, showStatus("Import cancelled")
+	case "/":
+		imp.filtering = true
+	case "j", "down":
+		if imp.cursor < len(visible)-1 {
+			imp.cursor++
+		}
+	case "k", "up":
+		if imp.cursor > 0 {
+			imp.cursor--
+		}
+	case " ":
+		if imp.cursor < len(visible) {
+			idx := visible[imp.cursor]
+			imp.selected[idx] = !imp.selected[idx]
+			if imp.cursor < len(visible)-1 {
+				imp.cursor++
+			}
+		}
+	case "a":
+		// Toggle every visible row: select all unless all are already selected.
+		all := true
+		for _, idx := range visible {
+			all = all && imp.selected[idx]
+		}
+		for _, idx := range visible {
+			imp.selected[idx] = !all
+		}
+	case "enter":
+		return m, m.commitImport()
+	}
+	return m, nil
+}
+
+// commitImport registers the selected candidates in one save.
+func (m *model) commitImport() tea.Cmd {
+	configs := make([]models.ConfigEntry, len(m.configs))
+	copy(configs, m.configs)
+
+	added := 0
+	now := time.Now()
+	for i, c := range m.imp.candidates {
+		if !m.imp.selected[i] {
+			continue
+		}
+		configs = append(configs, models.ConfigEntry{
+			Name:        filepath.Base(c.Path),
+			Path:        c.Path,
+			Type:        c.Type,
+			Project:     c.Project,
+			Added:       now,
+			ConfirmOpen: models.NeedsOpenConfirmation(c.Path),
+		})
+		added++
+	}
+
+	m.mode = ModeNormal
+	m.imp = importState{}
+	if added == 0 {
+		return showWarning("Nothing selected to import")
+	}
+	if err := m.storage.Save(configs); err != nil {
+		return showError(fmt.Sprintf("Failed to save: %v", err))
+	}
+	m.configs = configs
+	m.cacheValid = false
+	m.buildDisplayList()
+	m.refreshRightViewport()
+	return showSuccess(fmt.Sprintf("Imported %d files", added))
+}
+
+// renderImportPanel renders the import checklist in place of the file list.
+func (m model) renderImportPanel() string {
+	height := m.mainContentHeight()
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("117")).
+		Padding(0, 1)
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+	bodyHeight := height - panelStyle.GetVerticalFrameSize() - 2
+	if bodyHeight < 1 {
+		bodyHeight = 1
+	}
+
+	imp := m.imp
+	visible := imp.visible()
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("117")).
+		Render(imp.title)
+	filterLine := suitechrome.Dim(fmt.Sprintf("%d of %d selected", imp.selectedCount(), len(imp.candidates)))
+	if imp.filter != "" || imp.filtering {
+		filterLine += "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("/"+imp.filter)
+	}
+
+	start := 0
+	if imp.cursor >= bodyHeight {
+		start = imp.cursor - bodyHeight + 1
+	}
+	end := start + bodyHeight
+	if end > len(visible) {
+		end = len(visible)
+	}
+
+	rows := []string{title, filterLine}
+	for i := start; i < end; i++ {
+		c := imp.candidates[visible[i]]
+		box := "[ ]"
+		if imp.selected[visible[i]] {
+			box = "[x]"
+		}
+		path := c.Path
+		if imp.root != "" {
+			if rel, err := filepath.Rel(imp.root, c.Path); err == nil {
+				path = rel
+			}
+		}
+		project := c.Project
+		if project == "" {
+			project = "General"
+		}
+		meta := suitechrome.Dim(fmt.Sprintf("%s → %s", c.Type, project))
+		line := suitechrome.JoinLine(innerWidth, box+" "+path, meta)
+		if i == imp.cursor {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62")).Render(line)
+		}
+		rows = append(rows, line)
+	}
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(height - panelStyle.GetVerticalFrameSize()).
+		Render(strings.Join(rows, "\n"))
+}
@@ -1,11 +1,15 @@
 package editor
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/LFroesch/zap/internal/models"
 
@@ -16,6 +20,79 @@ var terminalEditors = map[string]bool{
 	"nvim": true, "vim": true, "vi": true, "nano": true, "emacs": true,
 }
 
+// multiFileFlags holds the flag a terminal editor needs to open several files
+// side by side instead of one after another
+var multiFileFlags = map[string]string{
+	"nvim": "-p", "vim": "-p",
+}
+
+// guiWaitFlags holds the flag that makes a GUI editor block until the file
+// is closed
+var guiWaitFlags = map[string]string{
+	"code": "--wait", "code-insiders": "--wait", "codium": "--wait",
+	"subl": "-w", "zed": "--wait", "mate": "-w", "gedit": "--wait",
+	"kate": "--block", "gvim": "-f",
+}
+
+// readOnlyFlags holds the arguments that open files in an editor's view
+// mode, where they can't be modified by accident
+var readOnlyFlags = map[string][]string{
+	"nvim": {"-R"}, "vim": {"-R"}, "vi": {"-R"}, "gvim": {"-R"},
+	"nano": {"-v"}, "micro": {"-readonly", "true"},
+}
+
+// isTerminal reports whether editorCmd runs in the terminal: as opts.Kind
+// says, or else as zap assumes for it.
+func isTerminal(editorCmd string, opts Options) bool {
+	switch strings.ToLower(opts.Kind) {
+	case "terminal":
+		return true
+	case "gui":
+		return false
+	}
+	return terminalEditors[program(editorCmd)]
+}
+
+// SupportsReadOnly reports whether Options.ReadOnly has an effect on
+// editorCmd. Files meant to stay untouched can be paged instead.
+func SupportsReadOnly(editorCmd string) bool {
+	_, ok := readOnlyFlags[program(editorCmd)]
+	return ok
+}
+
+// Options tweaks how an editor is launched
+type Options struct {
+	// Wait blocks on GUI editors that support a wait flag until they exit
+	Wait bool
+	// Line opens a single file with the cursor on this line (1-based) in
+	// editors that support it; zero opens at the top
+	Line int
+	// ReadOnly opens the files in the editor's view mode when it has one
+	ReadOnly bool
+	// DryRun checks the paths and reports them opened without starting the
+	// editor, for headless runs
+	DryRun bool
+	// Kind is "terminal" or "gui" for editors zap doesn't know or guesses
+	// wrong about; empty keeps zap's guess
+	Kind string
+}
+
+// lineArgs returns the arguments that open path at line in editorCmd, or nil
+// when the editor has no known way to do so
+func lineArgs(editorCmd, path string, line int) []string {
+	switch program(editorCmd) {
+	case "nvim", "vim", "vi", "nano", "emacs", "gvim", "gedit":
+		return []string{fmt.Sprintf("+%d", line), path}
+	case "code", "code-insiders", "codium":
+		return []string{"--goto", fmt.Sprintf("%s:%d", path, line)}
+	case "subl", "zed":
+		return []string{fmt.Sprintf("%s:%d", path, line)}
+	case "mate", "kate":
+		return []string{"-l", fmt.Sprint(line), path}
+	}
+	return nil
+}
+
 // ExpandPath expands ~ to home directory
 func ExpandPath(path string) string {
 	if strings.HasPrefix(path, "~/") {
@@ -37,47 +114,285 @@ func FileExists(path string) bool {
 
 // editorFinishedMsg is sent when the editor exits
 type editorFinishedMsg struct {
-	err  error
-	name string
+	err    error
+	name   string
+	paths  []string // as passed to OpenPaths
+	waited bool     // the editor ran to completion rather than being started
 }
 
 // OpenConfig opens a config file in the specified editor
-func OpenConfig(config models.ConfigEntry, editorCmd string) tea.Cmd {
-	return OpenPath(config.Path, editorCmd, config.Name)
+func OpenConfig(config models.ConfigEntry, editorCmd string, opts Options) tea.Cmd {
+	return OpenPathWith(config.Path, editorCmd, config.Name, opts)
 }
 
 // OpenPath opens any path in the specified editor
 func OpenPath(path, editorCmd, label string) tea.Cmd {
-	return func() tea.Msg {
-		expandedPath := ExpandPath(path)
+	return OpenPathWith(path, editorCmd, label, Options{})
+}
 
-		if !FileExists(path) {
-			return editorFinishedMsg{
-				err:  fmt.Errorf("path not found: %s", expandedPath),
-				name: label,
-			}
+// OpenPathWith opens any path in the specified editor using opts
+func OpenPathWith(path, editorCmd, label string, opts Options) tea.Cmd {
+	return OpenPaths([]string{path}, editorCmd, label, opts)
+}
+
+// OpenPaths opens several paths with a single editor invocation. Terminal
+// editors that support it use their tabbed multi-file mode.
+func OpenPaths(paths []string, editorCmd, label string, opts Options) tea.Cmd {
+	if opts.DryRun {
+		_, err := expandPaths(paths)
+		return func() tea.Msg {
+			return editorFinishedMsg{err: err, name: label, paths: paths}
 		}
+	}
 
-		if _, err := exec.LookPath(editorCmd); err != nil {
-			return editorFinishedMsg{
-				err:  fmt.Errorf("editor '%s' not found in PATH", editorCmd),
-				name: label,
-			}
+	cmd, err := command(paths, editorCmd, opts)
+	if err != nil {
+		return func() tea.Msg {
+			return editorFinishedMsg{err: err, name: label, paths: paths}
 		}
+	}
 
-		isTerminal := terminalEditors[editorCmd]
+	if isTerminal(editorCmd, opts) {
+		// ExecProcess must be returned as a command, not a message, for
+		// bubbletea to hand the terminal over to the editor.
+		return tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return editorFinishedMsg{err: err, name: label, paths: paths, waited: true}
+		})
+	}
 
-		if isTerminal {
-			cmd := exec.Command(editorCmd, expandedPath)
-			return tea.ExecProcess(cmd, func(err error) tea.Msg {
-				return editorFinishedMsg{err: err, name: label}
-			})
+	return func() tea.Msg {
+		if waits(editorCmd, opts) {
+			// Runs in the command goroutine, so the UI stays responsive.
+			err := cmd.Run()
+			return editorFinishedMsg{err: err, name: label, paths: paths, waited: true}
 		}
 
-		cmd := exec.Command(editorCmd, expandedPath)
 		err := cmd.Start()
-		return editorFinishedMsg{err: err, name: label}
+		return editorFinishedMsg{err: err, name: label, paths: paths}
+	}
+}
+
+// sniffLen is how much of a file IsBinary needs to look at
+const sniffLen = 8000
+
+// IsBinary guesses from its first bytes whether data is binary rather than
+// text: it holds a NUL byte, isn't valid UTF-8, or is more than a tenth
+// control characters other than whitespace and escapes.
+func IsBinary(data []byte) bool {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+		// Don't count a multi-byte character cut off at the end as invalid
+		for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return true
+	}
+	control := 0
+	for _, b := range data {
+		if (b < 0x20 && !strings.ContainsRune("\t\n\r\f\v\x1b", rune(b))) || b == 0x7f {
+			control++
+		}
+	}
+	return control*10 > len(data)
+}
+
+// maxPagedDump caps how much of a binary file Page shows as a hex dump
+const maxPagedDump = 1 << 20
+
+// pagerFinishedMsg is sent when the pager started by Page exits
+type pagerFinishedMsg struct {
+	err  error
+	name string
+}
+
+// Pager returns the command line of the pager to view files with: $PAGER,
+// or less, falling back to more.
+func Pager() []string {
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
 	}
+	if _, err := exec.LookPath("less"); err == nil {
+		return []string{"less"}
+	}
+	return []string{"more"}
+}
+
+// Page shows path in the pager, handing it the terminal until it exits.
+// LESSSECURE keeps less from starting an editor or shell, so the file can
+// only be read.
+func Page(path, label string, opts Options) tea.Cmd {
+	if opts.DryRun {
+		_, err := expandPaths([]string{path})
+		return func() tea.Msg {
+			return pagerFinishedMsg{err: err, name: label}
+		}
+	}
+	cmd, err := pagerCommand(path)
+	if err != nil {
+		return func() tea.Msg {
+			return pagerFinishedMsg{err: err, name: label}
+		}
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return pagerFinishedMsg{err: err, name: label}
+	})
+}
+
+// LaunchPager shows path in the pager outside of the TUI, on the current
+// terminal until it exits.
+func LaunchPager(path string) error {
+	cmd, err := pagerCommand(path)
+	if err != nil {
+		return err
+	}
+	if cmd.Stdin == nil {
+		cmd.Stdin = os.Stdin
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// pagerCommand builds the pager invocation for path.
+func pagerCommand(path string) (*exec.Cmd, error) {
+	args, err := expandPaths([]string{path})
+	if err != nil {
+		return nil, err
+	}
+
+	pager := Pager()
+	if _, err := exec.LookPath(pager[0]); err != nil {
+		return nil, fmt.Errorf("pager '%s' not found in PATH", pager[0])
+	}
+	cmd := exec.Command(pager[0], append(pager[1:], args...)...)
+	if dump, ok := binaryDump(args[0]); ok {
+		// Pagers print binary files as control characters; show a hex
+		// dump on stdin instead
+		cmd = exec.Command(pager[0], pager[1:]...)
+		cmd.Stdin = strings.NewReader(dump)
+	}
+	cmd.Env = append(os.Environ(), "LESSSECURE=1")
+	return cmd, nil
+}
+
+// binaryDump returns a hex dump of the start of path when it is binary.
+func binaryDump(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxPagedDump))
+	if err != nil || !IsBinary(data) {
+		return "", false
+	}
+	return hex.Dump(data), true
+}
+
+// HandlePagerFinished reports whether msg ends a Page call, with the status
+// to show when the pager failed
+func HandlePagerFinished(msg tea.Msg) (string, bool) {
+	if m, ok := msg.(pagerFinishedMsg); ok {
+		if m.err != nil {
+			return fmt.Sprintf("Failed to view %s: %v", m.name, m.err), true
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// Launch opens paths in the editor outside of the TUI. Terminal editors take
+// over the current terminal until they exit.
+func Launch(paths []string, editorCmd string, opts Options) error {
+	cmd, err := command(paths, editorCmd, opts)
+	if err != nil {
+		return err
+	}
+
+	if isTerminal(editorCmd, opts) {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+	if waits(editorCmd, opts) {
+		return cmd.Run()
+	}
+	return cmd.Start()
+}
+
+// command validates paths and editor and builds the editor invocation.
+func command(paths []string, editorCmd string, opts Options) (*exec.Cmd, error) {
+	args, err := expandPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	name := program(editorCmd)
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("editor '%s' not found in PATH", name)
+	}
+
+	if opts.Line > 0 && len(args) == 1 {
+		if withLine := lineArgs(editorCmd, args[0], opts.Line); withLine != nil {
+			args = withLine
+		}
+	}
+
+	if isTerminal(editorCmd, opts) {
+		if flag := multiFileFlags[name]; flag != "" && len(paths) > 1 {
+			args = append([]string{flag}, args...)
+		}
+	} else if waits(editorCmd, opts) {
+		args = append([]string{guiWaitFlags[name]}, args...)
+	}
+	if opts.ReadOnly {
+		args = append(append([]string(nil), readOnlyFlags[name]...), args...)
+	}
+
+	// Arguments given with the editor, as in "code --new-window", come first
+	extra := strings.Fields(editorCmd)[1:]
+	return exec.Command(name, append(extra, args...)...), nil
+}
+
+// program is the executable of editorCmd, without any arguments given with it.
+func program(editorCmd string) string {
+	if fields := strings.Fields(editorCmd); len(fields) > 0 {
+		return fields[0]
+	}
+	return editorCmd
+}
+
+// expandPaths expands paths for the editor's command line, failing on the
+// first one that doesn't exist.
+func expandPaths(paths []string) ([]string, error) {
+	var expanded []string
+	for _, path := range paths {
+		expandedPath := ExpandPath(path)
+		if !FileExists(path) {
+			return nil, fmt.Errorf("path not found: %s", expandedPath)
+		}
+		expanded = append(expanded, expandedPath)
+	}
+	return expanded, nil
+}
+
+// waits reports whether a GUI editor will be launched with its wait flag.
+func waits(editorCmd string, opts Options) bool {
+	_, ok := guiWaitFlags[program(editorCmd)]
+	return ok && opts.Wait && !isTerminal(editorCmd, opts)
+}
+
+// OpenedPaths returns the paths an editor opened when msg reports that it
+// started (GUI editors) or exited cleanly (terminal and waited-on editors).
+// It returns nil for failed launches and other messages.
+func OpenedPaths(msg tea.Msg) []string {
+	if m, ok := msg.(editorFinishedMsg); ok && m.err == nil {
+		return m.paths
+	}
+	return nil
 }
 
 // HandleEditorFinished processes the editor finished message
@@ -86,6 +401,9 @@ func HandleEditorFinished(msg tea.Msg) (string, bool) {
 		if m.err != nil {
 			return fmt.Sprintf("Failed to open %s: %v", m.name, m.err), true
 		}
+		if m.waited {
+			return fmt.Sprintf("Finished editing %s", m.name), true
+		}
 		return fmt.Sprintf("Opened %s in editor", m.name), true
 	}
 	return "", false
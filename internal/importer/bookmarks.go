@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"bufio"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// bookmarkToken matches the parts of a Netscape bookmark file that matter:
+// folder names, the lists they open and close, and links
+var bookmarkToken = regexp.MustCompile(`(?is)<h3[^>]*>(.*?)</h3>|<dl[^>]*>|</dl>|<a\s[^>]*href\s*=\s*"([^"]*)"`)
+
+// Bookmarks reads a Netscape bookmarks HTML export or a plain list of paths,
+// one per line, and returns the files it names that exist and aren't in
+// exclude. Bookmarks inside a folder suggest the folder as their project.
+func Bookmarks(path string, exclude map[string]bool) ([]Candidate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+
+	var candidates []Candidate
+	if isBookmarkHTML(text) {
+		candidates = parseBookmarkHTML(text)
+	} else {
+		candidates = parsePathList(text)
+	}
+	return existingFiles(candidates, exclude), nil
+}
+
+// isBookmarkHTML reports whether text looks like a Netscape bookmarks file
+func isBookmarkHTML(text string) bool {
+	head := strings.ToUpper(text[:min(len(text), 512)])
+	return strings.Contains(head, "NETSCAPE-BOOKMARK-FILE") || strings.Contains(head, "<DL")
+}
+
+func parseBookmarkHTML(text string) []Candidate {
+	var candidates []Candidate
+	var folders []string
+	pending := ""
+	for _, match := range bookmarkToken.FindAllStringSubmatch(text, -1) {
+		token := strings.ToLower(match[0])
+		switch {
+		case strings.HasPrefix(token, "<h3"):
+			pending = strings.TrimSpace(html.UnescapeString(match[1]))
+		case strings.HasPrefix(token, "<dl"):
+			folders = append(folders, pending)
+			pending = ""
+		case token == "</dl>":
+			if len(folders) > 0 {
+				folders = folders[:len(folders)-1]
+			}
+		default:
+			path, ok := fileURLPath(html.UnescapeString(match[2]))
+			if !ok {
+				continue
+			}
+			project := ""
+			if len(folders) > 0 {
+				project = folders[len(folders)-1]
+			}
+			candidates = append(candidates, Candidate{Path: path, Project: project})
+		}
+	}
+	return candidates
+}
+
+// fileURLPath returns the local path of a file:// URL
+func fileURLPath(link string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return "", false
+	}
+	path := u.Path
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:] // file:///C:/... on Windows
+	}
+	return filepath.FromSlash(path), true
+}
+
+// parsePathList reads one path per line, skipping blank lines and # comments.
+// file:// URLs and ~ paths are accepted.
+func parsePathList(text string) []Candidate {
+	home, _ := os.UserHomeDir()
+	var candidates []Candidate
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if path, ok := fileURLPath(line); ok {
+			line = path
+		} else if strings.HasPrefix(line, "~/") && home != "" {
+			line = filepath.Join(home, line[2:])
+		}
+		candidates = append(candidates, Candidate{Path: line})
+	}
+	return candidates
+}
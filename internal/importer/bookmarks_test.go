@@ -0,0 +1,51 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBookmarksReadsNetscapeFoldersAndPathLists(t *testing.T) {
+	dir := t.TempDir()
+	nginx := filepath.Join(dir, "nginx.conf")
+	hosts := filepath.Join(dir, "hosts")
+	for _, path := range []string{nginx, hosts} {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	export := filepath.Join(dir, "bookmarks.html")
+	html := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<DL><p>
+  <DT><H3>Web &amp; Proxy</H3>
+  <DL><p>
+    <DT><A HREF="file://` + filepath.ToSlash(nginx) + `">nginx</A>
+    <DT><A HREF="https://example.com">docs</A>
+  </DL><p>
+  <DT><A HREF="file://` + filepath.ToSlash(hosts) + `">hosts</A>
+</DL><p>`
+	if err := os.WriteFile(export, []byte(html), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Bookmarks(export, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Path != nginx || got[0].Project != "Web & Proxy" || got[1].Path != hosts || got[1].Project != "" {
+		t.Fatalf("Bookmarks(html) = %+v", got)
+	}
+
+	list := filepath.Join(dir, "paths.txt")
+	if err := os.WriteFile(list, []byte("# mine\n"+hosts+"\n\n"+filepath.Join(dir, "missing")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = Bookmarks(list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Path != hosts {
+		t.Fatalf("Bookmarks(list) = %+v", got)
+	}
+}
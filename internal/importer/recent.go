@@ -0,0 +1,186 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/models"
+)
+
+// vscodeDirs are the per-user settings directories of VS Code and its forks
+var vscodeDirs = []string{"Code", "Code - Insiders", "VSCodium"}
+
+// historyFiles are shell history files relative to the home directory
+var historyFiles = []string{".bash_history", ".zsh_history", ".histfile", ".local/share/fish/fish_history"}
+
+// VSCodeRecent returns the files in VS Code's recently opened list. Older
+// releases keep it in storage.json; newer ones in a SQLite database that is
+// read with the sqlite3 command when it is installed.
+func VSCodeRecent(configDir string) []string {
+	var paths []string
+	for _, dir := range vscodeDirs {
+		storage := filepath.Join(configDir, dir, "User", "globalStorage")
+		paths = append(paths, vscodeStorageJSON(filepath.Join(storage, "storage.json"))...)
+		paths = append(paths, vscodeStateDB(filepath.Join(storage, "state.vscdb"))...)
+	}
+	return paths
+}
+
+// recentList is VS Code's serialised recently opened list
+type recentList struct {
+	Entries []struct {
+		FileURI string `json:"fileUri"`
+	} `json:"entries"`
+}
+
+func vscodeStorageJSON(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var storage struct {
+		OpenedPathsList recentList `json:"openedPathsList"`
+	}
+	if json.Unmarshal(data, &storage) != nil {
+		return nil
+	}
+	return storage.OpenedPathsList.files()
+}
+
+func vscodeStateDB(path string) []string {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		return nil
+	}
+	out, err := exec.Command("sqlite3", "-readonly", path,
+		"SELECT value FROM ItemTable WHERE key = 'history.recentlyOpenedPathsList'").Output()
+	if err != nil {
+		return nil
+	}
+	var list recentList
+	if json.Unmarshal(out, &list) != nil {
+		return nil
+	}
+	return list.files()
+}
+
+// files returns the local file paths in the list, skipping folders and
+// remote workspaces
+func (l recentList) files() []string {
+	var paths []string
+	for _, entry := range l.Entries {
+		if path, ok := fileURLPath(entry.FileURI); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// ShellHistory returns the files passed to one of editors in the user's shell
+// history, most used first. Only absolute and ~ paths are kept since the
+// directory a command ran in isn't recorded.
+func ShellHistory(home string, editors []string) []string {
+	isEditor := make(map[string]bool, len(editors))
+	for _, e := range editors {
+		isEditor[filepath.Base(e)] = true
+	}
+
+	counts := map[string]int{}
+	for _, name := range historyFiles {
+		f, err := os.Open(filepath.Join(home, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			for _, path := range editedPaths(historyCommand(scanner.Text()), isEditor) {
+				if strings.HasPrefix(path, "~/") {
+					path = filepath.Join(home, path[2:])
+				}
+				counts[path]++
+			}
+		}
+		f.Close()
+	}
+
+	paths := make([]string, 0, len(counts))
+	for path := range counts {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if counts[paths[i]] != counts[paths[j]] {
+			return counts[paths[i]] > counts[paths[j]]
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
+
+// historyCommand strips the zsh extended-history and fish prefixes from a
+// history line
+func historyCommand(line string) string {
+	if strings.HasPrefix(line, ": ") {
+		if _, cmd, ok := strings.Cut(line, ";"); ok {
+			return cmd
+		}
+	}
+	return strings.TrimPrefix(line, "- cmd: ")
+}
+
+// editedPaths returns the file arguments of cmd when it runs an editor,
+// looking past sudo
+func editedPaths(cmd string, isEditor map[string]bool) []string {
+	fields := strings.Fields(cmd)
+	if len(fields) > 0 && fields[0] == "sudo" {
+		fields = fields[1:]
+	}
+	if len(fields) < 2 || !isEditor[filepath.Base(fields[0])] {
+		return nil
+	}
+	var paths []string
+	for _, arg := range fields[1:] {
+		arg = strings.Trim(arg, `"'`)
+		if strings.HasPrefix(arg, "/") || strings.HasPrefix(arg, "~/") {
+			paths = append(paths, arg)
+		}
+	}
+	return paths
+}
+
+// Recent turns paths gathered from other tools into candidates, keeping the
+// first occurrence of each regular file that isn't in exclude
+func Recent(paths []string, exclude map[string]bool) []Candidate {
+	candidates := make([]Candidate, len(paths))
+	for i, path := range paths {
+		candidates[i] = Candidate{Path: path}
+	}
+	return existingFiles(candidates, exclude)
+}
+
+// existingFiles keeps the first candidate for each regular file that isn't
+// in exclude and fills in its type
+func existingFiles(candidates []Candidate, exclude map[string]bool) []Candidate {
+	seen := map[string]bool{}
+	var out []Candidate
+	for _, c := range candidates {
+		c.Path = filepath.Clean(c.Path)
+		if seen[c.Path] || exclude[c.Path] {
+			continue
+		}
+		seen[c.Path] = true
+		if info, err := os.Stat(c.Path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		c.Type = models.DetectFileType(c.Path)
+		out = append(out, c)
+	}
+	return out
+}
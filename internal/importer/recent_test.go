@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShellHistoryFindsEditedFiles(t *testing.T) {
+	home := t.TempDir()
+	history := ": 1700000000:0;vim ~/.bashrc\n: 1700000001:0;sudo nano /etc/hosts\n: 1700000002:0;nvim -p ~/.bashrc relative.txt\nls ~/.vimrc\n"
+	if err := os.WriteFile(filepath.Join(home, ".zsh_history"), []byte(history), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ShellHistory(home, []string{"vim", "nvim", "nano"})
+	want := []string{filepath.Join(home, ".bashrc"), "/etc/hosts"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("ShellHistory = %v, want %v", got, want)
+	}
+}
+
+func TestVSCodeRecentReadsStorageJSON(t *testing.T) {
+	configDir := t.TempDir()
+	storage := filepath.Join(configDir, "Code", "User", "globalStorage")
+	if err := os.MkdirAll(storage, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(map[string]any{"openedPathsList": map[string]any{"entries": []map[string]string{
+		{"fileUri": "file:///etc/nginx/nginx.conf"},
+		{"folderUri": "file:///home/me/project"},
+		{"fileUri": "vscode-remote://ssh-remote+box/etc/hosts"},
+	}}})
+	if err := os.WriteFile(filepath.Join(storage, "storage.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := VSCodeRecent(configDir)
+	if len(got) != 1 || got[0] != "/etc/nginx/nginx.conf" {
+		t.Fatalf("VSCodeRecent = %v, want only the local file", got)
+	}
+}
+
+func TestRecentSkipsRegisteredAndMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "app.yaml")
+	registered := filepath.Join(dir, "known.json")
+	for _, path := range []string{kept, registered} {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := Recent([]string{kept, registered, filepath.Join(dir, "gone.toml"), kept, dir}, map[string]bool{registered: true})
+	if len(got) != 1 || got[0].Path != kept || got[0].Type != "yaml" {
+		t.Fatalf("Recent = %+v, want only app.yaml", got)
+	}
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// renameMaxVisited caps how many directory entries FindRenames looks at
+const renameMaxVisited = 20000
+
+// FindRenames looks for files that a missing path may have been moved to:
+// files with the same base name in the directory tree around the old
+// location. When size is known, only files of a similar size qualify and the
+// closest sizes come first. Paths in exclude are left out.
+func FindRenames(missing string, size int64, opts Options, exclude map[string]bool) []string {
+	root := nearestExistingDir(filepath.Dir(missing))
+	if root == "" || filepath.Dir(root) == root {
+		return nil // never walk the whole filesystem
+	}
+	// Search from one level up so moves into a sibling directory are found.
+	if parent := filepath.Dir(root); filepath.Dir(parent) != parent {
+		root = parent
+	}
+
+	name := filepath.Base(missing)
+	type match struct {
+		path string
+		diff int64
+	}
+	var matches []match
+	visited := 0
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if visited++; visited > renameMaxVisited {
+			return filepath.SkipAll
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if d.IsDir() {
+			depth := strings.Count(rel, string(filepath.Separator))
+			if path != root && (depth >= opts.MaxDepth || Ignored(rel, opts.Ignore)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != name || !d.Type().IsRegular() || exclude[path] || path == missing {
+			return nil
+		}
+
+		var diff int64
+		if size > 0 {
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			diff = info.Size() - size
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > size/4+512 {
+				return nil
+			}
+		}
+		matches = append(matches, match{path: path, diff: diff})
+		return nil
+	})
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].diff != matches[j].diff {
+			return matches[i].diff < matches[j].diff
+		}
+		return matches[i].path < matches[j].path
+	})
+	paths := make([]string, len(matches))
+	for i, m := range matches {
+		paths[i] = m.path
+	}
+	return paths
+}
+
+// nearestExistingDir walks up from dir to the first directory that exists.
+func nearestExistingDir(dir string) string {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/models"
+)
+
+// Candidate is a file found by Scan that could be registered
+type Candidate struct {
+	Path    string
+	Type    string
+	Project string // suggested project, taken from the scanned directory
+}
+
+// Options limits how far Scan walks
+type Options struct {
+	MaxDepth int      // directories below root to descend into (0 = root only)
+	MaxFiles int      // stop after this many candidates
+	Ignore   []string // glob patterns for names or root-relative paths to skip
+}
+
+// DefaultOptions returns the limits used by the TUI import
+func DefaultOptions(ignore []string) Options {
+	return Options{MaxDepth: 3, MaxFiles: 500, Ignore: ignore}
+}
+
+// Ignored reports whether a file or directory matches one of the patterns,
+// either by its base name or by its path relative to the scan root
+func Ignored(relPath string, patterns []string) bool {
+	relPath = filepath.ToSlash(relPath)
+	name := filepath.Base(relPath)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan walks root looking for files with a recognised type. Hidden and
+// ignored directories are skipped and paths in exclude are left out.
+func Scan(root string, opts Options, exclude map[string]bool) ([]Candidate, error) {
+	root = filepath.Clean(root)
+	project := filepath.Base(root)
+
+	var candidates []Candidate
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // unreadable subdirectory, keep going
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		depth := strings.Count(rel, string(filepath.Separator))
+
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || depth >= opts.MaxDepth || Ignored(rel, opts.Ignore)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() || exclude[path] || Ignored(rel, opts.Ignore) || !models.IsKnownExtension(path) {
+			return nil
+		}
+
+		candidates = append(candidates, Candidate{
+			Path:    path,
+			Type:    models.DetectFileType(path),
+			Project: project,
+		})
+		if opts.MaxFiles > 0 && len(candidates) >= opts.MaxFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Path < candidates[j].Path
+	})
+	return candidates, nil
+}
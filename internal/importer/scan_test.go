@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "infra")
+	for _, name := range []string{
+		"nginx.conf",
+		"notes",
+		"docker-compose.yml",
+		"app/config.yaml",
+		"app/deep/settings.toml",
+		".git/config.json",
+		"node_modules/pkg/package.json",
+		"yarn.lock",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ignore := []string{"node_modules", "*.lock"}
+
+	for _, tc := range []struct {
+		name    string
+		opts    Options
+		exclude []string
+		want    map[string]string // root-relative path → type
+	}{
+		{
+			name: "root only",
+			opts: Options{MaxDepth: 0, Ignore: ignore},
+			want: map[string]string{"docker-compose.yml": "compose", "nginx.conf": "ini"},
+		},
+		{
+			name: "one level down",
+			opts: Options{MaxDepth: 1, Ignore: ignore},
+			want: map[string]string{"docker-compose.yml": "compose", "nginx.conf": "ini", "app/config.yaml": "yaml"},
+		},
+		{
+			name: "default limits",
+			opts: DefaultOptions(ignore),
+			want: map[string]string{"docker-compose.yml": "compose", "nginx.conf": "ini", "app/config.yaml": "yaml", "app/deep/settings.toml": "toml"},
+		},
+		{
+			name:    "registered files are left out",
+			opts:    DefaultOptions(ignore),
+			exclude: []string{"nginx.conf", "app/config.yaml"},
+			want:    map[string]string{"docker-compose.yml": "compose", "app/deep/settings.toml": "toml"},
+		},
+		{
+			name: "nothing ignored",
+			opts: Options{MaxDepth: 3},
+			want: map[string]string{"docker-compose.yml": "compose", "nginx.conf": "ini", "app/config.yaml": "yaml", "app/deep/settings.toml": "toml", "node_modules/pkg/package.json": "json"},
+		},
+		{
+			name: "file limit",
+			opts: Options{MaxDepth: 3, MaxFiles: 1, Ignore: ignore},
+			want: map[string]string{"app/config.yaml": "yaml"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			exclude := make(map[string]bool)
+			for _, rel := range tc.exclude {
+				exclude[filepath.Join(root, filepath.FromSlash(rel))] = true
+			}
+			got, err := Scan(root, tc.opts, exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Scan found %+v, want %v", got, tc.want)
+			}
+			for i, c := range got {
+				rel, _ := filepath.Rel(root, c.Path)
+				want, ok := tc.want[filepath.ToSlash(rel)]
+				if !ok || c.Type != want || c.Project != "infra" {
+					t.Errorf("candidate %+v not expected (want type %q)", c, want)
+				}
+				if i > 0 && got[i-1].Path >= c.Path {
+					t.Errorf("candidates not sorted by path: %s before %s", got[i-1].Path, c.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestScanMissingRoot(t *testing.T) {
+	if _, err := Scan(filepath.Join(t.TempDir(), "missing"), DefaultOptions(nil), nil); err == nil {
+		t.Fatal("scanning a missing directory should fail")
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"node_modules", true},
+		{"web/node_modules", true},
+		{"yarn.lock", true},
+		{"build/out.json", true},
+		{"src/build/out.json", false},
+		{"config.yaml", false},
+	} {
+		if got := Ignored(tc.path, []string{"node_modules", "*.lock", "build/*"}); got != tc.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
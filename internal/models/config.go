@@ -1,7 +1,11 @@
 package models
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -9,52 +13,325 @@ import (
 // ConfigEntry represents a registered file in the registry
 type ConfigEntry struct {
 	Name        string    `json:"name"`
+	Alias       string    `json:"alias,omitempty"` // short unique nickname accepted wherever a name is
 	Path        string    `json:"path"`
 	Type        string    `json:"type"`        // json, yaml, toml, ini, txt
 	Project     string    `json:"project"`     // project association
 	Description string    `json:"description"` // brief description
 	LastOpened  time.Time `json:"last_opened,omitempty"`
-	Tags        []string  `json:"tags,omitempty"` // flexible tagging
+	Added       time.Time `json:"added,omitempty"`   // when the entry was registered
+	Expires     time.Time `json:"expires,omitempty"` // temporary registration, offered for cleanup once past
+	OpenCount   int       `json:"open_count,omitempty"`
+	Size        int64     `json:"size,omitempty"`         // size when last verified, used to recognise moved files
+	Editor      string    `json:"editor,omitempty"`       // editor last chosen via open-with
+	ReviewEvery string    `json:"review_every,omitempty"` // e.g. 90d, 2w, 6m; opening the file counts as a review
+	Run         string    `json:"run,omitempty"`          // check command, e.g. nginx -t, run from the file's directory
+	AutoCheck   bool      `json:"auto_check,omitempty"`   // re-run Run whenever the file changes on disk
+	Source      string    `json:"source,omitempty"`       // canonical URL or path the file derives from, for diffing
+	Checksum    string    `json:"checksum,omitempty"`     // expected content hash as sha256:<hex>, checked by zap verify
+	Secret      string    `json:"secret,omitempty"`       // secret manager reference (pass:<path> or op://…); the value is never stored
+	ConfirmOpen bool      `json:"confirm_open,omitempty"` // ask y/n before launching the editor
+	ReadOnly    bool      `json:"read_only,omitempty"`    // open in the editor's view mode (or the pager) and never edit inline
+	Archived    bool      `json:"archived,omitempty"`     // kept for reference but hidden by default
+	Tags        []string  `json:"tags,omitempty"`         // flexible tagging
+
+	// PreviousNames are names the entry had before being renamed, oldest
+	// first. Search still finds the entry by them.
+	PreviousNames []string `json:"previous_names,omitempty"`
+
+	// Keywords are alternate names search matches ("k8s", "kubernetes")
+	// that aren't shown anywhere else.
+	Keywords []string `json:"keywords,omitempty"`
+
+	// Shared marks an entry read from the team registry overlay. It is never
+	// saved to the personal registry; editing it saves a local override.
+	Shared bool `json:"-"`
 }
 
 // ConfigManager manages the collection of config entries
 type ConfigManager struct {
-	Configs []ConfigEntry `json:"configs"`
+	Configs   []ConfigEntry  `json:"configs"`
+	Projects  []Project      `json:"projects,omitempty"`
+	Activity  map[string]int `json:"activity,omitempty"`  // opens per day, keyed YYYY-MM-DD
+	Onboarded bool           `json:"onboarded,omitempty"` // first-run tour finished or skipped
+	Deleted   []DeletedEntry `json:"deleted,omitempty"`   // recent removals, oldest first
+}
+
+// DeletedEntry is an entry removed from the registry, kept for a while so
+// the removal can be undone.
+type DeletedEntry struct {
+	ConfigEntry
+	Deleted time.Time `json:"deleted"`
+}
+
+// extensionTypes maps lowercase file extensions to registry types
+var extensionTypes = map[string]string{
+	".json":     "json",
+	".yaml":     "yaml",
+	".yml":      "yaml",
+	".toml":     "toml",
+	".ini":      "ini",
+	".conf":     "ini",
+	".xml":      "xml",
+	".md":       "markdown",
+	".markdown": "markdown",
+	".txt":      "txt",
+	".sh":       "shell",
+	".go":       "go",
+	".py":       "python",
+	".js":       "javascript",
+	".ts":       "typescript",
+	".rb":       "ruby",
+	".log":      "log",
+	".tf":       "terraform",
+	".tfvars":   "terraform",
+	".hcl":      "hcl",
+	".service":  "systemd",
+	".socket":   "systemd",
+	".timer":    "systemd",
+	".mount":    "systemd",
+	".path":     "systemd",
+	".target":   "systemd",
+}
+
+// nameTypes maps lowercase file names that identify a type on their own
+var nameTypes = map[string]string{
+	"dockerfile":          "dockerfile",
+	"containerfile":       "dockerfile",
+	"docker-compose.yml":  "compose",
+	"docker-compose.yaml": "compose",
+	"compose.yml":         "compose",
+	"compose.yaml":        "compose",
+	"ssh_config":          "ssh",
+	"sshd_config":         "ssh",
+	"hosts":               "hosts",
 }
 
-// DetectFileType automatically detects file type from extension
+// detect returns the type recognised from path's name or extension
+func detect(path string) (string, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	if fileType, ok := nameTypes[name]; ok {
+		return fileType, true
+	}
+	if name == "config" && filepath.Base(filepath.Dir(path)) == ".ssh" {
+		return "ssh", true
+	}
+	if strings.HasPrefix(name, "dockerfile.") || strings.HasSuffix(name, ".dockerfile") {
+		return "dockerfile", true
+	}
+	if name == ".env" || strings.HasPrefix(name, ".env.") || strings.HasSuffix(name, ".env") {
+		return "env", true
+	}
+	fileType, ok := extensionTypes[strings.ToLower(filepath.Ext(name))]
+	return fileType, ok
+}
+
+// DetectFileType automatically detects file type from its name or extension
 func DetectFileType(path string) string {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".json":
-		return "json"
-	case ".yaml", ".yml":
-		return "yaml"
-	case ".toml":
-		return "toml"
-	case ".ini", ".conf":
-		return "ini"
-	case ".xml":
-		return "xml"
-	case ".md", ".markdown":
-		return "markdown"
-	case ".txt":
-		return "txt"
-	case ".sh":
-		return "shell"
-	case ".go":
-		return "go"
-	case ".py":
-		return "python"
-	case ".js":
-		return "javascript"
-	case ".ts":
-		return "typescript"
-	case ".rb":
-		return "ruby"
-	default:
-		return "txt"
+	if fileType, ok := detect(path); ok {
+		return fileType
+	}
+	return "txt"
+}
+
+// IsKnownExtension reports whether DetectFileType recognises the path's name
+// or extension rather than falling back to txt
+func IsKnownExtension(path string) bool {
+	_, ok := detect(path)
+	return ok
+}
+
+// KnownFileTypes returns every type DetectFileType can produce, sorted
+func KnownFileTypes() []string {
+	seen := map[string]bool{}
+	var types []string
+	for _, table := range []map[string]string{extensionTypes, nameTypes} {
+		for _, fileType := range table {
+			if !seen[fileType] {
+				seen[fileType] = true
+				types = append(types, fileType)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// systemDirs hold files owned by root on Unix systems
+var systemDirs = []string{"/etc/", "/root/", "/boot/", "/usr/", "/var/", "/srv/", "/opt/"}
+
+// remotePrefixes start paths that editors such as vim open over the network
+var remotePrefixes = []string{"ssh://", "scp://", "sftp://", "rsync://"}
+
+// secretNames are file names that usually hold credentials
+var secretNames = map[string]bool{
+	"credentials": true, ".netrc": true, ".pgpass": true, ".npmrc": true, ".pypirc": true,
+	"shadow": true, "gshadow": true, "sudoers": true, "authorized_keys": true,
+	"id_rsa": true, "id_ecdsa": true, "id_ed25519": true, "id_dsa": true,
+}
+
+// NeedsOpenConfirmation reports whether opening path deserves a y/n prompt
+// first: it is remote, a system file outside the home directory, or a file
+// that usually holds credentials. New entries start with ConfirmOpen set
+// from it.
+func NeedsOpenConfirmation(path string) bool {
+	for _, prefix := range remotePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	slashed := filepath.ToSlash(path)
+	temp := filepath.ToSlash(os.TempDir()) + "/" // under /var on macOS, but not root's
+	for _, dir := range systemDirs {
+		if strings.HasPrefix(slashed, dir) && !strings.HasPrefix(slashed, temp) {
+			return true
+		}
+	}
+	name := strings.ToLower(filepath.Base(path))
+	switch ext := filepath.Ext(name); {
+	case secretNames[name], ext == ".pem", ext == ".key", ext == ".p12", ext == ".pfx", ext == ".kdbx":
+		return true
+	}
+	fileType, _ := detect(path)
+	return fileType == "env"
+}
+
+// RecordOpen bumps the usage counters for an entry opened at t
+func (c *ConfigEntry) RecordOpen(t time.Time) {
+	c.LastOpened = t
+	c.OpenCount++
+}
+
+// SearchTerms returns the hidden names search also matches: previous names
+// and keywords.
+func (c *ConfigEntry) SearchTerms() []string {
+	terms := make([]string, 0, len(c.PreviousNames)+len(c.Keywords))
+	terms = append(terms, c.PreviousNames...)
+	return append(terms, c.Keywords...)
+}
+
+// ParseKeywords splits a comma-separated keyword list, dropping blanks and
+// repeats.
+func ParseKeywords(s string) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, keyword := range strings.Split(s, ",") {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" || seen[strings.ToLower(keyword)] {
+			continue
+		}
+		seen[strings.ToLower(keyword)] = true
+		keywords = append(keywords, keyword)
+	}
+	return keywords
+}
+
+// Rename changes the entry's name, keeping the old one in PreviousNames. A
+// name already in the history moves to its end rather than repeating.
+func (c *ConfigEntry) Rename(name string) {
+	if name == c.Name {
+		return
+	}
+	var history []string
+	for _, previous := range c.PreviousNames {
+		if !strings.EqualFold(previous, c.Name) && !strings.EqualFold(previous, name) {
+			history = append(history, previous)
+		}
+	}
+	if c.Name != "" {
+		history = append(history, c.Name)
+	}
+	c.PreviousNames = history
+	c.Name = name
+}
+
+// ParseInterval parses a review interval: a count followed by d, w, m
+// (30 days) or y (365 days), or anything time.ParseDuration accepts
+func ParseInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	units := map[byte]time.Duration{
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+		'm': 30 * 24 * time.Hour,
+		'y': 365 * 24 * time.Hour,
+	}
+	if len(s) > 1 {
+		if unit, ok := units[s[len(s)-1]]; ok {
+			if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n > 0 {
+				return time.Duration(n) * unit, nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid interval '%s' (try 90d, 2w, 6m or 1y)", s)
+	}
+	return d, nil
+}
+
+// ReviewDue returns when the entry is next due for review, counting from its
+// last open or, failing that, its registration. ok is false when the entry
+// has no valid review interval, or was neither opened nor dated when added
+// and so has nothing to count from.
+func (c *ConfigEntry) ReviewDue() (due time.Time, ok bool) {
+	if c.ReviewEvery == "" {
+		return time.Time{}, false
+	}
+	interval, err := ParseInterval(c.ReviewEvery)
+	if err != nil {
+		return time.Time{}, false
+	}
+	last := c.LastOpened
+	if last.IsZero() {
+		last = c.Added
+	}
+	if last.IsZero() {
+		return time.Time{}, false
+	}
+	return last.Add(interval), true
+}
+
+// ReviewOverdue reports whether the entry's review is due at now
+func (c *ConfigEntry) ReviewOverdue(now time.Time) bool {
+	due, ok := c.ReviewDue()
+	return ok && !now.Before(due)
+}
+
+// Expired reports whether the entry is a temporary registration whose time
+// is up at now
+func (c *ConfigEntry) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !now.Before(c.Expires)
+}
+
+// ParseExpiry parses when a temporary registration expires: a date
+// (2006-01-02, midnight local time) or an interval from now as accepted by
+// ParseInterval. An empty string means the entry never expires.
+func ParseExpiry(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	d, err := ParseInterval(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry '%s' (try 7d, 2w or a date like 2006-01-02)", s)
+	}
+	return now.Add(d), nil
+}
+
+// FindByAlias returns the entry whose alias is alias, ignoring case
+func FindByAlias(configs []ConfigEntry, alias string) *ConfigEntry {
+	if alias == "" {
+		return nil
+	}
+	for i := range configs {
+		if strings.EqualFold(configs[i].Alias, alias) {
+			return &configs[i]
+		}
 	}
+	return nil
 }
 
 // Equals checks if two entries are the same
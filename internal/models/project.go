@@ -0,0 +1,69 @@
+package models
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Project holds optional metadata for a project name used by entries
+type Project struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Root        string `json:"root,omitempty"`  // files under this directory join the project
+	Color       string `json:"color,omitempty"` // ANSI 0-255 or #rrggbb
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// FindProject returns the metadata for name, matched case-insensitively
+func FindProject(projects []Project, name string) *Project {
+	for i := range projects {
+		if strings.EqualFold(projects[i].Name, name) {
+			return &projects[i]
+		}
+	}
+	return nil
+}
+
+// ProjectForPath returns the project whose root contains path, preferring
+// the most specific root, or "" when none does
+func ProjectForPath(projects []Project, path string) string {
+	best, bestLen := "", 0
+	for _, project := range projects {
+		if project.Root == "" {
+			continue
+		}
+		root := filepath.Clean(project.Root)
+		rel, err := filepath.Rel(root, filepath.Clean(path))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > bestLen {
+			best, bestLen = project.Name, len(root)
+		}
+	}
+	return best
+}
+
+// PathInProject resolves a path typed for a file in project: a relative
+// path is taken as relative to the project's root. Absolute, ~ and remote
+// paths, and paths in projects without a root, are returned unchanged.
+func PathInProject(projects []Project, project, path string) string {
+	p := FindProject(projects, project)
+	if p == nil || p.Root == "" || path == "" || filepath.IsAbs(path) ||
+		strings.HasPrefix(path, "~") || strings.Contains(path, "://") {
+		return path
+	}
+	return filepath.Join(p.Root, path)
+}
+
+// ValidColor reports whether s is empty, an ANSI color number or a hex color
+func ValidColor(s string) bool {
+	if s == "" || hexColor.MatchString(s) {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
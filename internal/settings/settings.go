@@ -0,0 +1,332 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileName is the settings file stored next to the registry
+const FileName = "settings.json"
+
+// Settings holds user preferences that aren't part of the registry itself
+type Settings struct {
+	// Editor is the default editor, used instead of $VISUAL and $EDITOR.
+	Editor string `json:"editor,omitempty"`
+
+	// Editors picks an editor per file type ("markdown": "obsidian"). An
+	// editor remembered on an entry with open-with still wins.
+	Editors map[string]string `json:"editors,omitempty"`
+
+	// EditorKinds says whether an editor is "terminal" (zap hands it the
+	// terminal and waits) or "gui" (started in the background), for editors
+	// zap doesn't know or guesses wrong.
+	EditorKinds map[string]string `json:"editor_kinds,omitempty"`
+
+	// EditorWait passes the wait flag to GUI editors (code --wait, subl -w) so
+	// zap knows when editing finishes, the same as for terminal editors.
+	EditorWait bool `json:"editor_wait,omitempty"`
+
+	// Ignore lists glob patterns for files and directories that scans skip.
+	// Nil means DefaultIgnore; an empty list disables ignoring.
+	Ignore []string `json:"ignore,omitempty"`
+
+	// Watch flags registered files that change while zap is running.
+	Watch bool `json:"watch,omitempty"`
+
+	// Density spaces out the file list: "compact" (the default) shows one
+	// line per row, "comfortable" leaves a blank line between rows.
+	Density string `json:"density,omitempty"`
+
+	// ExpandRow starts with the selected list row spread over two lines,
+	// adding its project, path and description.
+	ExpandRow bool `json:"expand_row,omitempty"`
+
+	// Stripes shades every other row of the file list.
+	Stripes bool `json:"stripes,omitempty"`
+
+	// DateFormat renders timestamps in the details pane and reports: "iso"
+	// (the default), "relative" ("3h ago") or a Go time layout such as
+	// "02.01.2006 15:04".
+	DateFormat string `json:"date_format,omitempty"`
+
+	// Colorblind styles status messages with a palette whose colors stay
+	// distinct with common color vision deficiencies.
+	Colorblind bool `json:"colorblind,omitempty"`
+
+	// Collation is a locale ("de", "sv") whose alphabet rules order names
+	// when sorting. Empty sorts case-insensitively in natural order.
+	Collation string `json:"collation,omitempty"`
+
+	// Sort is a sort expression such as "project asc, type asc, last_opened
+	// desc". When set it is the starting sort and joins the S cycle.
+	Sort string `json:"sort,omitempty"`
+
+	// IgnoreAccents makes search treat accented letters like their base
+	// letter, so "cafe" finds "café".
+	IgnoreAccents bool `json:"ignore_accents,omitempty"`
+
+	// MatchBasename ranks a file's base name as highly as its entry name, so
+	// searching "Caddyfile" finds it whatever the entry is called.
+	MatchBasename bool `json:"match_basename,omitempty"`
+
+	// LargeFileKB is the size in KB above which the preview reads only the
+	// start of a file, and zap cat, copying contents and inline editing
+	// refuse it. Zero means DefaultLargeFileKB.
+	LargeFileKB int `json:"large_file_kb,omitempty"`
+
+	// Columns lays the file list out in columns, in order. Empty shows just
+	// the name.
+	Columns []Column `json:"columns,omitempty"`
+
+	// ColumnPresets replace Columns on narrower terminals, e.g. just name
+	// and path up to 80 cells wide.
+	ColumnPresets []ColumnPreset `json:"column_presets,omitempty"`
+
+	// Shared is a team registry (for example checked into a repo) shown
+	// read-only underneath the personal one.
+	Shared string `json:"shared,omitempty"`
+
+	// RelativePaths saves paths to files in the git checkout holding the
+	// registry relative to the registry's directory, for a registry checked
+	// into a repo and cloned elsewhere.
+	RelativePaths bool `json:"relative_paths,omitempty"`
+
+	// Workspaces are named views that show only some projects and tags.
+	Workspaces map[string]Workspace `json:"workspaces,omitempty"`
+
+	// Confirm chooses which actions ask before going ahead.
+	Confirm Confirmations `json:"confirm,omitempty"`
+}
+
+// Confirmations turns the questions zap asks before an action on or off.
+// Unset keys keep the default behaviour.
+type Confirmations struct {
+	Delete *bool `json:"delete,omitempty"` // D asks y/n (default on)
+	Open   *bool `json:"open,omitempty"`   // entries marked confirm_open ask before opening (default on)
+	Run    *bool `json:"run,omitempty"`    // run commands ask first (default only actions that change the system)
+	Bulk   *bool `json:"bulk,omitempty"`   // ctrl+x asks for the count to be typed (default on)
+}
+
+// ConfirmDelete reports whether deleting an entry asks first
+func (s Settings) ConfirmDelete() bool {
+	return s.Confirm.Delete == nil || *s.Confirm.Delete
+}
+
+// ConfirmOpen reports whether entries marked confirm_open ask before opening
+func (s Settings) ConfirmOpen() bool {
+	return s.Confirm.Open == nil || *s.Confirm.Open
+}
+
+// ConfirmBulk reports whether deleting everything a filter shows asks first
+func (s Settings) ConfirmBulk() bool {
+	return s.Confirm.Bulk == nil || *s.Confirm.Bulk
+}
+
+// ConfirmRun reports whether a command asks before running. Unset, only
+// commands that change system state ask.
+func (s Settings) ConfirmRun(changesSystem bool) bool {
+	if s.Confirm.Run == nil {
+		return changesSystem
+	}
+	return *s.Confirm.Run
+}
+
+// TypeEditor returns the editor configured for fileType, or "" when there
+// is none. Types are compared case-insensitively.
+func (s Settings) TypeEditor(fileType string) string {
+	for t, editor := range s.Editors {
+		if strings.EqualFold(t, fileType) {
+			return editor
+		}
+	}
+	return ""
+}
+
+// Comfortable reports whether the list uses the comfortable density
+func (s Settings) Comfortable() bool {
+	return strings.EqualFold(s.Density, "comfortable")
+}
+
+// Workspace selects the entries belonging to any of its projects or tags
+type Workspace struct {
+	Projects []string `json:"projects,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// Includes reports whether an entry with the given project and tags belongs
+// to the workspace. Names are compared case-insensitively.
+func (w Workspace) Includes(project string, tags []string) bool {
+	for _, p := range w.Projects {
+		if strings.EqualFold(p, project) {
+			return true
+		}
+	}
+	for _, want := range w.Tags {
+		for _, tag := range tags {
+			if strings.EqualFold(want, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WorkspaceNames returns the configured workspace names, sorted
+func (s Settings) WorkspaceNames() []string {
+	names := make([]string, 0, len(s.Workspaces))
+	for name := range s.Workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Column is one column of the file list: an entry field and its width in
+// cells. Columns without a width share the room the others leave.
+type Column struct {
+	Field string `json:"field"`
+	Width int    `json:"width,omitempty"`
+}
+
+// ColumnFields are the entry fields a column can show, in the order they
+// are offered when adding one
+var ColumnFields = []string{"name", "project", "type", "path", "description", "alias", "last_opened", "added", "open_count", "size", "tags"}
+
+// ColumnPreset is the file list's columns for terminals up to MaxWidth
+// cells wide.
+type ColumnPreset struct {
+	MaxWidth int      `json:"max_width"`
+	Columns  []Column `json:"columns"`
+}
+
+// ColumnPresetFor returns the index of the narrowest preset that covers a
+// terminal width cells wide, or -1 when it is wider than all of them and
+// Columns apply.
+func (s Settings) ColumnPresetFor(width int) int {
+	best := -1
+	for i, p := range s.ColumnPresets {
+		if width <= p.MaxWidth && (best < 0 || p.MaxWidth < s.ColumnPresets[best].MaxWidth) {
+			best = i
+		}
+	}
+	return best
+}
+
+// ColumnsFor returns the list columns for a terminal width cells wide: its
+// preset's, or the configured columns.
+func (s Settings) ColumnsFor(width int) []Column {
+	if i := s.ColumnPresetFor(width); i >= 0 {
+		return normalizeColumns(s.ColumnPresets[i].Columns)
+	}
+	return s.ListColumns()
+}
+
+// ListColumns returns the configured columns with unknown fields and
+// repeats dropped, or just the name when none are left.
+func (s Settings) ListColumns() []Column {
+	return normalizeColumns(s.Columns)
+}
+
+func normalizeColumns(configured []Column) []Column {
+	var columns []Column
+	seen := make(map[string]bool)
+	for _, c := range configured {
+		field := strings.ToLower(strings.TrimSpace(c.Field))
+		if seen[field] || !isColumnField(field) {
+			continue
+		}
+		seen[field] = true
+		columns = append(columns, Column{Field: field, Width: max(c.Width, 0)})
+	}
+	if len(columns) == 0 {
+		return []Column{{Field: "name"}}
+	}
+	return columns
+}
+
+func isColumnField(field string) bool {
+	for _, f := range ColumnFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultLargeFileKB is used when the settings file doesn't set large_file_kb
+const DefaultLargeFileKB = 1024
+
+// LargeFileBytes returns the large file threshold in bytes
+func (s Settings) LargeFileBytes() int64 {
+	if s.LargeFileKB <= 0 {
+		return DefaultLargeFileKB * 1024
+	}
+	return int64(s.LargeFileKB) * 1024
+}
+
+// DefaultIgnore is used when the settings file doesn't set ignore
+var DefaultIgnore = []string{"node_modules", ".git", "vendor", "*.lock"}
+
+// IgnorePatterns returns the configured ignore globs or the defaults
+func (s Settings) IgnorePatterns() []string {
+	if s.Ignore == nil {
+		return DefaultIgnore
+	}
+	return s.Ignore
+}
+
+// PathFor returns the settings file path that belongs to a registry file
+func PathFor(registryPath string) string {
+	return filepath.Join(filepath.Dir(registryPath), FileName)
+}
+
+// Load reads settings from disk, returning defaults if the file doesn't exist
+func Load(path string) (Settings, error) {
+	var s Settings
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+
+	return s, nil
+}
+
+// Set writes one setting to the settings file at path, leaving the others
+// as they are. Keys are written in sorted order.
+func Set(path, key string, value any) error {
+	fields := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read settings file: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("failed to parse settings file: %w", err)
+		}
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	fields[key] = raw
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
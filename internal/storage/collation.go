@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Collation orders strings when sorting configs. The zero value compares
+// case-insensitively in natural order; a locale collation follows that
+// language's alphabet, so Swedish puts å, ä and ö after z while German
+// sorts ä with a.
+type Collation struct {
+	collator *collate.Collator
+}
+
+// NewCollation returns the collation for a BCP 47 locale such as "de" or
+// "sv-SE". An empty locale gives the default natural order.
+func NewCollation(locale string) (Collation, error) {
+	if locale == "" {
+		return Collation{}, nil
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return Collation{}, fmt.Errorf("invalid collation locale '%s': %w", locale, err)
+	}
+	return Collation{collator: collate.New(tag, collate.IgnoreCase, collate.Numeric)}, nil
+}
+
+// Less reports whether a sorts before b
+func (c Collation) Less(a, b string) bool {
+	return c.Compare(a, b) < 0
+}
+
+// Compare returns -1, 0 or 1 as a sorts before, with or after b
+func (c Collation) Compare(a, b string) int {
+	if c.collator == nil {
+		return naturalCompare(strings.ToLower(a), strings.ToLower(b))
+	}
+	return c.collator.CompareString(a, b)
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+)
+
+func TestCollationFollowsLocaleAlphabet(t *testing.T) {
+	configs := []models.ConfigEntry{{Name: "öl"}, {Name: "zebra"}, {Name: "apa"}}
+	names := func(c Collation) string {
+		var out []string
+		for _, config := range SortByName(configs, c) {
+			out = append(out, config.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	swedish, err := NewCollation("sv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(swedish); got != "apa,zebra,öl" {
+		t.Fatalf("sv order = %s, want ö after z", got)
+	}
+	german, _ := NewCollation("de")
+	if got := names(german); got != "apa,öl,zebra" {
+		t.Fatalf("de order = %s, want ö with o", got)
+	}
+	if _, err := NewCollation("not a locale!"); err == nil {
+		t.Fatal("invalid locale accepted")
+	}
+}
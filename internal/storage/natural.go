@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NaturalLess compares strings case-insensitively, treating runs of digits
+// as numbers so "server2" sorts before "server10"
+func NaturalLess(a, b string) bool {
+	return naturalCompare(strings.ToLower(a), strings.ToLower(b)) < 0
+}
+
+// naturalCompare returns -1, 0 or 1 as a sorts before, with or after b
+func naturalCompare(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si, sj := i, j
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			// Compare the numbers without leading zeros: longer is larger,
+			// then digit by digit.
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+			if len(na) != len(nb) {
+				return compareInts(len(na), len(nb))
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			// Equal values: fewer leading zeros first, so "1" < "01".
+			if c := compareInts(i-si, j-sj); c != 0 {
+				return c
+			}
+			continue
+		}
+		if ra[i] != rb[j] {
+			return compareInts(int(ra[i]), int(rb[j]))
+		}
+		i++
+		j++
+	}
+	return compareInts(len(ra)-i, len(rb)-j)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+)
+
+func TestSortByNameUsesNaturalOrder(t *testing.T) {
+	configs := []models.ConfigEntry{{Name: "server10"}, {Name: "Server2"}, {Name: "server1"}, {Name: "server02"}, {Name: "alpha"}}
+	var got []string
+	for _, config := range SortByName(configs, Collation{}) {
+		got = append(got, config.Name)
+	}
+	want := []string{"alpha", "server1", "Server2", "server02", "server10"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SortByName = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+)
+
+// SortKey is one field of a sort expression
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// SortSpec is a user-defined sort order, compared key by key
+type SortSpec []SortKey
+
+// sortFields compares two entries on each field a sort expression may use
+var sortFields = map[string]func(a, b *models.ConfigEntry, c Collation) int{
+	"name":        func(a, b *models.ConfigEntry, c Collation) int { return c.Compare(a.Name, b.Name) },
+	"project":     func(a, b *models.ConfigEntry, c Collation) int { return c.Compare(projectName(a), projectName(b)) },
+	"type":        func(a, b *models.ConfigEntry, c Collation) int { return c.Compare(a.Type, b.Type) },
+	"path":        func(a, b *models.ConfigEntry, c Collation) int { return c.Compare(a.Path, b.Path) },
+	"description": func(a, b *models.ConfigEntry, c Collation) int { return c.Compare(a.Description, b.Description) },
+	"last_opened": func(a, b *models.ConfigEntry, _ Collation) int { return compareTimes(a.LastOpened, b.LastOpened) },
+	"added":       func(a, b *models.ConfigEntry, _ Collation) int { return compareTimes(a.Added, b.Added) },
+	"open_count":  func(a, b *models.ConfigEntry, _ Collation) int { return compareInts(a.OpenCount, b.OpenCount) },
+	"size":        func(a, b *models.ConfigEntry, _ Collation) int { return compareInts(int(a.Size), int(b.Size)) },
+}
+
+// ParseSortSpec parses a sort expression such as
+// "project asc, type asc, last_opened desc". Keys default to ascending.
+func ParseSortSpec(expr string) (SortSpec, error) {
+	var spec SortSpec
+	for _, part := range strings.Split(expr, ",") {
+		fields := strings.Fields(strings.ToLower(part))
+		if len(fields) == 0 {
+			continue
+		}
+		key := SortKey{Field: fields[0]}
+		if _, ok := sortFields[key.Field]; !ok {
+			return nil, fmt.Errorf("unknown sort field '%s' (use %s)", key.Field, strings.Join(SortFieldNames(), ", "))
+		}
+		switch {
+		case len(fields) == 1 || (len(fields) == 2 && fields[1] == "asc"):
+		case len(fields) == 2 && fields[1] == "desc":
+			key.Desc = true
+		default:
+			return nil, fmt.Errorf("invalid sort key '%s' (expected '<field> [asc|desc]')", strings.TrimSpace(part))
+		}
+		spec = append(spec, key)
+	}
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("empty sort expression")
+	}
+	return spec, nil
+}
+
+// SortFieldNames returns the fields a sort expression may use, sorted
+func SortFieldNames() []string {
+	names := make([]string, 0, len(sortFields))
+	for name := range sortFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String formats the spec the way ParseSortSpec reads it
+func (s SortSpec) String() string {
+	parts := make([]string, len(s))
+	for i, key := range s {
+		parts[i] = key.Field
+		if key.Desc {
+			parts[i] += " desc"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SortBySpec sorts configs by each key of spec in turn, comparing text
+// with c. Entries equal on every key keep their registry order.
+func SortBySpec(configs []models.ConfigEntry, spec SortSpec, c Collation) []models.ConfigEntry {
+	sorted := make([]models.ConfigEntry, len(configs))
+	copy(sorted, configs)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, key := range spec {
+			cmp := sortFields[key.Field](&sorted[i], &sorted[j], c)
+			if key.Desc {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	return sorted
+}
+
+// projectName returns the project an entry is listed under
+func projectName(c *models.ConfigEntry) string {
+	if c.Project == "" {
+		return "General"
+	}
+	return c.Project
+}
+
+func compareTimes(a, b time.Time) int {
+	return a.Compare(b)
+}
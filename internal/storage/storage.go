@@ -7,13 +7,28 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/LFroesch/zap/internal/models"
 )
 
+// ActivityDateFormat is the day key used in the activity log
+const ActivityDateFormat = "2006-01-02"
+
+// activityRetention is how long daily open counts are kept
+const activityRetention = 366 * 24 * time.Hour
+
+// maxDeleted is how many removed entries are kept for restoring
+const maxDeleted = 10
+
 // Storage handles config file persistence
 type Storage struct {
-	filePath string
+	filePath  string
+	projects  []models.Project
+	activity  map[string]int // carried across saves alongside the configs
+	deleted   []models.DeletedEntry
+	onboarded bool
+	relative  bool // save paths inside the registry's repo relative to it
 }
 
 // New creates a new Storage instance
@@ -41,12 +56,109 @@ func (s *Storage) Load() ([]models.ConfigEntry, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	dir := filepath.Dir(s.filePath)
+	for i := range manager.Configs {
+		manager.Configs[i].Path = resolvePath(dir, manager.Configs[i].Path)
+	}
+	for i := range manager.Projects {
+		manager.Projects[i].Root = resolvePath(dir, manager.Projects[i].Root)
+	}
+	for i := range manager.Deleted {
+		manager.Deleted[i].Path = resolvePath(dir, manager.Deleted[i].Path)
+	}
+
+	s.projects = manager.Projects
+	s.activity = manager.Activity
+	s.deleted = manager.Deleted
+	s.onboarded = manager.Onboarded
 	return manager.Configs, nil
 }
 
-// Save writes configs to disk atomically
+// resolvePath makes a path stored relative to the registry's directory
+// absolute. Absolute and ~ paths are returned unchanged.
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) || path == "~" || strings.HasPrefix(path, "~/") {
+		return path
+	}
+	return filepath.Join(dir, filepath.FromSlash(path))
+}
+
+// repoRoot returns the root of the git checkout containing dir, or "" when
+// dir isn't inside one.
+func repoRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// SetRelativePaths turns on saving paths inside the git checkout holding the
+// registry relative to the registry's directory. It is off by default so a
+// dotfiles repo around the personal registry doesn't get it rewritten.
+func (s *Storage) SetRelativePaths(on bool) {
+	s.relative = on
+}
+
+// relativizer returns a function that rewrites paths inside the repo holding
+// the registry relative to the registry's directory, so a checked-in
+// registry keeps working wherever the repo is cloned. Unless relative paths
+// were turned on, or outside a repo, paths are left absolute.
+func (s *Storage) relativizer() func(string) string {
+	unchanged := func(path string) string { return path }
+	if !s.relative {
+		return unchanged
+	}
+	dir, err := filepath.Abs(filepath.Dir(s.filePath))
+	root := repoRoot(dir)
+	if err != nil || root == "" {
+		return unchanged
+	}
+	return func(path string) string {
+		if !filepath.IsAbs(path) {
+			return path
+		}
+		if inRoot, err := filepath.Rel(root, path); err != nil || inRoot == ".." || strings.HasPrefix(inRoot, ".."+string(filepath.Separator)) {
+			return path
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return path
+		}
+		return filepath.ToSlash(rel)
+	}
+}
+
+// Save writes configs to disk atomically. Shared entries are left out.
 func (s *Storage) Save(configs []models.ConfigEntry) error {
-	manager := models.ConfigManager{Configs: configs}
+	relative := s.relativizer()
+	local := make([]models.ConfigEntry, 0, len(configs))
+	for _, config := range configs {
+		if !config.Shared {
+			config.Path = relative(config.Path)
+			local = append(local, config)
+		}
+	}
+	projects := make([]models.Project, len(s.projects))
+	for i, project := range s.projects {
+		project.Root = relative(project.Root)
+		projects[i] = project
+	}
+	deleted := make([]models.DeletedEntry, len(s.deleted))
+	for i, entry := range s.deleted {
+		entry.Path = relative(entry.Path)
+		deleted[i] = entry
+	}
+	manager := models.ConfigManager{Configs: local, Projects: projects, Activity: s.activity, Onboarded: s.onboarded, Deleted: deleted}
 
 	data, err := json.MarshalIndent(manager, "", "  ")
 	if err != nil {
@@ -72,8 +184,105 @@ func (s *Storage) Save(configs []models.ConfigEntry) error {
 	return nil
 }
 
-// SortConfigs sorts configs by project then name
-func SortConfigs(configs []models.ConfigEntry) []models.ConfigEntry {
+// CopyTo returns a Storage for filePath holding the same projects, activity,
+// deletions, tour state and relative-path setting as s, for working on a
+// scratch copy of the registry. Nothing is written until its first Save.
+func (s *Storage) CopyTo(filePath string) *Storage {
+	activity := make(map[string]int, len(s.activity))
+	for day, n := range s.activity {
+		activity[day] = n
+	}
+	return &Storage{
+		filePath:  filePath,
+		projects:  s.Projects(),
+		activity:  activity,
+		deleted:   append([]models.DeletedEntry(nil), s.deleted...),
+		onboarded: s.onboarded,
+		relative:  s.relative,
+	}
+}
+
+// Projects returns the project metadata loaded with the registry
+func (s *Storage) Projects() []models.Project {
+	return append([]models.Project(nil), s.projects...)
+}
+
+// SetProjects replaces the project metadata written by the next Save
+func (s *Storage) SetProjects(projects []models.Project) {
+	s.projects = append([]models.Project(nil), projects...)
+}
+
+// Onboarded reports whether the first-run tour has been finished or skipped
+func (s *Storage) Onboarded() bool {
+	return s.onboarded
+}
+
+// SetOnboarded marks the first-run tour done. It is persisted by the next Save.
+func (s *Storage) SetOnboarded(done bool) {
+	s.onboarded = done
+}
+
+// RecordActivity counts an open on t's day. It is persisted by the next Save.
+func (s *Storage) RecordActivity(t time.Time) {
+	if s.activity == nil {
+		s.activity = make(map[string]int)
+	}
+	s.activity[t.Format(ActivityDateFormat)]++
+
+	cutoff := t.Add(-activityRetention).Format(ActivityDateFormat)
+	for day := range s.activity {
+		if day < cutoff {
+			delete(s.activity, day)
+		}
+	}
+}
+
+// RecordDeleted remembers configs as removed at t, keeping the most recent
+// maxDeleted removals. Shared entries are skipped. It is persisted by the
+// next Save; the returned func forgets the removals again if that fails.
+func (s *Storage) RecordDeleted(configs []models.ConfigEntry, t time.Time) (undo func()) {
+	previous := s.deleted
+	for _, config := range configs {
+		if !config.Shared {
+			s.deleted = append(s.deleted, models.DeletedEntry{ConfigEntry: config, Deleted: t})
+		}
+	}
+	if len(s.deleted) > maxDeleted {
+		s.deleted = append([]models.DeletedEntry(nil), s.deleted[len(s.deleted)-maxDeleted:]...)
+	}
+	return func() { s.deleted = previous }
+}
+
+// Deleted returns the remembered removals, most recent first
+func (s *Storage) Deleted() []models.DeletedEntry {
+	deleted := make([]models.DeletedEntry, len(s.deleted))
+	for i, entry := range s.deleted {
+		deleted[len(s.deleted)-1-i] = entry
+	}
+	return deleted
+}
+
+// ForgetDeleted drops the i-th removal as numbered by Deleted, once it has
+// been restored. It is persisted by the next Save.
+func (s *Storage) ForgetDeleted(i int) {
+	if i < 0 || i >= len(s.deleted) {
+		return
+	}
+	at := len(s.deleted) - 1 - i
+	s.deleted = append(s.deleted[:at:at], s.deleted[at+1:]...)
+}
+
+// Activity returns the number of opens per day, keyed by ActivityDateFormat
+func (s *Storage) Activity() map[string]int {
+	activity := make(map[string]int, len(s.activity))
+	for day, count := range s.activity {
+		activity[day] = count
+	}
+	return activity
+}
+
+// SortConfigs sorts configs by project then name using c
+func SortConfigs(configs []models.ConfigEntry, c Collation) []models.ConfigEntry {
 	sorted := make([]models.ConfigEntry, len(configs))
 	copy(sorted, configs)
 
@@ -90,11 +299,11 @@ func SortConfigs(configs []models.ConfigEntry) []models.ConfigEntry {
 
 		// First sort by project
 		if !strings.EqualFold(projectI, projectJ) {
-			return strings.ToLower(projectI) < strings.ToLower(projectJ)
+			return c.Less(projectI, projectJ)
 		}
 
 		// If projects are the same, sort by name
-		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+		return c.Less(sorted[i].Name, sorted[j].Name)
 	})
 
 	return sorted
@@ -112,43 +321,43 @@ func SortByRecentlyOpened(configs []models.ConfigEntry) []models.ConfigEntry {
 	return sorted
 }
 
-// SortByName sorts configs alphabetically by name
-func SortByName(configs []models.ConfigEntry) []models.ConfigEntry {
+// SortByName sorts configs by name using c
+func SortByName(configs []models.ConfigEntry, c Collation) []models.ConfigEntry {
 	sorted := make([]models.ConfigEntry, len(configs))
 	copy(sorted, configs)
 
 	sort.Slice(sorted, func(i, j int) bool {
-		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+		return c.Less(sorted[i].Name, sorted[j].Name)
 	})
 
 	return sorted
 }
 
-// SortByType sorts configs by file type, then by name
-func SortByType(configs []models.ConfigEntry) []models.ConfigEntry {
+// SortByType sorts configs by file type, then by name, using c
+func SortByType(configs []models.ConfigEntry, c Collation) []models.ConfigEntry {
 	sorted := make([]models.ConfigEntry, len(configs))
 	copy(sorted, configs)
 
 	sort.Slice(sorted, func(i, j int) bool {
 		// First sort by type
 		if !strings.EqualFold(sorted[i].Type, sorted[j].Type) {
-			return strings.ToLower(sorted[i].Type) < strings.ToLower(sorted[j].Type)
+			return c.Less(sorted[i].Type, sorted[j].Type)
 		}
 
 		// If types are the same, sort by name
-		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+		return c.Less(sorted[i].Name, sorted[j].Name)
 	})
 
 	return sorted
 }
 
-// SortByPath sorts configs alphabetically by full path
-func SortByPath(configs []models.ConfigEntry) []models.ConfigEntry {
+// SortByPath sorts configs by full path using c
+func SortByPath(configs []models.ConfigEntry, c Collation) []models.ConfigEntry {
 	sorted := make([]models.ConfigEntry, len(configs))
 	copy(sorted, configs)
 
 	sort.Slice(sorted, func(i, j int) bool {
-		return strings.ToLower(sorted[i].Path) < strings.ToLower(sorted[j].Path)
+		return c.Less(sorted[i].Path, sorted[j].Path)
 	})
 
 	return sorted
@@ -171,6 +380,34 @@ func (s *Storage) GetFilePath() string {
 	return s.filePath
 }
 
+// MergeShared layers shared entries underneath local ones: each shared entry
+// is marked Shared and added unless a local entry overrides it by having the
+// same path. ~ in either path is treated as home.
+func MergeShared(local, shared []models.ConfigEntry) []models.ConfigEntry {
+	overridden := make(map[string]bool, len(local))
+	for _, config := range local {
+		overridden[expandHome(config.Path)] = true
+	}
+	merged := append([]models.ConfigEntry(nil), local...)
+	for _, config := range shared {
+		if overridden[expandHome(config.Path)] {
+			continue
+		}
+		config.Shared = true
+		merged = append(merged, config)
+	}
+	return merged
+}
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
 // FindDuplicates checks if a config with the same path already exists
 func FindDuplicates(configs []models.ConfigEntry, path string) *models.ConfigEntry {
 	for i := range configs {
@@ -7,7 +7,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-const helpReservedLines = 4
+const helpReservedLines = 5
 
 func helpPanelStyle() lipgloss.Style {
 	return lipgloss.NewStyle().
@@ -21,29 +21,95 @@ func helpLines() []string {
 		"Navigation",
 		"j/k, up/down        Navigate left file list",
 		"g/G                 First/last item",
+		"z                   Expand the selected row to two lines",
+		"p                   Show or hide the file preview",
+		"L                   Arrange list columns (saved to settings.json)",
+		"left/right          Scroll list columns that don't fit (name stays)",
 		"ctrl+d/u            Half-page scroll",
 		"J/K                 Scroll right preview pane",
 		"pgup/pgdn           Page scroll right preview pane",
 		"",
 		"Actions",
-		"enter/o             Open file in external editor",
+		"enter/o             Open file (or all marked files) in editor",
+		"ctrl+o              Quick open: type, enter, editor launches",
+		"1/2/3               Open recently opened file",
+		"space               Mark/unmark file",
+		"esc                 Clear marks, or skip the first-run tour",
+		"v                   View file read-only in $PAGER",
 		"E                   Edit selected file inline",
 		"O                   Open parent directory in editor",
+		"W                   Open with another editor (remembered)",
 		"e                   Edit selected file metadata",
+		"P                   Edit project description, root and color",
+		"t                   Edit tags (adds tags to marked files)",
+		"b                   Switch registry (n in the list creates one)",
+		"x                   Run the file's check command",
+		"c                   Toggle auto-check: rerun it whenever the file changes",
+		"d                   Diff the file against its source URL or path",
+		"V                   Show the file's pass/1Password secret, masked",
+		"!                   Toggle asking y/n before opening the file",
+		"l                   Lock/unlock the file read-only",
+		"X                   Actions for the file's type",
+		"@                   Open at a section (ssh host, ini section, hosts line)",
 		"N                   Add new file",
+		"ctrl+v              Add the path on the clipboard",
+		"I                   Import from a directory scan, bookmarks or path list",
+		"I, ctrl+r           Import recently edited files (VS Code, shell history)",
+		"a                   Archive/restore file (or marked files)",
 		"D                   Delete file",
+		"ctrl+x              Delete all files the filter shows (type the count)",
 		"y                   Copy path to clipboard",
+		"Y                   Copy file contents to clipboard",
 		"r                   Refresh list",
+		"i                   Registry stats, activity heatmap, restore deleted",
 		"",
 		"Search & Sort",
-		"/                   Search",
+		"/                   Search: words AND, -word NOT, a OR b, \"phrase\"",
+		"ctrl+f              Toggle fuzzy search (ranked by use)",
 		"S                   Cycle sort mode",
+		"<, >                Sort by previous/next column",
+		"~                   Reverse the column sort",
+		"R                   Toggle files due for review",
+		"U                   Toggle files never opened",
+		"T                   Show only files with one of some tags",
+		"tab/shift+tab       Switch workspace",
+		"A                   Show/hide archived files",
+		"M                   Show/hide missing files",
 		"",
 		"Edit Mode",
 		"tab/shift+tab       Next/previous field",
+		"right               Accept completion",
+		"up/down             Cycle completions / choose type",
 		"enter               Save",
 		"esc                 Cancel",
 		"",
+		"Log View (enter on a log entry)",
+		"j/k, pgup/pgdn      Scroll",
+		"f                   Toggle follow",
+		"o                   Open in editor instead",
+		"esc                 Close",
+		"",
+		"Column Arrangement (L)",
+		"left/right          Pick a column",
+		"shift+left/right    Move it",
+		"+/-, 0              Resize it, or back to automatic width",
+		"a/x                 Add/remove a column",
+		"enter               Save to settings.json",
+		"esc                 Discard changes",
+		"",
+		"Secret Pane (V)",
+		"space               Reveal/hide the secret",
+		"y                   Copy it to the clipboard",
+		"x                   Run the check command with $ZAP_SECRET",
+		"esc                 Close and forget the value",
+		"",
+		"Import Preview",
+		"space               Toggle file",
+		"a                   Toggle all visible",
+		"/                   Filter by path",
+		"enter               Import selected",
+		"esc                 Cancel",
+		"",
 		"Inline File Edit",
 		"ctrl+s              Save file",
 		"ctrl+d              Delete current line",
@@ -72,7 +138,8 @@ func HelpBodyHeight(totalHeight int) int {
 }
 
 // HelpPanel renders a bounded help view that preserves the app header/footer.
-func HelpPanel(width, height, scroll int) string {
+// footer is shown on the last line, for the version and build details.
+func HelpPanel(width, height, scroll int, footer string) string {
 	titleStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("214")).
 		Bold(true)
@@ -150,6 +217,7 @@ func HelpPanel(width, height, scroll int) string {
 		strings.TrimRight(body.String(), "\n"),
 		"",
 		footerStyle.Render(scrollHint),
+		footerStyle.Render(footer),
 	)
 
 	return panelStyle.
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// previewStyle is the chroma style the file preview is colored with. Only
+// its foreground colors are used, so the preview keeps the panel background.
+var previewStyle = styles.Get("monokai")
+
+// lexerNames maps registry types to chroma lexers where the names differ
+var lexerNames = map[string]string{
+	"compose": "yaml",
+	"shell":   "bash",
+}
+
+// lexerFor picks the lexer for a file of the given type, falling back to one
+// matching the file name for types chroma doesn't know, such as ssh.
+func lexerFor(fileType, path string) chroma.Lexer {
+	if fileType == "" || fileType == "txt" || fileType == "log" {
+		return nil
+	}
+	name := fileType
+	if alias, ok := lexerNames[fileType]; ok {
+		name = alias
+	}
+	if lexer := lexers.Get(name); lexer != nil {
+		return lexer
+	}
+	return lexers.Match(filepath.Base(path))
+}
+
+// Highlight colors the lines of a file of the given type for the preview.
+// Types without a lexer, such as txt and log, are returned unchanged.
+func Highlight(fileType, path string, lines []string) []string {
+	lexer := lexerFor(fileType, path)
+	if lexer == nil || len(lines) == 0 {
+		return lines
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, strings.Join(lines, "\n")+"\n")
+	if err != nil {
+		return lines
+	}
+
+	out := make([]string, 0, len(lines))
+	for _, tokens := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		var b strings.Builder
+		for _, token := range tokens {
+			b.WriteString(renderToken(token))
+		}
+		out = append(out, b.String())
+	}
+	if len(out) != len(lines) {
+		// The lexer merged or split lines; show them plain rather than shifted
+		return lines
+	}
+	return out
+}
+
+// renderToken colors one token with the style's foreground, bold and italic.
+func renderToken(token chroma.Token) string {
+	text := strings.TrimSuffix(token.Value, "\n")
+	entry := previewStyle.Get(token.Type)
+	if text == "" || strings.TrimSpace(text) == "" || !entry.Colour.IsSet() {
+		return text
+	}
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(entry.Colour.String())).
+		Bold(entry.Bold == chroma.Yes).
+		Italic(entry.Italic == chroma.Yes).
+		TabWidth(lipgloss.NoTabConversion)
+	return style.Render(text)
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestHighlightKeepsTheText(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI256)
+
+	for _, tc := range []struct{ fileType, path, text string }{
+		{"yaml", "app.yaml", "services:\n  - name: web # front\n    image: \"nginx:1.27\"\n    note: it's fine"},
+		{"compose", "docker-compose.yml", "services:\n  web:\n    ports: [\"80:80\"]"},
+		{"ini", "php.ini", "[server]\nport = 8080 ; default\nname=zap"},
+		{"json", "package.json", "{\n  \"name\": \"zap\",\n  \"debug\": true\n}"},
+		{"go", "main.go", "func main() {\n\tfmt.Println(\"// not a comment\") // comment\n}"},
+		{"markdown", "README.md", "# Title\n\n- item\n```\ncode\n```\n> don't quote"},
+		{"shell", "deploy.sh", "#!/bin/sh\n# don't run twice\necho \"$HOME\"\n"},
+		{"dockerfile", "Dockerfile", "FROM golang:1.23\nRUN go build ./..."},
+		{"ssh", "config", "Host web\n  HostName 10.0.0.1"},
+	} {
+		lines := strings.Split(tc.text, "\n")
+		got := Highlight(tc.fileType, tc.path, lines)
+		if len(got) != len(lines) {
+			t.Fatalf("%s: %d lines highlighted, want %d", tc.fileType, len(got), len(lines))
+		}
+		colored := false
+		for i := range lines {
+			if plain := xansi.Strip(got[i]); plain != lines[i] {
+				t.Errorf("%s line %d = %q, want %q", tc.fileType, i+1, plain, lines[i])
+			}
+			colored = colored || got[i] != lines[i]
+		}
+		if !colored && tc.fileType != "ssh" {
+			t.Errorf("%s: nothing was highlighted", tc.fileType)
+		}
+	}
+}
+
+func TestHighlightLeavesPlainTypesAlone(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI256)
+
+	lines := []string{"2024-05-10 error: it's \"broken\"", "# not a heading"}
+	for _, fileType := range []string{"txt", "log", ""} {
+		got := Highlight(fileType, "notes", lines)
+		if strings.Join(got, "\n") != strings.Join(lines, "\n") {
+			t.Errorf("%q preview = %q, want it unchanged", fileType, got)
+		}
+	}
+}
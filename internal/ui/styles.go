@@ -60,33 +60,67 @@ var (
 			Bold(true)
 )
 
-// GetStatusStyle returns the appropriate style based on message content
-func GetStatusStyle(message string) lipgloss.Style {
-	switch {
-	case contains(message, "❌", "Failed", "Error", "Not found"):
-		return ErrorStyle
-	case contains(message, "⚠️", "Warning"):
-		return WarningStyle
-	case contains(message, "ℹ️", "Info"):
-		return InfoStyle
-	default:
-		return SuccessStyle
+// RowStyle returns the style of an unselected list row. With striped set,
+// every other row gets a faint background so wide lists are easier to follow
+// across the screen.
+func RowStyle(index int, striped bool) lipgloss.Style {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	if striped && index%2 == 1 {
+		style = style.Background(lipgloss.Color("236"))
 	}
+	return style
 }
 
-func contains(s string, substrs ...string) bool {
-	for _, substr := range substrs {
-		if lipgloss.NewStyle().Render(s) == lipgloss.NewStyle().Render(substr) ||
-		   len(s) > 0 && len(substr) > 0 && s[0:min(len(substr), len(s))] == substr[0:min(len(substr), len(s))] {
-			return true
-		}
+// StatusLevel says what kind of news a status message carries, so it is
+// styled by meaning instead of by its wording
+type StatusLevel int
+
+const (
+	StatusInfo StatusLevel = iota
+	StatusSuccess
+	StatusWarn
+	StatusError
+)
+
+// colorblindColors is the Okabe-Ito palette, whose hues stay distinct with
+// the common forms of color blindness
+var colorblindColors = map[StatusLevel]string{
+	StatusInfo:    "#56B4E9",
+	StatusSuccess: "#009E73",
+	StatusWarn:    "#E69F00",
+	StatusError:   "#D55E00",
+}
+
+var statusColors = map[StatusLevel]string{
+	StatusInfo:    ColorText,
+	StatusSuccess: ColorSuccess,
+	StatusWarn:    ColorWarning,
+	StatusError:   ColorDanger,
+}
+
+// StatusStyle returns the style for a status message of the given level
+func StatusStyle(level StatusLevel, colorblind bool) lipgloss.Style {
+	colors := statusColors
+	if colorblind {
+		colors = colorblindColors
+	}
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(colors[level])).Inline(true)
+	if level == StatusWarn || level == StatusError {
+		style = style.Bold(true)
 	}
-	return false
+	return style
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// StatusMarker is the symbol shown before a message of the given level, so
+// warnings and errors stand out without relying on color
+func StatusMarker(level StatusLevel) string {
+	switch level {
+	case StatusSuccess:
+		return "✓ "
+	case StatusWarn:
+		return "⚠ "
+	case StatusError:
+		return "✗ "
 	}
-	return b
+	return ""
 }
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+)
+
+// Date formats accepted by FormatTime besides a Go time layout
+const (
+	DateISO      = "iso"
+	DateRelative = "relative"
+)
+
+// FormatTime renders t in format: "iso" (the default), "relative" ("3h ago",
+// "in 2d") or a Go time layout such as "02.01.2006 15:04". With dateOnly
+// the ISO format leaves out the time of day; layouts are used as given.
+func FormatTime(t, now time.Time, format string, dateOnly bool) string {
+	switch format {
+	case "", DateISO:
+		if dateOnly {
+			return t.Format("2006-01-02")
+		}
+		return t.Format("2006-01-02 15:04")
+	case DateRelative:
+		return relativeTime(t, now)
+	default:
+		return t.Format(format)
+	}
+}
+
+// relativeTime describes the distance between t and now in the largest
+// whole unit, from minutes up to years.
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	suffix := " ago"
+	if d < 0 {
+		d = -d
+		suffix = ""
+	}
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 14*24*time.Hour:
+		amount = fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		amount = fmt.Sprintf("%dw", int(d.Hours()/(24*7)))
+	default:
+		amount = fmt.Sprintf("%dy", int(d.Hours()/(24*365)))
+	}
+	if suffix == "" {
+		return "in " + amount
+	}
+	return amount + suffix
+}
@@ -0,0 +1,97 @@
+package watch
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ChangedMsg is sent when a watched file is written, created, removed or
+// renamed
+type ChangedMsg struct {
+	Path string
+}
+
+// Watcher reports changes to a set of files. It watches their parent
+// directories so files replaced by editors' atomic saves are still seen.
+type Watcher struct {
+	fs    *fsnotify.Watcher
+	mu    sync.Mutex
+	files map[string]bool // read by the goroutine running Next
+	dirs  map[string]bool
+}
+
+// New starts watching paths, which must already be expanded
+func New(paths []string) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{fs: fsw, dirs: make(map[string]bool)}
+	w.Sync(paths)
+	return w, nil
+}
+
+// Sync updates the watched set to paths. Directories that can't be watched
+// (for example because they don't exist) are skipped.
+func (w *Watcher) Sync(paths []string) {
+	files := make(map[string]bool, len(paths))
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		files[path] = true
+		dirs[filepath.Dir(path)] = true
+	}
+
+	for dir := range w.dirs {
+		if !dirs[dir] {
+			w.fs.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	for dir := range dirs {
+		if !w.dirs[dir] && w.fs.Add(dir) == nil {
+			w.dirs[dir] = true
+		}
+	}
+	w.mu.Lock()
+	w.files = files
+	w.mu.Unlock()
+}
+
+func (w *Watcher) watching(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.files[path]
+}
+
+// Next returns a command that waits for the next change to a watched file.
+// Call it again after each ChangedMsg to keep listening.
+func (w *Watcher) Next() tea.Cmd {
+	return func() tea.Msg {
+		for {
+			select {
+			case event, ok := <-w.fs.Events:
+				if !ok {
+					return nil
+				}
+				path := filepath.Clean(event.Name)
+				if w.watching(path) && !event.Has(fsnotify.Chmod) {
+					return ChangedMsg{Path: path}
+				}
+			case _, ok := <-w.fs.Errors:
+				if !ok {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
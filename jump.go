@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// jumpTarget is a place inside a file the editor can be opened at.
+type jumpTarget struct {
+	label string
+	line  int // 1-based
+}
+
+// jumpState is the open section list for an entry.
+type jumpState struct {
+	config  models.ConfigEntry
+	targets []jumpTarget
+	cursor  int // index into the filtered targets
+}
+
+// sshHosts lists the Host and Match blocks of an ssh config.
+func sshHosts(data string) []jumpTarget {
+	var targets []jumpTarget
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		// ssh_config allows "Keyword value" and "Keyword=value".
+		line := strings.TrimSpace(scanner.Text())
+		end := strings.IndexAny(line, " \t=")
+		if end < 0 {
+			continue
+		}
+		keyword := strings.ToLower(line[:end])
+		value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[end:]), "="))
+		if (keyword == "host" || keyword == "match") && value != "" {
+			targets = append(targets, jumpTarget{label: line[:end] + " " + value, line: n})
+		}
+	}
+	return targets
+}
+
+// iniSections lists the [section] headers of an ini or toml file.
+func iniSections(data string) []jumpTarget {
+	var targets []jumpTarget
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && len(line) > 2 {
+			targets = append(targets, jumpTarget{label: line, line: n})
+		}
+	}
+	return targets
+}
+
+// hostsEntries lists the address lines of a hosts file, labelled by the
+// names they map.
+func hostsEntries(data string) []jumpTarget {
+	var targets []jumpTarget
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		label := strings.Join(fields[1:], " ") + "  (" + fields[0] + ")"
+		targets = append(targets, jumpTarget{label: label, line: n})
+	}
+	return targets
+}
+
+// jumpTargetsFor returns the sections of config's file that can be jumped
+// to, or nil when its type has none.
+func jumpTargetsFor(config models.ConfigEntry) ([]jumpTarget, error) {
+	var parse func(string) []jumpTarget
+	switch {
+	case isKind(config, "ssh"):
+		parse = sshHosts
+	case isKind(config, "hosts"):
+		parse = hostsEntries
+	case isKind(config, "ini"), isKind(config, "toml"):
+		parse = iniSections
+	default:
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(editor.ExpandPath(config.Path))
+	if err != nil {
+		return nil, err
+	}
+	return parse(string(data)), nil
+}
+
+// startJump lists the sections of config for picking where to open it.
+func (m *model) startJump(config models.ConfigEntry) tea.Cmd {
+	targets, err := jumpTargetsFor(config)
+	if err != nil {
+		return showError(fmt.Sprintf("Cannot read %s: %v", config.Name, err))
+	}
+	if len(targets) == 0 {
+		return showWarning(fmt.Sprintf("No sections found in %s", config.Name))
+	}
+
+	m.jump = jumpState{
+		config:  config,
+		targets: append([]jumpTarget{{label: "Top of file", line: 1}}, targets...),
+	}
+	m.textInput.SetSuggestions(nil)
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = "filter"
+	m.textInput.Focus()
+	m.mode = ModeJump
+	return nil
+}
+
+// filteredTargets returns the targets whose label contains the filter.
+func (m model) filteredTargets() []jumpTarget {
+	filter := strings.ToLower(strings.TrimSpace(m.textInput.Value()))
+	if filter == "" {
+		return m.jump.targets
+	}
+	var targets []jumpTarget
+	for _, target := range m.jump.targets {
+		if strings.Contains(strings.ToLower(target.label), filter) {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+func (m *model) closeJump() {
+	m.mode = ModeNormal
+	m.jump = jumpState{}
+	m.textInput.Blur()
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = ""
+}
+
+func (m model) updateJump(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	targets := m.filteredTargets()
+	switch msg.String() {
+	case "esc":
+		m.closeJump()
+		return m, nil
+	case "up", "ctrl+p", "ctrl+k":
+		if m.jump.cursor > 0 {
+			m.jump.cursor--
+		}
+		return m, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if m.jump.cursor < len(targets)-1 {
+			m.jump.cursor++
+		}
+		return m, nil
+	case "enter":
+		if len(targets) == 0 {
+			return m, nil
+		}
+		target := targets[m.jump.cursor]
+		config := m.jump.config
+		m.closeJump()
+		return m, m.openConfigAt(config, target.line)
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	m.jump.cursor = 0
+	return m, cmd
+}
+
+// renderJumpPanel lists the sections in place of the file list.
+func (m model) renderJumpPanel() string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("117")).
+		Padding(0, 1)
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+	innerHeight := m.mainContentHeight() - panelStyle.GetVerticalFrameSize()
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render("Open " + m.jump.config.Name + " at")
+	lines := []string{title, "", m.textInput.View(), ""}
+
+	targets := m.filteredTargets()
+	if len(targets) == 0 {
+		lines = append(lines, suitechrome.Dim("No matching sections"))
+	}
+	visible := max(innerHeight-len(lines), 1)
+	start := 0
+	if m.jump.cursor >= visible {
+		start = m.jump.cursor - visible + 1
+	}
+	for i := start; i < len(targets) && i < start+visible; i++ {
+		number := fmt.Sprintf("%5d  ", targets[i].line)
+		label := truncate(targets[i].label, max(innerWidth-len(number), 3))
+		if i == m.jump.cursor {
+			lines = append(lines, lipgloss.NewStyle().
+				Foreground(lipgloss.Color("230")).
+				Background(lipgloss.Color("62")).
+				Width(innerWidth).
+				Render(number+label))
+			continue
+		}
+		lines = append(lines, suitechrome.Dim(number)+label)
+	}
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(innerHeight).
+		Render(strings.Join(lines, "\n"))
+}
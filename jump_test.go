@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSSHHostsListsHostAndMatchBlocks(t *testing.T) {
+	data := "# global\nServerName x\n\nHost web-1 web-2\n  HostName 10.0.0.1\n\n  host=db\nMatch host *.internal\nHost\n"
+	got := sshHosts(data)
+	want := []jumpTarget{
+		{label: "Host web-1 web-2", line: 4},
+		{label: "host db", line: 7},
+		{label: "Match host *.internal", line: 8},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("sshHosts = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSSHConfigIsDetected(t *testing.T) {
+	for _, path := range []string{"~/.ssh/config", "/etc/ssh/sshd_config"} {
+		if got := models.DetectFileType(path); got != "ssh" {
+			t.Errorf("DetectFileType(%q) = %q, want ssh", path, got)
+		}
+	}
+	if got := models.DetectFileType("/app/config"); got == "ssh" {
+		t.Errorf("plain config file detected as ssh")
+	}
+}
+
+func TestIniSectionsAndHostsEntries(t *testing.T) {
+	sections := iniSections("; top\nname = x\n\n[server]\nport = 80\n  [database.replica]  \n[]\n")
+	if len(sections) != 2 || sections[0] != (jumpTarget{label: "[server]", line: 4}) || sections[1] != (jumpTarget{label: "[database.replica]", line: 6}) {
+		t.Errorf("iniSections = %+v", sections)
+	}
+
+	hosts := hostsEntries("# static\n127.0.0.1 localhost\n\n10.0.0.5  db db.internal # primary\n10.0.0.9\n")
+	if len(hosts) != 2 || hosts[1] != (jumpTarget{label: "db db.internal  (10.0.0.5)", line: 4}) {
+		t.Errorf("hostsEntries = %+v", hosts)
+	}
+	if got := models.DetectFileType("/etc/hosts"); got != "hosts" {
+		t.Errorf("DetectFileType(/etc/hosts) = %q, want hosts", got)
+	}
+}
+
+func TestEnterOpensSSHConfigWithoutHosts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(path, []byte("ServerAliveInterval 60\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1,
+		storage: storage.New(filepath.Join(dir, "zap-registry.json")), dryRun: true}
+	m.configs = []models.ConfigEntry{
+		{Name: "ssh", Path: path, Type: "ssh"},
+		{Name: "gone", Path: filepath.Join(dir, "missing", "ssh_config"), Type: "ssh"},
+	}
+	m.buildDisplayList()
+
+	opened := 0
+	for m.cursor = 0; m.cursor < len(m.displayConfigs); m.cursor++ {
+		config := m.getConfigByDisplayIndex(m.cursor)
+		if config == nil {
+			continue
+		}
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if updated.(model).mode != ModeNormal || cmd == nil {
+			t.Fatalf("enter on %s should open the file, mode = %v", config.Name, updated.(model).mode)
+		}
+		opened++
+	}
+	if opened != 2 {
+		t.Fatalf("opened %d entries, want 2", opened)
+	}
+}
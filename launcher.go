@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+)
+
+// scriptFilterItem is one result in Alfred's Script Filter JSON format.
+type scriptFilterItem struct {
+	UID          string           `json:"uid"`
+	Title        string           `json:"title"`
+	Subtitle     string           `json:"subtitle"`
+	Arg          string           `json:"arg"`
+	Type         string           `json:"type"`
+	Valid        bool             `json:"valid"`
+	Match        string           `json:"match"`
+	Autocomplete string           `json:"autocomplete"`
+	Icon         scriptFilterIcon `json:"icon"`
+	QuicklookURL string           `json:"quicklookurl"`
+}
+
+type scriptFilterIcon struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// runScriptFilter prints the entries matching a query as Script Filter JSON,
+// so launchers such as Alfred and Raycast can list and open zap's files
+// directly. Results are ranked like quick open; each item's arg is the
+// expanded path, and missing files are listed but can't be actioned.
+func runScriptFilter(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("script-filter")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	_, configs, prefs, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	matches := quickOpenMatches(configs, strings.Join(rest, " "), time.Now(), searchOptionsFor(prefs))
+
+	items := make([]scriptFilterItem, 0, len(matches))
+	for _, config := range matches {
+		path := editor.ExpandPath(config.Path)
+		subtitle := contractHome(config.Path)
+		if config.Project != "" {
+			subtitle = config.Project + " · " + subtitle
+		}
+		exists := editor.FileExists(config.Path)
+		if !exists {
+			subtitle = "Missing: " + subtitle
+		}
+		match := append([]string{config.Name, config.Alias, config.Project, config.Description}, config.SearchTerms()...)
+		items = append(items, scriptFilterItem{
+			UID:          path,
+			Title:        config.Name,
+			Subtitle:     subtitle,
+			Arg:          path,
+			Type:         "file",
+			Valid:        exists,
+			Match:        strings.Join(strings.Fields(strings.Join(match, " ")), " "),
+			Autocomplete: config.Name,
+			Icon:         scriptFilterIcon{Type: "fileicon", Path: path},
+			QuicklookURL: path,
+		})
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Items []scriptFilterItem `json:"items"`
+	}{items}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = ctx.stdout.Write(append(data, '\n'))
+	return err
+}
+
+// runPick prints the entries matching a query, best first, for piping into a
+// picker: name and path separated by a tab for fzf or rofi, or with
+// --powershell as CSV that ConvertFrom-Csv turns into objects for
+// Out-GridView or PSReadLine.
+func runPick(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("pick")
+	powershell := fs.Bool("powershell", false, "Print CSV for ConvertFrom-Csv | Out-GridView")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	_, configs, prefs, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	matches := quickOpenMatches(configs, strings.Join(rest, " "), time.Now(), searchOptionsFor(prefs))
+
+	if !*powershell {
+		for _, config := range matches {
+			fmt.Fprintf(ctx.stdout, "%s\t%s\n", config.Name, editor.ExpandPath(config.Path))
+		}
+		return nil
+	}
+
+	w := csv.NewWriter(ctx.stdout)
+	w.UseCRLF = true
+	w.Write([]string{"Name", "Project", "Type", "Description", "LastOpened", "Path"})
+	for _, config := range matches {
+		lastOpened := ""
+		if !config.LastOpened.IsZero() {
+			lastOpened = config.LastOpened.Format("2006-01-02 15:04")
+		}
+		w.Write([]string{config.Name, config.Project, config.Type, config.Description, lastOpened, editor.ExpandPath(config.Path)})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+func TestScriptFilterListsMatchesForLaunchers(t *testing.T) {
+	dir := t.TempDir()
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	nginx := filepath.Join(dir, "nginx.conf")
+	if err := os.WriteFile(nginx, []byte("events {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configs := []models.ConfigEntry{
+		{Name: "nginx", Path: nginx, Project: "infra", Keywords: []string{"proxy"}},
+		{Name: "nginx site", Path: filepath.Join(dir, "gone.conf")},
+		{Name: "zshrc", Path: filepath.Join(dir, ".zshrc")},
+	}
+	if err := storage.New(registry).Save(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runScriptFilter(ctx, []string{"ngx"}); err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Items []scriptFilterItem `json:"items"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("items = %+v, want the two nginx entries", result.Items)
+	}
+	for _, item := range result.Items {
+		switch item.Title {
+		case "nginx":
+			if item.Arg != nginx || !item.Valid || !strings.HasPrefix(item.Subtitle, "infra · ") || item.Match != "nginx infra proxy" {
+				t.Fatalf("nginx item = %+v", item)
+			}
+		case "nginx site":
+			if item.Valid {
+				t.Fatalf("a missing file can't be opened: %+v", item)
+			}
+		default:
+			t.Fatalf("unexpected item %+v", item)
+		}
+	}
+}
+
+func TestPickPrintsCSVForPowerShell(t *testing.T) {
+	registry := filepath.Join(t.TempDir(), "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	configs := []models.ConfigEntry{
+		{Name: "profile", Path: "/home/me/profile.ps1", Project: "shell", Description: "aliases, prompt"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+	if err := storage.New(registry).Save(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runPick(ctx, []string{"--powershell", "prof"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Name,Project,Type,Description,LastOpened,Path\r\n" +
+		"profile,shell,,\"aliases, prompt\",,/home/me/profile.ps1\r\n"
+	if out.String() != want {
+		t.Fatalf("CSV = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := runPick(ctx, []string{"zsh"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "zshrc\t/home/me/.zshrc\n" {
+		t.Fatalf("plain pick = %q", out.String())
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+)
+
+// lintIssue is one hygiene problem with a registry entry. fix is nil when
+// the problem needs a human to resolve it.
+type lintIssue struct {
+	index   int
+	message string
+	fix     func(config *models.ConfigEntry) string // applies the fix and describes it
+}
+
+// Placeholders the add form starts with, which are saved as-is when the
+// field isn't edited.
+const (
+	placeholderName        = "New File"
+	placeholderPath        = "~/path/to/file"
+	placeholderDescription = "File description"
+)
+
+// lintConfigs checks every personal entry for placeholder values, stray
+// whitespace, types that detection would improve and duplicate paths.
+// Entries from the shared registry are skipped since they aren't saved here.
+func lintConfigs(configs []models.ConfigEntry) []lintIssue {
+	var issues []lintIssue
+	add := func(i int, message string, fix func(*models.ConfigEntry) string) {
+		issues = append(issues, lintIssue{index: i, message: message, fix: fix})
+	}
+	firstByPath := map[string]int{}
+	mergedByPath := map[string]models.ConfigEntry{} // what compactConfigs will keep
+
+	for i, config := range configs {
+		if config.Shared {
+			continue
+		}
+
+		if config.Name != strings.TrimSpace(config.Name) {
+			add(i, "name has leading or trailing whitespace", func(c *models.ConfigEntry) string {
+				c.Name = strings.TrimSpace(c.Name)
+				return fmt.Sprintf("name → %q", c.Name)
+			})
+		}
+		if name := strings.TrimSpace(config.Name); name == "" || name == placeholderName {
+			add(i, "name is empty or the placeholder", func(c *models.ConfigEntry) string {
+				c.Name = filepath.Base(c.Path)
+				return fmt.Sprintf("name → %q", c.Name)
+			})
+		}
+		if config.Project != strings.TrimSpace(config.Project) {
+			add(i, "project has leading or trailing whitespace", func(c *models.ConfigEntry) string {
+				c.Project = strings.TrimSpace(c.Project)
+				return fmt.Sprintf("project → %q", c.Project)
+			})
+		}
+		if config.Path == placeholderPath {
+			add(i, "path is the placeholder", nil)
+		}
+
+		switch strings.TrimSpace(config.Description) {
+		case placeholderDescription:
+			add(i, "description is the placeholder", func(c *models.ConfigEntry) string {
+				c.Description = ""
+				return "description cleared"
+			})
+		case "":
+			add(i, "description is empty", nil)
+		default:
+			if config.Description != strings.TrimSpace(config.Description) {
+				add(i, "description has leading or trailing whitespace", func(c *models.ConfigEntry) string {
+					c.Description = strings.TrimSpace(c.Description)
+					return "description trimmed"
+				})
+			}
+		}
+
+		if config.Type == "" || config.Type == "txt" {
+			if detected := models.DetectFileType(config.Path); detected != "txt" {
+				add(i, fmt.Sprintf("type is %q but the file looks like %s", config.Type, detected), func(c *models.ConfigEntry) string {
+					c.Type = detected
+					return "type → " + detected
+				})
+			}
+		}
+
+		path := editor.ExpandPath(config.Path)
+		if first, ok := firstByPath[path]; ok {
+			firstName := configs[first].Name
+			merged := mergedByPath[path]
+			if conflicts := duplicateConflicts(merged, config); len(conflicts) > 0 {
+				add(i, fmt.Sprintf("same file as %s, with a different %s", firstName, strings.Join(conflicts, ", ")), nil)
+				continue
+			}
+			mergeDuplicate(&merged, config)
+			mergedByPath[path] = merged
+			// The merge itself happens in compactConfigs once every fix ran
+			add(i, "same file as "+firstName, func(*models.ConfigEntry) string {
+				return "merged into " + firstName
+			})
+			continue
+		}
+		firstByPath[path] = i
+		mergedByPath[path] = config
+	}
+	return issues
+}
+
+// duplicateConflicts names the fields two entries for the same file both
+// set to different values, which merging them would lose.
+func duplicateConflicts(keep, dup models.ConfigEntry) []string {
+	var conflicts []string
+	for _, field := range []struct {
+		name string
+		a, b string
+	}{
+		{"project", keep.Project, dup.Project},
+		{"alias", keep.Alias, dup.Alias},
+		{"description", keep.Description, dup.Description},
+		{"run command", keep.Run, dup.Run},
+		{"review interval", keep.ReviewEvery, dup.ReviewEvery},
+		{"source", keep.Source, dup.Source},
+		{"checksum", keep.Checksum, dup.Checksum},
+		{"secret", keep.Secret, dup.Secret},
+	} {
+		a, b := strings.TrimSpace(field.a), strings.TrimSpace(field.b)
+		if !unset(a) && !unset(b) && a != b {
+			conflicts = append(conflicts, field.name)
+		}
+	}
+	return conflicts
+}
+
+// unset reports whether a text field is empty or still the add form's
+// placeholder.
+func unset(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s == placeholderDescription
+}
+
+// mergeDuplicate folds dup into keep: usage adds up, fields keep leaves
+// empty are taken from dup and tags and keywords are combined. dup's name
+// is remembered so search still finds it.
+func mergeDuplicate(keep *models.ConfigEntry, dup models.ConfigEntry) {
+	keep.OpenCount += dup.OpenCount
+	if dup.LastOpened.After(keep.LastOpened) {
+		keep.LastOpened = dup.LastOpened
+	}
+	if !dup.Added.IsZero() && (keep.Added.IsZero() || dup.Added.Before(keep.Added)) {
+		keep.Added = dup.Added
+	}
+	for _, field := range []struct{ keep, dup *string }{
+		{&keep.Project, &dup.Project},
+		{&keep.Alias, &dup.Alias},
+		{&keep.Description, &dup.Description},
+		{&keep.Run, &dup.Run},
+		{&keep.ReviewEvery, &dup.ReviewEvery},
+		{&keep.Source, &dup.Source},
+		{&keep.Checksum, &dup.Checksum},
+		{&keep.Secret, &dup.Secret},
+		{&keep.Editor, &dup.Editor},
+	} {
+		if unset(*field.keep) {
+			*field.keep = *field.dup
+		}
+	}
+	if keep.Expires.IsZero() || dup.Expires.IsZero() {
+		keep.Expires = time.Time{}
+	} else if dup.Expires.After(keep.Expires) {
+		keep.Expires = dup.Expires
+	}
+	keep.ConfirmOpen = keep.ConfirmOpen || dup.ConfirmOpen
+	keep.ReadOnly = keep.ReadOnly || dup.ReadOnly
+	keep.AutoCheck = keep.AutoCheck || dup.AutoCheck
+	keep.Archived = keep.Archived && dup.Archived
+	keep.Tags = models.ParseKeywords(strings.Join(append(append([]string(nil), keep.Tags...), dup.Tags...), ","))
+	keep.Keywords = models.ParseKeywords(strings.Join(append(append([]string(nil), keep.Keywords...), dup.Keywords...), ","))
+	previous := append([]string(nil), keep.PreviousNames...)
+	for _, name := range append(append([]string(nil), dup.PreviousNames...), dup.Name) {
+		if name != keep.Name && !containsString(previous, name) {
+			previous = append(previous, name)
+		}
+	}
+	keep.PreviousNames = previous
+}
+
+// compactConfigs removes entries that repeat an earlier entry's file,
+// merging them into the one that is kept. Duplicates that conflict with it
+// stay for the user to sort out. It returns the removed entries.
+func compactConfigs(configs []models.ConfigEntry) (kept, removed []models.ConfigEntry) {
+	firstByPath := map[string]int{}
+	for _, config := range configs {
+		if config.Shared {
+			kept = append(kept, config)
+			continue
+		}
+		path := editor.ExpandPath(config.Path)
+		first, ok := firstByPath[path]
+		if !ok {
+			firstByPath[path] = len(kept)
+			kept = append(kept, config)
+			continue
+		}
+		if len(duplicateConflicts(kept[first], config)) > 0 {
+			kept = append(kept, config)
+			continue
+		}
+		mergeDuplicate(&kept[first], config)
+		removed = append(removed, config)
+	}
+	return kept, removed
+}
+
+func runLint(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("lint")
+	fix := fs.Bool("fix", false, "Apply the automatic fixes and merge duplicate entries")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageError("lint [--fix]")
+	}
+
+	store, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+
+	issues := lintConfigs(configs)
+	unresolved, fixable := 0, 0
+	for _, issue := range issues {
+		config := &configs[issue.index]
+		fmt.Fprintf(ctx.stdout, "%s  %s\n", config.Name, issue.message)
+		switch {
+		case issue.fix != nil && *fix:
+			fmt.Fprintf(ctx.stdout, "  fixed: %s\n", issue.fix(config))
+			continue
+		case issue.fix != nil:
+			fixable++
+		}
+		unresolved++
+	}
+
+	if *fix && unresolved < len(issues) {
+		var removed []models.ConfigEntry
+		configs, removed = compactConfigs(configs)
+		store.RecordDeleted(removed, time.Now())
+		if err := store.Save(configs); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(ctx.stdout, "%d entries checked, %d issues\n", len(configs), unresolved)
+	if fixable > 0 {
+		fmt.Fprintf(ctx.stdout, "run zap lint --fix to fix %d of them\n", fixable)
+	}
+	if unresolved > 0 {
+		return invalidf("%d lint issues", unresolved)
+	}
+	return nil
+}
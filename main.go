@@ -5,45 +5,136 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
 	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/internal/ui"
+	"github.com/LFroesch/zap/internal/watch"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-var version = "dev"
-
 func main() {
+	if len(os.Args) > 1 && isCLICommand(os.Args[1]) {
+		os.Exit(runCLICommand(os.Args[1], os.Args[2:]))
+	}
+
 	showVersion := flag.Bool("version", false, "Print version and exit")
+	query := flag.String("q", "", "Start with this search query applied")
+	openFirst := flag.Bool("open-first", false, "Open the top match for the query in the editor and exit")
+	quiet := flag.Bool("quiet", false, "Suppress error messages from --open-first")
+	workspace := flag.String("workspace", "", "Start in this workspace from settings.json")
+	watchFiles := flag.Bool("watch", false, "Flag registered files that change while zap is open")
+	demo := flag.Bool("demo", false, "Try zap on a temporary registry of example files, leaving yours untouched")
+	registry := flag.String("registry", "", "Use this named registry (or registry file) instead of the default")
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "zap — Lightning-fast TUI file registry for developers\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: zap [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: zap [flags] [query]\n       zap <command> [args]\n\n")
+		printCommandUsage(os.Stderr)
+		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flag.PrintDefaults()
 	}
-	flag.Parse()
+	args, err := parseInterspersed(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		os.Exit(exitInvalid)
+	}
 	if *showVersion {
-		fmt.Println("zap " + version)
-		os.Exit(0)
+		os.Exit(runCLICommand("version", nil))
+	}
+	if err := useRegistry(*registry); err != nil {
+		fmt.Fprintf(os.Stderr, "zap: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if *query == "" && len(args) > 0 {
+		*query = strings.Join(args, " ")
+	}
+
+	var (
+		store   *storage.Storage
+		configs []models.ConfigEntry
+		prefs   settings.Settings
+		cleanup = func() {} // removes the --demo registry before exiting
+	)
+	if *demo {
+		if store, configs, cleanup, err = setupDemo(); err != nil {
+			log.Fatal(err)
+		}
+	} else if store, configs, prefs, err = loadRegistry(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *watchFiles {
+		prefs.Watch = true
+	}
+	m := newModel(store, configs, prefs)
+	if *workspace != "" {
+		if _, ok := prefs.Workspaces[*workspace]; !ok {
+			fmt.Fprintf(os.Stderr, "zap: unknown workspace %q\n", *workspace)
+			cleanup()
+			os.Exit(exitInvalid)
+		}
+		m.workspace = *workspace
+		m.buildDisplayList()
+		m.refreshRightViewport()
+	}
+	if *query != "" {
+		m.searchQuery = *query
+		m.searchInput.SetValue(*query)
+		m.buildDisplayList()
+		m.refreshRightViewport()
 	}
 
-	configFile, err := resolveRegistryPath()
+	if *openFirst {
+		err := m.openFirstMatch()
+		if err != nil && !*quiet {
+			fmt.Fprintf(os.Stderr, "zap: %v\n", err)
+		}
+		cleanup()
+		os.Exit(exitCode(err))
+	}
+
+	err = runTUI(m)
+	cleanup()
 	if err != nil {
 		log.Fatal(err)
 	}
+}
 
-	store := storage.New(configFile)
-	configs, err := loadConfigs(store)
+// runTUI runs the interactive program until the user quits.
+func runTUI(m model) error {
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	final, err := p.Run()
+	// The watcher may have been replaced while running, so close the final one
+	if m, ok := final.(model); ok && m.watcher != nil {
+		m.watcher.Close()
+	}
 	if err != nil {
-		log.Fatalf("Failed to load configs: %v", err)
+		return err
+	}
+	// Save opens still waiting for their delayed flush
+	if m, ok := final.(model); ok {
+		if err := m.flushUsage(); err != nil {
+			fmt.Fprintf(os.Stderr, "zap: failed to save usage: %v\n", err)
+		}
 	}
+	return nil
+}
 
+// newModel builds the initial TUI state for a loaded registry.
+func newModel(store *storage.Storage, configs []models.ConfigEntry, prefs settings.Settings) model {
 	m := model{
 		configs:      configs,
+		projects:     store.Projects(),
 		storage:      store,
-		editor:       store.GetEditor(),
+		settings:     prefs,
+		editor:       defaultEditor(store, prefs),
 		width:        100,
 		height:       24,
 		mode:         ModeNormal,
@@ -54,11 +145,15 @@ func main() {
 		deleteIndex:  -1,
 		cacheValid:   false,
 		sortMode:     0, // Start with Project sort
+		expandRow:    prefs.ExpandRow,
+		tutorial:     !store.Onboarded() && len(configs) == 0,
 	}
 
 	// Initialize text inputs
 	m.textInput = textinput.New()
 	m.textInput.CharLimit = 300
+	m.textInput.ShowSuggestions = true
+	m.textInput.KeyMap.AcceptSuggestion = key.NewBinding(key.WithKeys("right"))
 
 	m.fileEditArea = textarea.New()
 	m.fileEditArea.CharLimit = 0
@@ -69,16 +164,92 @@ func main() {
 
 	m.rightViewport = viewport.New(40, 10)
 
+	if collation, err := storage.NewCollation(prefs.Collation); err != nil {
+		m.statusMsg = err.Error()
+		m.statusLevel = ui.StatusError
+		m.statusExpiry = time.Now().Add(5 * time.Second)
+	} else {
+		m.collation = collation
+	}
+	if prefs.Sort != "" {
+		if spec, err := storage.ParseSortSpec(prefs.Sort); err != nil {
+			m.statusMsg = fmt.Sprintf("Ignoring sort setting: %v", err)
+			m.statusLevel = ui.StatusError
+			m.statusExpiry = time.Now().Add(5 * time.Second)
+		} else {
+			m.sortSpec = spec
+			m.sortMode = sortCustom
+		}
+	}
+
+	if prefs.Watch || m.hasAutoCheck() {
+		watcher, err := watch.New(m.watchPaths())
+		if err != nil {
+			m.statusMsg = fmt.Sprintf("File watching unavailable: %v", err)
+			m.statusLevel = ui.StatusWarn
+			m.statusExpiry = time.Now().Add(5 * time.Second)
+		} else {
+			m.watcher = watcher
+		}
+	}
+
 	// Build initial display list
 	m.buildDisplayList()
 	m.refreshRightViewport()
+	m.offerExpiredCleanup()
+	return m
+}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+// openFirstMatch opens the top entry of the current list without starting
+// the TUI.
+func (m *model) openFirstMatch() error {
+	config := m.getConfigByDisplayIndex(m.cursor)
+	if config == nil {
+		if m.searchQuery != "" {
+			return notFoundf("no entries match %q", m.searchQuery)
+		}
+		return notFoundf("no files registered")
+	}
+
+	target := *config
+	if needsConfirmation([]models.ConfigEntry{target}, m.settings) {
+		ctx := &cliContext{stdin: os.Stdin, stderr: os.Stderr, interactive: isTerminal(os.Stdin) && isTerminal(os.Stderr)}
+		if err := confirmOpen(ctx, target); err != nil {
+			return err
+		}
+	}
+	editorCmd := m.editorFor(target)
+	if err := launchEntry(target, editorCmd, m.editorOptions(editorCmd)); err != nil {
+		return fmt.Errorf("open %s: %w", target.Name, err)
+	}
+	config.RecordOpen(time.Now())
+	m.storage.RecordActivity(time.Now())
+	return m.storage.Save(m.configs)
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments and returns the positional arguments. Everything after -- is
+// positional, even if it looks like a flag.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		if parsed := len(args) - fs.NArg(); parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, fs.Args()...), nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
 	}
 }
 
 func (m model) Init() tea.Cmd {
+	if m.watcher != nil {
+		return tea.Batch(tea.SetWindowTitle("zap - File Registry"), m.watcher.Next())
+	}
 	return tea.SetWindowTitle("zap - File Registry")
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestParseInterspersedAcceptsFlagsAfterQuery(t *testing.T) {
+	fs := flag.NewFlagSet("zap", flag.ContinueOnError)
+	openFirst := fs.Bool("open-first", false, "")
+
+	args, err := parseInterspersed(fs, []string{"nginx", "--open-first", "conf"})
+	if err != nil {
+		t.Fatalf("parseInterspersed error = %v", err)
+	}
+	if !*openFirst {
+		t.Fatal("expected --open-first after the query to be parsed")
+	}
+	if want := []string{"nginx", "conf"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("positional args = %q, want %q", args, want)
+	}
+}
+
+func TestParseInterspersedKeepsArgumentsAfterDashDash(t *testing.T) {
+	fs := flag.NewFlagSet("zap", flag.ContinueOnError)
+	openFirst := fs.Bool("open-first", false, "")
+
+	args, err := parseInterspersed(fs, []string{"nginx", "--", "--open-first", "-v", "conf", "--"})
+	if err != nil {
+		t.Fatalf("parseInterspersed error = %v", err)
+	}
+	if *openFirst {
+		t.Fatal("--open-first after -- should be a query word, not a flag")
+	}
+	if want := []string{"nginx", "--open-first", "-v", "conf", "--"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("positional args = %q, want %q", args, want)
+	}
+}
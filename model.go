@@ -4,7 +4,10 @@ import (
 	"time"
 
 	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
 	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/internal/ui"
+	"github.com/LFroesch/zap/internal/watch"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -22,14 +25,41 @@ const (
 	ModeSearch
 	ModeHelp
 	ModeConfirmDelete
+	ModeConfirmQuit
+	ModeOpenWith
+	ModeImportDir
+	ModeImport
+	ModeStats
+	ModeProjectEdit
+	ModeTail
+	ModeRunResult
+	ModeActions
+	ModeJump
+	ModeQuickOpen
+	ModeSecret
+	ModeColumns
+	ModeConfirmBatchDelete
+	ModeConfirmOpen
+	ModeTagEdit
+	ModeTagFilter
+	ModeRegistryPicker
 )
 
+// recentStripSize is how many recently opened files get a number key.
+const recentStripSize = 3
+
+// editFieldCount is the number of metadata fields cycled through in edit mode:
+// Name, Project, Path, Type, Description, Review, Run, Alias, Source, Secret,
+// Keywords, Expires.
+const editFieldCount = 12
+
 type model struct {
-	configs []models.ConfigEntry
-	storage *storage.Storage
-	editor  string
-	width   int
-	height  int
+	configs  []models.ConfigEntry
+	storage  *storage.Storage
+	settings settings.Settings
+	editor   string
+	width    int
+	height   int
 
 	// Navigation
 	cursor       int
@@ -45,22 +75,110 @@ type model struct {
 	editRow       int
 	editCol       int
 	textInput     textinput.Model
+	typeCursor    int                // highlighted option while picking a Type
+	editOriginal  string             // field value when the edit input was loaded
+	draft         models.ConfigEntry // pending changes, saved as a whole on enter
+	draftDirty    bool               // draft differs from the stored entry
 	fileEditArea  textarea.Model
 	fileEditPath  string
 	fileEditLabel string
+	fileEditOrig  string // file content when inline editing started
+
+	// Project metadata
+	projects  []models.Project
+	projDraft models.Project // project being edited
+	projCol   int            // index into projectFieldNames
 
 	// Search mode
-	searchInput textinput.Model
-	searchQuery string
-	fuzzyMode   bool
+	searchInput  textinput.Model
+	searchQuery  string
+	fuzzyMode    bool
+	overdueOnly  bool     // show only entries whose review is due
+	unopenedOnly bool     // show only entries that have never been opened
+	workspace    string   // active workspace name, "" for everything
+	tagFilter    []string // show only entries with one of these tags
+
+	// Visibility toggles, combined with search and workspace
+	showArchived bool
+	hideMissing  bool
+
+	// File watching, keyed by expanded path
+	watcher *watch.Watcher
+	watched []string               // paths last passed to watcher.Sync
+	changed map[string]bool        // files modified since zap started
+	checks  map[string]*checkState // latest automatic check of auto_check entries
+	exists  map[string]bool        // cached file checks, cleared by r and watcher events
+
+	// Multi-select, keyed by entry path
+	marked map[string]bool
+
+	// Directory import preview
+	imp importState
+
+	// Log follow view
+	tail    tailState
+	tailGen int // bumped each time the view opens
+
+	// Run command results and the action menu feeding them
+	run     runState
+	runGen  int // bumped for each run so late results are recognised
+	actions actionState
+
+	// Section list for opening a file at a given line
+	jump jumpState
+
+	// Secret looked up from pass or 1Password, held only while shown
+	secret secretState
+
+	// Tag editor and tag filter prompt
+	tags tagState
+
+	// Registry switcher
+	registries registryPickerState
+
+	// List column arrangement, and how many columns ←/→ scrolled out of view
+	columns   columnState
+	colOffset int
+
+	// Quick-open launcher; its query lives in textInput
+	quickOpenCursor int
+
+	// Stats dashboard
+	stats       registryStats
+	statsScroll int
 
 	// Delete confirmation
 	deleteIndex int
+	batchDelete batchDeleteState
+
+	// Open confirmation for entries marked confirm_open
+	pendingOpen pendingOpen
+
+	// Quit confirmation
+	quitReturnMode ViewMode // mode to resume if quitting is cancelled
 
 	// UI state
 	statusMsg    string
+	statusLevel  ui.StatusLevel
 	statusExpiry time.Time
 
+	// expandRow shows the selected row on two lines
+	expandRow bool
+
+	// hidePreview leaves the file preview out of the details panel
+	hidePreview bool
+
+	// usageDirty is set while recorded opens wait for flushUsage
+	usageDirty bool
+
+	// dryRun reports opens and commands without starting an editor or
+	// running them, for zap script
+	dryRun bool
+
+	// First-run tour
+	tutorial     bool
+	tutorialStep int // next step to teach, see tutorialSteps
+
 	// Display data
 	displayConfigs []displayConfig // Flattened list with headers
 	rightViewport  viewport.Model
@@ -68,13 +186,18 @@ type model struct {
 	// Performance
 	sortedCache []models.ConfigEntry
 	cacheValid  bool
-	sortMode    int // 0=Project, 1=Recent, 2=Name, 3=Type, 4=Path
+	sortMode    int // 0=Project, 1=Recent, 2=Name, 3=Path, 4=Custom, 5=Column
+	sortSpec    storage.SortSpec
+	sortCol     int  // index into sortColumns while sortMode is sortColumn
+	sortReverse bool // flips the column sort's direction
+	collation   storage.Collation
 }
 
 // displayConfig represents a row in the display (either a header or a config)
 type displayConfig struct {
 	isHeader    bool
 	headerText  string
+	project     string // project name shown by a header
 	config      *models.ConfigEntry
 	configIndex int // Index in m.configs (-1 for headers)
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+)
+
+// maxOpenChoices is how many matches zap open lists when the query is
+// ambiguous.
+const maxOpenChoices = 9
+
+// runOpen opens the entry a query names. An exact name or alias opens
+// directly; otherwise the query is fuzzy-matched like quick open, and when
+// several entries match zap asks which (or, without a terminal, lists them).
+func runOpen(ctx *cliContext, args []string) error {
+	const usage = "open [--first] [--yes] <query>"
+	fs := ctx.flagSet("open")
+	first := fs.Bool("first", false, "Open the best match instead of asking which")
+	yes := fs.Bool("yes", false, "Open entries marked confirm_open without asking")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usageError(usage)
+	}
+	query := strings.Join(rest, " ")
+
+	store, configs, prefs, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	matches := openMatches(configs, query, prefs)
+	if len(matches) == 0 {
+		return notFoundf("no entry matches %q", query)
+	}
+
+	target := matches[0]
+	if len(matches) > 1 && !*first {
+		if target, err = pickMatch(ctx, query, matches); err != nil {
+			return err
+		}
+	}
+
+	if needsConfirmation([]models.ConfigEntry{target}, prefs) && !*yes {
+		if err := confirmOpen(ctx, target); err != nil {
+			return err
+		}
+	}
+
+	editorCmd := preferredEditor(target, prefs, defaultEditor(store, prefs))
+	if err := launchEntry(target, editorCmd, editor.Options{Wait: prefs.EditorWait, Kind: editorKind(prefs, editorCmd)}); err != nil {
+		return fmt.Errorf("open %s: %w", target.Name, err)
+	}
+	now := time.Now()
+	for i := range configs {
+		if configs[i].Equals(&target) {
+			configs[i].RecordOpen(now)
+		}
+	}
+	store.RecordActivity(now)
+	return store.Save(configs)
+}
+
+// openMatches returns the entries query could mean, best first: just the
+// entry it names exactly, or else the fuzzy matches ranked by use.
+func openMatches(configs []models.ConfigEntry, query string, prefs settings.Settings) []models.ConfigEntry {
+	if config, err := resolveEntry(configs, query, true); err == nil {
+		return []models.ConfigEntry{config}
+	}
+	return quickOpenMatches(configs, query, time.Now(), searchOptionsFor(prefs))
+}
+
+// pickMatch lists the best matches numbered and, on a terminal, reads which
+// one to open. Elsewhere the list goes to stdout and the query is reported
+// as ambiguous.
+func pickMatch(ctx *cliContext, query string, matches []models.ConfigEntry) (models.ConfigEntry, error) {
+	shown := matches[:min(len(matches), maxOpenChoices)]
+	out := ctx.stdout
+	if ctx.interactive {
+		out = ctx.stderr
+	}
+	width := 0
+	for _, config := range shown {
+		width = max(width, len(config.Name))
+	}
+	for i, config := range shown {
+		fmt.Fprintf(out, "%d  %-*s  %s\n", i+1, width, config.Name, config.Path)
+	}
+	if len(matches) > len(shown) {
+		fmt.Fprintf(out, "   … and %d more; refine the query to see them\n", len(matches)-len(shown))
+	}
+	if !ctx.interactive {
+		return models.ConfigEntry{}, ambiguousf("%d entries match %q; pick one or use --first", len(matches), query)
+	}
+
+	fmt.Fprintf(out, "Open which? [1-%d] ", len(shown))
+	line := ctx.readLine()
+	if line == "" {
+		return models.ConfigEntry{}, ambiguousf("nothing picked")
+	}
+	n, err := strconv.Atoi(line)
+	if err != nil || n < 1 || n > len(shown) {
+		return models.ConfigEntry{}, invalidf("%q is not one of 1-%d", line, len(shown))
+	}
+	return shown[n-1], nil
+}
+
+// confirmOpen asks before opening an entry marked confirm_open. Without a
+// terminal to ask on the open is refused.
+func confirmOpen(ctx *cliContext, config models.ConfigEntry) error {
+	if !ctx.interactive {
+		return invalidf("%s asks before opening and there is no terminal to ask on (zap open --yes skips the question)", config.Name)
+	}
+	fmt.Fprintf(ctx.stderr, "Open %s (%s)? [y/N] ", config.Name, contractHome(config.Path))
+	if answer := strings.ToLower(ctx.readLine()); answer != "y" && answer != "yes" {
+		return fmt.Errorf("%s not opened", config.Name)
+	}
+	return nil
+}
+
+// launchEntry opens config outside the TUI: in the editor's view mode when
+// the entry is read-only, or in the pager when the editor has no such mode.
+func launchEntry(config models.ConfigEntry, editorCmd string, opts editor.Options) error {
+	if config.ReadOnly {
+		if !editor.SupportsReadOnly(editorCmd) {
+			return editor.LaunchPager(config.Path)
+		}
+		opts.ReadOnly = true
+	}
+	return editor.Launch([]string{config.Path}, editorCmd, opts)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+func TestOpenAsksWhichWhenSeveralEntriesMatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses the true command as the editor")
+	}
+	dir := t.TempDir()
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "true")
+	var configs []models.ConfigEntry
+	for _, name := range []string{"nginx.conf", "nginx site", "zshrc"} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-"))
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		configs = append(configs, models.ConfigEntry{Name: name, Path: path})
+	}
+	store := storage.New(registry)
+	if err := store.Save(configs); err != nil {
+		t.Fatal(err)
+	}
+	opens := func() map[string]int {
+		saved, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts := make(map[string]int)
+		for _, config := range saved {
+			counts[config.Name] = config.OpenCount
+		}
+		return counts
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	err := runOpen(ctx, []string{"ngx"})
+	if exitCode(err) != exitAmbiguous || !strings.Contains(out.String(), "1  nginx") || !strings.Contains(out.String(), "2  nginx") {
+		t.Fatalf("ambiguous open without a terminal: %v\n%s", err, out.String())
+	}
+	if got := opens(); got["nginx.conf"]+got["nginx site"] != 0 {
+		t.Fatalf("nothing should open, counts = %v", got)
+	}
+
+	out.Reset()
+	ctx.interactive = true
+	ctx.stdin = strings.NewReader("2\n")
+	if err := runOpen(ctx, []string{"ngx"}); err != nil {
+		t.Fatalf("picking a match: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "Open which? [1-2]") {
+		t.Fatalf("picker prompt missing:\n%s", out.String())
+	}
+	picked := "nginx.conf"
+	if strings.HasPrefix(strings.Split(out.String(), "\n")[1], "2  nginx site") {
+		picked = "nginx site"
+	}
+	if got := opens(); got[picked] != 1 {
+		t.Fatalf("%s was listed second and should be opened, counts = %v\n%s", picked, got, out.String())
+	}
+
+	// The entry just opened now ranks first, so --first takes it again.
+	if err := runOpen(ctx, []string{"--first", "ngx"}); err != nil {
+		t.Fatalf("open --first: %v", err)
+	}
+	if got := opens(); got[picked] != 2 {
+		t.Fatalf("--first should open the most used match %s, counts = %v", picked, got)
+	}
+
+	if err := runOpen(ctx, []string{"zshrc"}); err != nil || opens()["zshrc"] != 1 {
+		t.Fatalf("exact name should open directly: %v", err)
+	}
+}
+
+func TestEditorSettingsChooseTheEditor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses the true command as an installed editor")
+	}
+	t.Setenv("VISUAL", "vim")
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	if got := defaultEditor(store, settings.Settings{}); got != "vim" {
+		t.Fatalf("default editor = %q, want $VISUAL without a setting", got)
+	}
+	prefs := settings.Settings{Editor: "hx", Editors: map[string]string{"Markdown": "true", "yaml": "no-such-editor"}}
+	if got := defaultEditor(store, prefs); got != "hx" {
+		t.Fatalf("default editor = %q, want the editor setting over $VISUAL", got)
+	}
+
+	for _, tc := range []struct {
+		config models.ConfigEntry
+		want   string
+	}{
+		{models.ConfigEntry{Type: "markdown"}, "true"},
+		{models.ConfigEntry{Type: "yaml"}, "hx"}, // not installed
+		{models.ConfigEntry{Type: "markdown", Editor: "sh"}, "sh"},
+		{models.ConfigEntry{Type: "markdown", Editor: "sh -x"}, "sh -x"},
+		{models.ConfigEntry{Type: "markdown", Editor: "no-such-editor -x"}, "true"},
+		{models.ConfigEntry{Type: "toml"}, "hx"},
+	} {
+		if got := preferredEditor(tc.config, prefs, "hx"); got != tc.want {
+			t.Errorf("editor for %+v = %q, want %q", tc.config, got, tc.want)
+		}
+	}
+	kinds := settings.Settings{EditorKinds: map[string]string{"hx": "terminal", "code --new-window": "gui"}}
+	for editorCmd, want := range map[string]string{"hx": "terminal", "hx --config x": "terminal", "code": "gui", "vim": ""} {
+		if got := editorKind(kinds, editorCmd); got != want {
+			t.Errorf("kind of %q = %q, want %q", editorCmd, got, want)
+		}
+	}
+	if got := editorKind(settings.Settings{}, "hx"); got != "" {
+		t.Errorf("kind of hx without the setting = %q, want zap's guess", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := launchEntry(models.ConfigEntry{Path: path}, "true --new-window", editor.Options{Wait: true}); err != nil {
+		t.Fatalf("editor with arguments: %v", err)
+	}
+}
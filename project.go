@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// projectFieldNames are the project metadata fields cycled through with tab.
+var projectFieldNames = []string{"Description", "Root", "Color"}
+
+// findProject returns the metadata stored for a project name, if any.
+func (m model) findProject(name string) *models.Project {
+	return models.FindProject(m.projects, name)
+}
+
+// projectColor returns the accent color for a project, falling back to the
+// default header color.
+func (m model) projectColor(name string) lipgloss.Color {
+	if project := m.findProject(name); project != nil && project.Color != "" {
+		return lipgloss.Color(project.Color)
+	}
+	return lipgloss.Color("214")
+}
+
+// startProjectEdit opens the metadata of the selected file's project.
+func (m *model) startProjectEdit() tea.Cmd {
+	config := m.getConfigByDisplayIndex(m.cursor)
+	if config == nil {
+		return nil
+	}
+	if config.Project == "" {
+		return showWarning("Assign the file to a project first")
+	}
+
+	m.projDraft = models.Project{Name: config.Project}
+	if project := m.findProject(config.Project); project != nil {
+		m.projDraft = *project
+	}
+	m.mode = ModeProjectEdit
+	m.projCol = 0
+	m.loadProjectField()
+	m.textInput.Focus()
+	m.refreshRightViewport()
+	return nil
+}
+
+func (m *model) loadProjectField() {
+	var value string
+	switch m.projCol {
+	case 0:
+		value = m.projDraft.Description
+	case 1:
+		value = m.projDraft.Root
+	case 2:
+		value = m.projDraft.Color
+	}
+	m.textInput.SetSuggestions(nil)
+	m.textInput.SetValue(value)
+	m.textInput.SetCursor(len(value))
+}
+
+// applyProjectField validates the input and stores it in the project draft.
+func (m *model) applyProjectField() error {
+	value := strings.TrimSpace(m.textInput.Value())
+	switch m.projCol {
+	case 0:
+		m.projDraft.Description = value
+	case 1:
+		if value != "" {
+			value = editor.ExpandPath(value)
+		}
+		m.projDraft.Root = value
+	case 2:
+		if !models.ValidColor(value) {
+			return fmt.Errorf("color must be 0-255 or #rrggbb")
+		}
+		m.projDraft.Color = value
+	}
+	m.refreshRightViewport()
+	return nil
+}
+
+// commitProjectEdit stores the project draft and saves the registry.
+func (m *model) commitProjectEdit() error {
+	if err := m.applyProjectField(); err != nil {
+		return err
+	}
+
+	projects := append([]models.Project(nil), m.projects...)
+	if existing := models.FindProject(projects, m.projDraft.Name); existing != nil {
+		*existing = m.projDraft
+	} else {
+		projects = append(projects, m.projDraft)
+	}
+
+	m.storage.SetProjects(projects)
+	if err := m.storage.Save(m.configs); err != nil {
+		m.storage.SetProjects(m.projects)
+		return err
+	}
+	m.projects = projects
+	return nil
+}
+
+func (m *model) cancelProjectEdit() {
+	m.mode = ModeNormal
+	m.projDraft = models.Project{}
+	m.textInput.Blur()
+	m.textInput.SetValue("")
+	m.buildDisplayList()
+	m.refreshRightViewport()
+}
+
+func (m model) updateProjectEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.cancelProjectEdit()
+		return m, showStatus("Project edit cancelled")
+	case "enter":
+		name := m.projDraft.Name
+		if err := m.commitProjectEdit(); err != nil {
+			return m, showError(err.Error())
+		}
+		m.cancelProjectEdit()
+		return m, showSuccess(fmt.Sprintf("Saved project %s", name))
+	case "tab", "shift+tab":
+		if err := m.applyProjectField(); err != nil {
+			return m, showError(err.Error())
+		}
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = len(projectFieldNames) - 1
+		}
+		m.projCol = (m.projCol + step) % len(projectFieldNames)
+		m.loadProjectField()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// projectPanelContent describes the project being edited in the right panel.
+func (m model) projectPanelContent() string {
+	p := m.projDraft
+	title := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	if models.ValidColor(p.Color) && p.Color != "" {
+		title = title.Foreground(lipgloss.Color(p.Color))
+	}
+
+	count := 0
+	for _, config := range m.configs {
+		if strings.EqualFold(config.Project, p.Name) {
+			count++
+		}
+	}
+
+	lines := []string{
+		title.Render("Project: " + p.Name),
+		fmt.Sprintf("Files: %d", count),
+		"",
+		"Description: " + p.Description,
+		"Root: " + p.Root,
+		"Color: " + p.Color,
+		"",
+		"New files under Root join this project, and",
+		"new files added to it start out in Root.",
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/internal/watch"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestProjectEditIsSavedWithRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zap-registry.json")
+	store := storage.New(path)
+	configs := []models.ConfigEntry{{Name: "nginx", Path: "/etc/nginx/nginx.conf", Project: "infra"}}
+	m := model{width: 100, height: 24, storage: store, configs: configs, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.buildDisplayList()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	m = updated.(model)
+	m.textInput.SetValue("web servers")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	m.textInput.SetValue("/etc/nginx")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if updated.(model).mode != ModeNormal {
+		t.Fatalf("mode after enter = %v, want ModeNormal", updated.(model).mode)
+	}
+
+	reloaded := storage.New(path)
+	if _, err := reloaded.Load(); err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	project := models.FindProject(reloaded.Projects(), "infra")
+	if project == nil || project.Description != "web servers" || project.Root != "/etc/nginx" {
+		t.Fatalf("saved project = %+v, want description and root", project)
+	}
+}
+
+func TestProjectForPathPrefersDeepestRoot(t *testing.T) {
+	projects := []models.Project{
+		{Name: "etc", Root: "/etc"},
+		{Name: "infra", Root: "/etc/nginx"},
+		{Name: "other", Root: "/etc/nginx-old"},
+	}
+	if got := models.ProjectForPath(projects, "/etc/nginx/sites/default"); got != "infra" {
+		t.Fatalf("ProjectForPath = %q, want infra", got)
+	}
+	if got := models.ProjectForPath(projects, "/srv/app.conf"); got != "" {
+		t.Fatalf("ProjectForPath outside any root = %q, want empty", got)
+	}
+}
+
+func TestProjectColorFallsBackToDefault(t *testing.T) {
+	m := model{projects: []models.Project{{Name: "infra", Color: "#ff8800"}}}
+	if got := m.projectColor("INFRA"); got != "#ff8800" {
+		t.Fatalf("projectColor = %q, want the configured color", got)
+	}
+	if got := m.projectColor("dots"); got != "214" {
+		t.Fatalf("projectColor without metadata = %q, want default 214", got)
+	}
+}
+
+func TestProjectHeadersCountFilesAndMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "nginx.conf")
+	if err := os.WriteFile(present, []byte("events {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "nginx", Path: present, Project: "infra"},
+		{Name: "old", Path: filepath.Join(dir, "gone.conf"), Project: "infra"},
+		{Name: "notes", Path: present + ".md"},
+	}
+	m.buildDisplayList()
+
+	headers := func() []string {
+		var headers []string
+		for _, display := range m.displayConfigs {
+			if display.isHeader {
+				headers = append(headers, display.headerText)
+			}
+		}
+		return headers
+	}
+	want := []string{"📂 General (1, 1 missing)", "📂 infra (2, 1 missing)"}
+	if got := headers(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("headers = %q, want %q", got, want)
+	}
+
+	// Rebuilding for a filter reuses the checks; a watcher event redoes one
+	gone := filepath.Join(dir, "gone.conf")
+	if err := os.WriteFile(gone, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.buildDisplayList()
+	if got := headers(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("headers after a rebuild = %q, want the cached %q", got, want)
+	}
+	updated, _ := m.Update(watch.ChangedMsg{Path: gone})
+	m = updated.(model)
+	want = []string{"📂 General (1, 1 missing)", "📂 infra (2)"}
+	if got := headers(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("headers after the file appeared = %q, want %q", got, want)
+	}
+}
+
+func TestNewFileInProjectStartsInItsRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "webapp")
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.projects = []models.Project{{Name: "webapp", Root: root}}
+	m.addNewConfig()
+
+	m.editCol = 1
+	m.loadEditField()
+	m.textInput.SetValue("webapp")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	if got := m.textInput.Value(); got != root+string(filepath.Separator) {
+		t.Fatalf("path field = %q, want the project root", got)
+	}
+
+	m.textInput.SetValue("config/nginx.conf")
+	if hint, _ := m.editFieldHint(); hint != "path does not exist yet" {
+		t.Fatalf("hint before the file exists = %q", hint)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "config", "nginx.conf"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if hint, _ := m.editFieldHint(); hint != "" {
+		t.Fatalf("hint for a relative path in the project root = %q, want none", hint)
+	}
+	if err := m.applyEditField(); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "config", "nginx.conf"); m.draft.Path != want {
+		t.Fatalf("relative path = %q, want %q", m.draft.Path, want)
+	}
+	if got := models.PathInProject(m.projects, "webapp", "/etc/hosts"); got != "/etc/hosts" {
+		t.Fatalf("absolute path = %q, want it unchanged", got)
+	}
+}
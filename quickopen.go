@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// quickOpenMatches returns the unarchived entries matching query, best
+// first. Without a query the most recently opened entries come first.
+func quickOpenMatches(configs []models.ConfigEntry, query string, now time.Time, opts searchOptions) []models.ConfigEntry {
+	fold := opts.fold
+	query = fold(strings.TrimSpace(query))
+	if query == "" {
+		var matches []models.ConfigEntry
+		for _, config := range storage.SortByRecentlyOpened(configs) {
+			if !config.Archived {
+				matches = append(matches, config)
+			}
+		}
+		return matches
+	}
+
+	var matches []models.ConfigEntry
+	ranks := map[string]float64{}
+	for _, config := range configs {
+		if config.Archived {
+			continue
+		}
+		if fold(config.Alias) != query &&
+			!fuzzyMatch(query, fold(config.Name)) &&
+			!fuzzyMatch(query, fold(config.Project)) &&
+			!fuzzyMatch(query, fold(filepath.Base(config.Path))) &&
+			!fuzzyMatch(query, fold(config.Description)) &&
+			!matchesAny(query, config.SearchTerms(), fold) {
+			continue
+		}
+		matches = append(matches, config)
+		ranks[config.Path] = searchRank(config, query, now, opts)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return ranks[matches[i].Path] > ranks[matches[j].Path]
+	})
+	return matches
+}
+
+// matchesAny reports whether query fuzzy-matches any of names.
+func matchesAny(query string, names []string, fold func(string) string) bool {
+	for _, name := range names {
+		if fuzzyMatch(query, fold(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// startQuickOpen shows the quick-open launcher. The list's search and
+// filters are left untouched.
+func (m *model) startQuickOpen() {
+	m.quickOpenCursor = 0
+	m.textInput.SetSuggestions(nil)
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = "open…"
+	m.textInput.Focus()
+	m.mode = ModeQuickOpen
+}
+
+func (m *model) closeQuickOpen() {
+	m.mode = ModeNormal
+	m.quickOpenCursor = 0
+	m.textInput.Blur()
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = ""
+}
+
+func (m model) updateQuickOpen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	matches := quickOpenMatches(m.configs, m.textInput.Value(), time.Now(), m.searchOptions())
+	switch msg.String() {
+	case "esc", "ctrl+o":
+		m.closeQuickOpen()
+		return m, nil
+	case "up", "ctrl+p", "ctrl+k":
+		if m.quickOpenCursor > 0 {
+			m.quickOpenCursor--
+		}
+		return m, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if m.quickOpenCursor < len(matches)-1 {
+			m.quickOpenCursor++
+		}
+		return m, nil
+	case "enter":
+		if len(matches) == 0 {
+			return m, nil
+		}
+		config := matches[m.quickOpenCursor]
+		m.closeQuickOpen()
+		return m, m.openConfigs([]models.ConfigEntry{config}, m.editorFor(config), false)
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	m.quickOpenCursor = 0
+	return m, cmd
+}
+
+// renderQuickOpenPanel shows the launcher's input and matches in place of
+// the file list.
+func (m model) renderQuickOpenPanel() string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("117")).
+		Padding(0, 1)
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+	innerHeight := m.mainContentHeight() - panelStyle.GetVerticalFrameSize()
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render("Quick open")
+	lines := []string{title, "", m.textInput.View(), ""}
+
+	matches := quickOpenMatches(m.configs, m.textInput.Value(), time.Now(), m.searchOptions())
+	if len(matches) == 0 {
+		lines = append(lines, suitechrome.Dim("No matching files"))
+	}
+	visible := max(innerHeight-len(lines), 1)
+	start := 0
+	if m.quickOpenCursor >= visible {
+		start = m.quickOpenCursor - visible + 1
+	}
+	nameWidth := min(max(innerWidth/3, 12), 32)
+	for i := start; i < len(matches) && i < start+visible; i++ {
+		config := matches[i]
+		name := config.Name
+		if config.Alias != "" {
+			name += " (" + config.Alias + ")"
+		}
+		name = fmt.Sprintf("%-*s  ", nameWidth, truncate(name, nameWidth))
+		path := truncate(config.Path, max(innerWidth-nameWidth-2, 3))
+		if i == m.quickOpenCursor {
+			lines = append(lines, lipgloss.NewStyle().
+				Foreground(lipgloss.Color("230")).
+				Background(lipgloss.Color("62")).
+				Width(innerWidth).
+				Render(name+path))
+			continue
+		}
+		lines = append(lines, name+suitechrome.Dim(path))
+	}
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(innerHeight).
+		Render(strings.Join(lines, "\n"))
+}
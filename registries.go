@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultRegistryName labels the registry zap uses without --registry.
+const defaultRegistryName = "default"
+
+// registryOverride is the registry file chosen with --registry. It takes
+// precedence over ZAP_REGISTRY_PATH.
+var registryOverride string
+
+var registryNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// registryPathFor returns the file for a --registry value. A name selects a
+// registry kept next to the default one as zap-registry-<name>.json, which
+// shares its settings.json; anything that looks like a path is used as is.
+func registryPathFor(value string) (string, error) {
+	if strings.ContainsRune(value, filepath.Separator) || strings.ContainsRune(value, '/') || strings.HasSuffix(value, ".json") {
+		return filepath.Abs(editor.ExpandPath(value))
+	}
+	base, err := defaultRegistryPath()
+	if err != nil {
+		return "", err
+	}
+	if value == defaultRegistryName {
+		return base, nil
+	}
+	if !registryNamePattern.MatchString(value) {
+		return "", invalidf("registry name %q may only use letters, digits, - and _", value)
+	}
+	return filepath.Join(filepath.Dir(base), "zap-registry-"+value+".json"), nil
+}
+
+// registryName labels a registry file: "default", the name of a registry
+// next to it, or the file's path for any other.
+func registryName(path string) string {
+	base, err := defaultRegistryPath()
+	if err == nil && filepath.Clean(path) == filepath.Clean(base) {
+		return defaultRegistryName
+	}
+	if err == nil && filepath.Dir(path) == filepath.Dir(base) {
+		name := filepath.Base(path)
+		if rest, ok := strings.CutPrefix(name, "zap-registry-"); ok && strings.HasSuffix(rest, ".json") {
+			return strings.TrimSuffix(rest, ".json")
+		}
+	}
+	return contractHome(path)
+}
+
+// registryNames lists the default registry and every named one next to it.
+func registryNames() []string {
+	names := []string{defaultRegistryName}
+	base, err := defaultRegistryPath()
+	if err != nil {
+		return names
+	}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(base), "zap-registry-*.json"))
+	var named []string
+	for _, match := range matches {
+		if name := registryName(match); registryNamePattern.MatchString(name) && name != defaultRegistryName {
+			named = append(named, name)
+		}
+	}
+	sort.Strings(named)
+	return append(names, named...)
+}
+
+// extractRegistry removes the global --registry flag from a command's
+// arguments, returning its value.
+func extractRegistry(args []string) (string, []string, error) {
+	value := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if v, ok := strings.CutPrefix(strings.TrimPrefix(arg, "-"), "registry="); ok && strings.HasPrefix(arg, "-") {
+			value = v
+			continue
+		}
+		if arg == "--registry" || arg == "-registry" {
+			if i+1 == len(args) {
+				return "", nil, invalidf("flag needs an argument: %s", arg)
+			}
+			value = args[i+1]
+			i++
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return value, rest, nil
+}
+
+// useRegistry makes commands load the registry named by a --registry value.
+func useRegistry(value string) error {
+	if value == "" {
+		return nil
+	}
+	path, err := registryPathFor(value)
+	if err != nil {
+		return err
+	}
+	registryOverride = path
+	return nil
+}
+
+// registryPickerState is the registry switcher, listing the registries next
+// to the default one.
+type registryPickerState struct {
+	names    []string
+	cursor   int
+	creating bool // typing the name of a new registry into textInput
+}
+
+func (m *model) startRegistryPicker() tea.Cmd {
+	m.registries = registryPickerState{names: registryNames()}
+	current := registryName(m.storage.GetFilePath())
+	for i, name := range m.registries.names {
+		if name == current {
+			m.registries.cursor = i
+		}
+	}
+	m.mode = ModeRegistryPicker
+	return nil
+}
+
+func (m model) updateRegistryPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.registries.creating {
+		switch msg.String() {
+		case "esc":
+			m.registries.creating = false
+			m.textInput.Blur()
+			m.textInput.SetValue("")
+			return m, nil
+		case "enter":
+			name := strings.TrimSpace(m.textInput.Value())
+			if !registryNamePattern.MatchString(name) {
+				return m, showWarning("Use letters, digits, - and _ for the registry name")
+			}
+			m.textInput.Blur()
+			m.textInput.SetValue("")
+			return m.switchRegistry(name)
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "esc", "q", "b":
+		m.mode = ModeNormal
+		m.registries = registryPickerState{}
+	case "j", "down":
+		if m.registries.cursor < len(m.registries.names)-1 {
+			m.registries.cursor++
+		}
+	case "k", "up":
+		if m.registries.cursor > 0 {
+			m.registries.cursor--
+		}
+	case "n":
+		m.registries.creating = true
+		m.textInput.SetSuggestions(nil)
+		m.textInput.SetValue("")
+		m.textInput.Focus()
+	case "enter":
+		return m.switchRegistry(m.registries.names[m.registries.cursor])
+	}
+	return m, nil
+}
+
+// switchRegistry replaces the TUI state with that of another registry.
+func (m model) switchRegistry(name string) (tea.Model, tea.Cmd) {
+	path, err := registryPathFor(name)
+	if err != nil {
+		return m, showError(err.Error())
+	}
+	if filepath.Clean(path) == filepath.Clean(m.storage.GetFilePath()) {
+		m.mode = ModeNormal
+		m.registries = registryPickerState{}
+		return m, showStatus("Already using " + name)
+	}
+	// Opens waiting for their delayed save belong to the registry being left
+	if err := m.flushUsage(); err != nil {
+		return m, showError(fmt.Sprintf("Failed to save usage: %v", err))
+	}
+
+	store := storage.New(path)
+	configs, err := store.Load()
+	if err != nil {
+		return m, showError(fmt.Sprintf("Failed to load %s: %v", name, err))
+	}
+	if !editor.FileExists(path) {
+		// Write the new registry right away so the switcher lists it
+		if err := store.Save(configs); err != nil {
+			return m, showError(fmt.Sprintf("Failed to create %s: %v", name, err))
+		}
+	}
+	prefs, err := settings.Load(settings.PathFor(path))
+	if err != nil {
+		return m, showError(fmt.Sprintf("Failed to load settings: %v", err))
+	}
+	store.SetRelativePaths(prefs.RelativePaths)
+	if configs, err = withShared(configs, prefs); err != nil {
+		return m, showError(err.Error())
+	}
+
+	if m.watcher != nil {
+		m.watcher.Close()
+	}
+	next := newModel(store, configs, prefs)
+	next.width, next.height, next.dryRun = m.width, m.height, m.dryRun
+	next.tutorial = false
+	next.refreshRightViewport()
+	return next, tea.Batch(next.Init(), showSuccess(fmt.Sprintf("Switched to %s (%d files)", name, len(configs))))
+}
+
+// renderRegistryPanel lists the registries in place of the file list.
+func (m model) renderRegistryPanel() string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("117")).
+		Padding(0, 1)
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render("Registries")
+	current := registryName(m.storage.GetFilePath())
+	lines := []string{title, ""}
+	for i, name := range m.registries.names {
+		label := "  " + name
+		if name == current {
+			label = "● " + name
+		}
+		if i == m.registries.cursor {
+			lines = append(lines, lipgloss.NewStyle().
+				Foreground(lipgloss.Color("230")).
+				Background(lipgloss.Color("62")).
+				Width(innerWidth).
+				Render(label))
+			continue
+		}
+		lines = append(lines, label)
+	}
+	if current != defaultRegistryName && !containsString(m.registries.names, current) {
+		lines = append(lines, "", "● "+current+" (opened with --registry)")
+	}
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(m.mainContentHeight() - panelStyle.GetVerticalFrameSize()).
+		Render(strings.Join(lines, "\n"))
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
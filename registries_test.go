@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRegistryFlagSelectsANamedRegistry(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(registryPathEnv, filepath.Join(dir, "zap-registry.json"))
+	t.Cleanup(func() { registryOverride = "" })
+	work := storage.New(filepath.Join(dir, "zap-registry-work.json"))
+	if err := work.Save([]models.ConfigEntry{{Name: "vpn", Path: "/etc/vpn.conf"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	value, rest, err := extractRegistry([]string{"--format", "names", "--registry", "work"})
+	if err != nil || value != "work" || len(rest) != 2 {
+		t.Fatalf("extractRegistry = %q, %q, %v", value, rest, err)
+	}
+	if err := useRegistry(value); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runList(&cliContext{stdout: &out, stderr: &out, quiet: true}, rest); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "vpn\n" {
+		t.Fatalf("list = %q, want the work registry's entries", out.String())
+	}
+	if err := useRegistry("no spaces allowed"); exitCode(err) != exitInvalid {
+		t.Fatalf("bad registry name: %v", err)
+	}
+	if names := registryNames(); len(names) != 2 || names[0] != "default" || names[1] != "work" {
+		t.Fatalf("registryNames = %q", names)
+	}
+}
+
+func TestSwitchingToANewRegistry(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(registryPathEnv, filepath.Join(dir, "zap-registry.json"))
+	store := storage.New(filepath.Join(dir, "zap-registry.json"))
+	m := newModel(store, []models.ConfigEntry{{Name: "zshrc", Path: "/home/me/.zshrc"}}, settings.Settings{})
+	m.recordOpens([]string{"/home/me/.zshrc"})
+
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune{'b'}},
+		{Type: tea.KeyRunes, Runes: []rune{'n'}},
+		{Type: tea.KeyRunes, Runes: []rune("personal")},
+		{Type: tea.KeyEnter},
+	} {
+		updated, _ := m.Update(key)
+		m = updated.(model)
+	}
+	if got := m.storage.GetFilePath(); got != filepath.Join(dir, "zap-registry-personal.json") {
+		t.Fatalf("registry = %s, want the new personal registry", got)
+	}
+	if m.mode != ModeNormal || len(m.configs) != 0 || m.tutorial {
+		t.Fatalf("after switching: mode %v, %d files, tutorial %v", m.mode, len(m.configs), m.tutorial)
+	}
+	if saved, _ := store.Load(); len(saved) != 1 || saved[0].OpenCount != 1 {
+		t.Fatalf("open recorded before switching was not saved: %+v", saved)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	m = updated.(model)
+	if names := m.registries.names; len(names) != 2 || names[m.registries.cursor] != "personal" {
+		t.Fatalf("picker lists %q with %d selected, want personal created and selected", names, m.registries.cursor)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.mode != ModeNormal || m.storage.GetFilePath() != filepath.Join(dir, "zap-registry-personal.json") {
+		t.Fatalf("enter on the current registry should just close the picker, mode = %v", m.mode)
+	}
+}
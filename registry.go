@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+// Registry operations shared by the TUI and the CLI subcommands. They work
+// on a slice of entries and leave saving to the caller.
+
+// entryForPath returns a new entry for the file at path with the defaults
+// zap fills in when adding one: named after the file, typed by its name and
+// in the project whose root holds it.
+func entryForPath(path string, projects []models.Project) models.ConfigEntry {
+	return models.ConfigEntry{
+		Name:    filepath.Base(path),
+		Path:    path,
+		Type:    models.DetectFileType(path),
+		Project: models.ProjectForPath(projects, path),
+	}
+}
+
+// registerEntry returns configs with config added as a new entry, stamped
+// with when it was added and set to ask before opening when its path looks
+// sensitive. A path or alias that is already registered is refused.
+func registerEntry(configs []models.ConfigEntry, config models.ConfigEntry, now time.Time) ([]models.ConfigEntry, error) {
+	if dup := storage.FindDuplicates(configs, config.Path); dup != nil {
+		return configs, fmt.Errorf("file already registered as '%s'", dup.Name)
+	}
+	if config.Alias != "" {
+		if dup := models.FindByAlias(configs, config.Alias); dup != nil {
+			return configs, fmt.Errorf("alias already used by '%s'", dup.Name)
+		}
+	}
+	config.Added = now
+	if models.NeedsOpenConfirmation(config.Path) {
+		config.ConfirmOpen = true
+	}
+	added := make([]models.ConfigEntry, len(configs), len(configs)+1)
+	copy(added, configs)
+	return append(added, config), nil
+}
+
+// removeEntries returns configs without the entries in targets.
+func removeEntries(configs, targets []models.ConfigEntry) []models.ConfigEntry {
+	var kept []models.ConfigEntry
+	for _, config := range configs {
+		drop := false
+		for i := range targets {
+			if config.Equals(&targets[i]) {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			kept = append(kept, config)
+		}
+	}
+	return kept
+}
+
+// filterEntries returns the entries matching query, parsed like the TUI's
+// search, leaving out archived entries unless archived is set.
+func filterEntries(configs []models.ConfigEntry, query string, archived bool, opts searchOptions) []models.ConfigEntry {
+	groups := parseQuery(query, opts.fold)
+	var matches []models.ConfigEntry
+	for _, config := range configs {
+		if (archived || !config.Archived) && matchesSearch(config, groups, opts) {
+			matches = append(matches, config)
+		}
+	}
+	return matches
+}
+
+// runAdd registers files without opening the TUI, for scripts and dotfile
+// installers. Each path must exist; one that is already registered is an
+// error unless --skip-existing is given, so reruns are harmless.
+func runAdd(ctx *cliContext, args []string) error {
+	const usage = "add [--name n] [--project p] [--type t] [--description d] [--alias a] [--skip-existing] <path>..."
+	fs := ctx.flagSet("add")
+	name := fs.String("name", "", "Entry name (default the file name; one path only)")
+	project := fs.String("project", "", "Project (default the one whose root holds the file)")
+	fileType := fs.String("type", "", "File type (default detected from the name)")
+	description := fs.String("description", "", "Description")
+	alias := fs.String("alias", "", "Short alias (one path only)")
+	skipExisting := fs.Bool("skip-existing", false, "Skip paths that are already registered")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usageError(usage)
+	}
+	if len(rest) > 1 && (*name != "" || *alias != "") {
+		return invalidf("--name and --alias need a single path")
+	}
+
+	store, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	// Every path is checked before anything is reported or saved, so a
+	// failing path leaves the registry as it was
+	var added []models.ConfigEntry
+	for _, arg := range rest {
+		path, err := filepath.Abs(editor.ExpandPath(arg))
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return notFoundf("%s: no such file", arg)
+		}
+		if dup := storage.FindDuplicates(configs, path); dup != nil && *skipExisting {
+			if !ctx.quiet {
+				fmt.Fprintf(ctx.stderr, "skipped  %s  (already registered as %s)\n", contractHome(path), dup.Name)
+			}
+			continue
+		}
+
+		config := entryForPath(path, store.Projects())
+		if *name != "" {
+			config.Name = *name
+		}
+		if *project != "" {
+			config.Project = *project
+		}
+		if *fileType != "" {
+			config.Type = *fileType
+		}
+		config.Description = *description
+		config.Alias = *alias
+		if configs, err = registerEntry(configs, config, time.Now()); err != nil {
+			return invalidf("%s: %v", contractHome(path), err)
+		}
+		added = append(added, config)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if err := store.Save(configs); err != nil {
+		return err
+	}
+	for _, config := range added {
+		fmt.Fprintf(ctx.stdout, "added    %s  %s\n", config.Name, contractHome(config.Path))
+	}
+	return nil
+}
+
+// runList prints the registered files, optionally narrowed by a query
+// written like the TUI's search, sorted by name.
+func runList(ctx *cliContext, args []string) error {
+	const usage = "list [--archived] [--format table|names|json] [query]"
+	fs := ctx.flagSet("list")
+	archived := fs.Bool("archived", false, "Include archived entries")
+	format := fs.String("format", "table", "table, names or json")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "table", "names", "json":
+	default:
+		return invalidf("unknown format %q; use table, names or json", *format)
+	}
+
+	_, configs, prefs, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	collation, err := storage.NewCollation(prefs.Collation)
+	if err != nil {
+		return invalidf("%v", err)
+	}
+	matches := storage.SortByName(filterEntries(configs, strings.Join(rest, " "), *archived, searchOptionsFor(prefs)), collation)
+	if len(matches) == 0 && len(rest) > 0 {
+		return notFoundf("no entry matches %q", strings.Join(rest, " "))
+	}
+
+	switch *format {
+	case "names":
+		for _, config := range matches {
+			fmt.Fprintln(ctx.stdout, config.Name)
+		}
+	case "json":
+		if matches == nil {
+			matches = []models.ConfigEntry{}
+		}
+		data, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = ctx.stdout.Write(append(data, '\n'))
+		return err
+	default:
+		nameWidth, projectWidth := 0, 0
+		for _, config := range matches {
+			nameWidth = max(nameWidth, len(config.Name))
+			projectWidth = max(projectWidth, len(config.Project))
+		}
+		for _, config := range matches {
+			fmt.Fprintf(ctx.stdout, "%-*s  %-*s  %s\n", nameWidth, config.Name, projectWidth, config.Project, contractHome(config.Path))
+		}
+	}
+	return nil
+}
+
+// runRm removes entries from the registry by exact name or alias. The files
+// themselves are left alone.
+func runRm(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("rm")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usageError("rm <name>...")
+	}
+
+	store, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	var targets []models.ConfigEntry
+	for _, name := range rest {
+		config, err := resolveEntry(configs, name, true)
+		if err != nil {
+			return err
+		}
+		if config.Shared {
+			return invalidf("%s comes from the shared registry and can't be removed here", config.Name)
+		}
+		targets = append(targets, config)
+	}
+	store.RecordDeleted(targets, time.Now())
+	if err := store.Save(removeEntries(configs, targets)); err != nil {
+		return err
+	}
+	for _, config := range targets {
+		fmt.Fprintf(ctx.stdout, "removed  %s\n", config.Name)
+	}
+	return nil
+}
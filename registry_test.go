@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddListRmWithoutTheTUI(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(registryPathEnv, filepath.Join(dir, "zap-registry.json"))
+	zshrc := filepath.Join(dir, ".zshrc")
+	nginx := filepath.Join(dir, "nginx.conf")
+	for _, path := range []string{zshrc, nginx} {
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out, quiet: true}
+	if err := runAdd(ctx, []string{"--project", "dots", zshrc, nginx}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAdd(ctx, []string{zshrc}); exitCode(err) != exitInvalid {
+		t.Fatalf("adding a registered path again: %v, want invalid", err)
+	}
+	if err := runAdd(ctx, []string{"--skip-existing", zshrc}); err != nil {
+		t.Fatalf("--skip-existing: %v", err)
+	}
+	if err := runAdd(ctx, []string{filepath.Join(dir, "missing")}); exitCode(err) != exitNotFound {
+		t.Fatalf("adding a missing file: %v, want not found", err)
+	}
+	hosts := filepath.Join(dir, "hosts")
+	if err := os.WriteFile(hosts, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := runAdd(ctx, []string{hosts, zshrc}); exitCode(err) != exitInvalid || out.Len() != 0 {
+		t.Fatalf("adding a new path with a registered one: %v, printed %q; want nothing added", err, out.String())
+	}
+
+	out.Reset()
+	if err := runList(ctx, []string{"--format", "names"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != ".zshrc\nnginx.conf\n" {
+		t.Fatalf("list = %q, want both files sorted by name", got)
+	}
+	out.Reset()
+	if err := runList(ctx, []string{"nginx"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "nginx.conf  dots  "+contractHome(nginx)+"\n" {
+		t.Fatalf("filtered table = %q", got)
+	}
+
+	if err := runRm(ctx, []string{"nginx"}); exitCode(err) != exitNotFound {
+		t.Fatalf("rm of a partial name: %v, want not found", err)
+	}
+	if err := runRm(ctx, []string{"nginx.conf"}); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := runList(ctx, []string{"--format", "names"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != ".zshrc\n" {
+		t.Fatalf("list after rm = %q", got)
+	}
+	if _, err := os.Stat(nginx); err != nil {
+		t.Fatalf("rm deleted the file itself: %v", err)
+	}
+}
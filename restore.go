@@ -0,0 +1,247 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+// maxRestorePreview is how many diff lines zap restore shows per file.
+const maxRestorePreview = 40
+
+// archivedFile is a file's copy inside a backup.
+type archivedFile struct {
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// readBackup loads an archive written by zap backup, returning its copies
+// of the files keyed by their name in the archive.
+func readBackup(path string) (registryBackup, map[string]archivedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return registryBackup{}, nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return registryBackup{}, nil, invalidf("%s is not a zap backup: %v", path, err)
+	}
+
+	files := map[string]archivedFile{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return registryBackup{}, nil, invalidf("%s is not a zap backup: %v", path, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return registryBackup{}, nil, err
+		}
+		files[header.Name] = archivedFile{data: data, mode: os.FileMode(header.Mode).Perm(), modTime: header.ModTime}
+	}
+
+	manifest, ok := files[backupManifest]
+	if !ok {
+		return registryBackup{}, nil, invalidf("%s has no %s", path, backupManifest)
+	}
+	var backup registryBackup
+	if err := json.Unmarshal(manifest.data, &backup); err != nil {
+		return registryBackup{}, nil, invalidf("%s: %v", backupManifest, err)
+	}
+	return backup, files, nil
+}
+
+// runRestore writes the files in a backup back to their registered paths,
+// showing how each differs from what is on disk and asking before writing
+// it. Entries missing from the registry are registered again, without
+// their check commands unless --allow-run is given.
+func runRestore(ctx *cliContext, args []string) error {
+	const usage = "restore [--yes] [--dry-run] [--allow-run] <archive>"
+	fs := ctx.flagSet("restore")
+	yes := fs.Bool("yes", false, "Restore every file without asking")
+	dryRun := fs.Bool("dry-run", false, "Only show what would change")
+	allowRun := fs.Bool("allow-run", false, "Keep the backup's check commands (listed and dropped otherwise)")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageError(usage)
+	}
+	if !*yes && !*dryRun && !ctx.interactive {
+		return invalidf("restore asks before writing each file; run it in a terminal, or pass --yes or --dry-run")
+	}
+
+	backup, files, err := readBackup(rest[0])
+	if err != nil {
+		return err
+	}
+	store, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+
+	var tally restoreTally
+	all := *yes
+	for _, entry := range backup.Entries {
+		file, ok := files[entry.File]
+		if !ok {
+			return invalidf("backup is missing %s for %s", entry.File, entry.Name)
+		}
+		target := editor.ExpandPath(entry.Path)
+
+		// The check command is listed with the diff so it is seen before
+		// the entry is registered with it
+		register := storage.FindDuplicates(configs, target) == nil
+		run := ""
+		if register {
+			run = entry.Run
+		}
+		current, err := os.ReadFile(target)
+		switch {
+		case err == nil && bytes.Equal(current, file.data):
+			tally.unchanged++
+			fmt.Fprintf(ctx.stdout, "unchanged  %s  %s\n", entry.Name, contractHome(target))
+			printRestoreRun(ctx, run, *allowRun)
+		case *dryRun:
+			printRestorePreview(ctx, entry.Name, target, current, err == nil, file.data)
+			printRestoreRun(ctx, run, *allowRun)
+			continue
+		default:
+			printRestorePreview(ctx, entry.Name, target, current, err == nil, file.data)
+			printRestoreRun(ctx, run, *allowRun)
+			write := true
+			if !all {
+				fmt.Fprintf(ctx.stderr, "Restore %s? [y/N/a(ll)/q(uit)] ", contractHome(target))
+				switch strings.ToLower(ctx.readLine()) {
+				case "y", "yes":
+				case "a", "all":
+					all = true
+				case "q", "quit":
+					tally.skipped++
+					return finishRestore(ctx, store, configs, tally)
+				default:
+					write = false
+				}
+			}
+			if !write {
+				tally.skipped++
+				if err != nil {
+					continue // nothing on disk to register
+				}
+			} else {
+				if err := restoreFile(target, file); err != nil {
+					return fmt.Errorf("restore %s: %w", entry.Name, err)
+				}
+				tally.restored++
+			}
+		}
+
+		if register {
+			config := entry.ConfigEntry
+			config.Path = target
+			config.AutoCheck = false // turned back on with c once the command is trusted again
+			if config.Run != "" && !*allowRun {
+				config.Run = ""
+				tally.dropped++
+			}
+			if models.FindByAlias(configs, config.Alias) != nil {
+				config.Alias = ""
+			}
+			configs = append(configs, config)
+			tally.registered++
+		}
+	}
+	if *dryRun {
+		return nil
+	}
+	return finishRestore(ctx, store, configs, tally)
+}
+
+// restoreTally counts what zap restore did with each backup entry.
+type restoreTally struct {
+	restored, unchanged, skipped, registered, dropped int
+}
+
+// printRestoreRun lists the check command an entry would be registered
+// with, and whether it is kept.
+func printRestoreRun(ctx *cliContext, run string, allowed bool) {
+	if run == "" {
+		return
+	}
+	if allowed {
+		fmt.Fprintf(ctx.stdout, "    run    %s\n", run)
+		return
+	}
+	fmt.Fprintf(ctx.stdout, "    no run %s  (pass --allow-run to keep it)\n", run)
+}
+
+// printRestorePreview describes what restoring a file would change.
+func printRestorePreview(ctx *cliContext, name, target string, current []byte, exists bool, data []byte) {
+	if !exists {
+		fmt.Fprintf(ctx.stdout, "missing    %s  %s  (would be created, %d lines)\n", name, contractHome(target), len(splitLines(data)))
+		return
+	}
+	fmt.Fprintf(ctx.stdout, "changed    %s  %s\n", name, contractHome(target))
+	if editor.IsBinary(current) || editor.IsBinary(data) {
+		fmt.Fprintln(ctx.stdout, "    binary files differ")
+		return
+	}
+	lines, _, err := unifiedDiff(contractHome(target), "backup", splitLines(current), splitLines(data))
+	if err != nil {
+		fmt.Fprintf(ctx.stdout, "    %v\n", err)
+		return
+	}
+	for i, line := range lines {
+		if i == maxRestorePreview {
+			fmt.Fprintf(ctx.stdout, "    … %d more lines\n", len(lines)-i)
+			break
+		}
+		fmt.Fprintln(ctx.stdout, "    "+line)
+	}
+}
+
+// restoreFile writes a file's backup copy to target with its permissions
+// and modification time.
+func restoreFile(target string, file archivedFile) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, file.data, file.mode); err != nil {
+		return err
+	}
+	if err := os.Chmod(target, file.mode); err != nil {
+		return err
+	}
+	return os.Chtimes(target, file.modTime, file.modTime)
+}
+
+func finishRestore(ctx *cliContext, store *storage.Storage, configs []models.ConfigEntry, tally restoreTally) error {
+	if tally.registered > 0 {
+		if err := store.Save(configs); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(ctx.stdout, "%d restored, %d unchanged, %d skipped, %d added to the registry\n", tally.restored, tally.unchanged, tally.skipped, tally.registered)
+	if tally.dropped > 0 {
+		fmt.Fprintf(ctx.stdout, "%d check commands left out; restore again with --allow-run to keep them\n", tally.dropped)
+	}
+	return nil
+}
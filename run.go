@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// runTimeout stops entry commands that hang.
+const runTimeout = time.Minute
+
+// runState holds the output of a command run for an entry.
+type runState struct {
+	config   models.ConfigEntry
+	command  string                 // shown as the pane title
+	fn       func() (string, error) // built-in action run instead of command
+	running  bool
+	lines    []string
+	err      error
+	duration time.Duration
+	scroll   int
+}
+
+// runFinishedMsg carries the result of an entry's Run command. gen is the
+// run it belongs to, so a result arriving after its pane was closed or
+// replaced is dropped.
+type runFinishedMsg struct {
+	gen      int
+	output   string
+	err      error
+	duration time.Duration
+}
+
+// shellCommand wraps a command line for the platform shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// runEntryCommand runs command for config in the background with exec, as
+// run gen.
+func runEntryCommand(exec commandRunner, gen int, config models.ConfigEntry, command string) tea.Cmd {
+	return func() tea.Msg {
+		msg := exec(config, command)
+		msg.gen = gen
+		return msg
+	}
+}
+
+// commandRunner runs an entry's command: execEntryCommand, or dryRunCommand
+// under zap script.
+type commandRunner func(config models.ConfigEntry, command string, env ...string) runFinishedMsg
+
+// commandRunner returns what runs entry commands for the model.
+func (m model) commandRunner() commandRunner {
+	if m.dryRun {
+		return dryRunCommand
+	}
+	return execEntryCommand
+}
+
+// dryRunCommand stands in for execEntryCommand when nothing may be changed,
+// reporting the command instead of running it.
+func dryRunCommand(_ models.ConfigEntry, command string, _ ...string) runFinishedMsg {
+	return runFinishedMsg{output: "dry run, not started: " + command + "\n"}
+}
+
+// execEntryCommand runs command for config from the file's directory with
+// ZAP_FILE set to its path, plus any extra env, capturing stdout and stderr
+// together.
+func execEntryCommand(config models.ConfigEntry, command string, env ...string) runFinishedMsg {
+	path := editor.ExpandPath(config.Path)
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	if info, err := os.Stat(filepath.Dir(path)); err == nil && info.IsDir() {
+		cmd.Dir = filepath.Dir(path)
+	}
+	cmd.Env = append(append(os.Environ(), "ZAP_FILE="+path), env...)
+
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", runTimeout)
+	}
+	return runFinishedMsg{output: string(output), err: err, duration: time.Since(start)}
+}
+
+// runFuncInPane runs a built-in action for config and shows what it reports
+// in the results pane, the same way as a shell command.
+func (m *model) runFuncInPane(config models.ConfigEntry, title string, fn func() (string, error)) tea.Cmd {
+	m.run = runState{config: config, command: title, fn: fn, running: true}
+	m.runGen++
+	m.mode = ModeRunResult
+	gen := m.runGen
+	return func() tea.Msg {
+		start := time.Now()
+		output, err := fn()
+		return runFinishedMsg{gen: gen, output: output, err: err, duration: time.Since(start)}
+	}
+}
+
+// startRun runs the selected entry's command and shows the results pane.
+func (m *model) startRun() tea.Cmd {
+	config := m.getConfigByDisplayIndex(m.cursor)
+	if config == nil {
+		return nil
+	}
+	if config.Run == "" {
+		return showWarning("No run command set for " + config.Name + " (add one with e)")
+	}
+	if m.settings.ConfirmRun(false) {
+		m.actions = actionState{config: *config, items: []fileAction{{label: "Run " + config.Run, command: config.Run}}, confirming: true, single: true}
+		m.mode = ModeActions
+		return nil
+	}
+	return m.runInPane(*config, config.Run)
+}
+
+// runInPane runs command for config and shows its output in the results pane.
+func (m *model) runInPane(config models.ConfigEntry, command string) tea.Cmd {
+	m.run = runState{config: config, command: command, running: true}
+	m.runGen++
+	m.mode = ModeRunResult
+	return runEntryCommand(m.commandRunner(), m.runGen, config, command)
+}
+
+func (m *model) finishRun(msg runFinishedMsg) {
+	m.run.running = false
+	m.run.err = msg.err
+	m.run.duration = msg.duration
+	output := strings.TrimRight(strings.ReplaceAll(msg.output, "\r\n", "\n"), "\n")
+	if output != "" {
+		m.run.lines = strings.Split(output, "\n")
+	}
+}
+
+func (m model) runPanelStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("117")).
+		Padding(0, 1)
+}
+
+func (m model) runBodyHeight() int {
+	height := m.mainContentHeight() - m.runPanelStyle().GetVerticalFrameSize() - 2
+	if height < 1 {
+		height = 1
+	}
+	return height
+}
+
+func (m model) updateRunResult(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxScroll := len(m.run.lines) - m.runBodyHeight()
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = ModeNormal
+		m.run = runState{}
+		m.refreshRightViewport() // the command may have changed the file
+		return m, nil
+	case "x", "r":
+		if m.run.running {
+			return m, nil
+		}
+		if m.run.fn != nil {
+			return m, m.runFuncInPane(m.run.config, m.run.command, m.run.fn)
+		}
+		return m, m.runInPane(m.run.config, m.run.command)
+	case "j", "down":
+		m.run.scroll++
+	case "k", "up":
+		m.run.scroll--
+	case "g", "home":
+		m.run.scroll = 0
+	case "G", "end":
+		m.run.scroll = maxScroll
+	}
+	if m.run.scroll > maxScroll {
+		m.run.scroll = maxScroll
+	}
+	if m.run.scroll < 0 {
+		m.run.scroll = 0
+	}
+	return m, nil
+}
+
+// renderRunPanel shows the command output in place of the file list.
+func (m model) renderRunPanel() string {
+	panelStyle := m.runPanelStyle()
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render("$ " + m.run.command)
+	var result string
+	switch {
+	case m.run.running:
+		result = suitechrome.Dim("running…")
+	case m.run.err != nil:
+		result = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("✗ " + m.run.err.Error())
+	default:
+		result = lipgloss.NewStyle().Foreground(lipgloss.Color("82")).Render(fmt.Sprintf("✓ ok in %s", m.run.duration.Round(time.Millisecond)))
+	}
+
+	body := []string{suitechrome.JoinLine(innerWidth, title, result), ""}
+	if !m.run.running && len(m.run.lines) == 0 {
+		body = append(body, suitechrome.Dim("  (no output)"))
+	}
+	end := m.run.scroll + m.runBodyHeight()
+	if end > len(m.run.lines) {
+		end = len(m.run.lines)
+	}
+	for _, line := range m.run.lines[m.run.scroll:end] {
+		body = append(body, truncate(strings.ReplaceAll(line, "\t", "    "), innerWidth))
+	}
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(m.mainContentHeight() - panelStyle.GetVerticalFrameSize()).
+		Render(strings.Join(body, "\n"))
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRunCommandCapturesOutputAndFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh syntax")
+	}
+	dir := t.TempDir()
+	config := models.ConfigEntry{Name: "app", Path: filepath.Join(dir, "app.conf"), Run: `echo "checking $ZAP_FILE"; echo bad >&2; exit 3`}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{config}
+	m.buildDisplayList()
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	if m.mode != ModeRunResult || !m.run.running || cmd == nil {
+		t.Fatalf("x should start the run command, mode = %v", m.mode)
+	}
+
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	if m.run.running || m.run.err == nil {
+		t.Fatalf("run state = %+v, want a finished failing run", m.run)
+	}
+	if len(m.run.lines) != 2 || m.run.lines[0] != "checking "+config.Path || m.run.lines[1] != "bad" {
+		t.Fatalf("output = %q, want stdout and stderr", m.run.lines)
+	}
+}
+
+func TestAutoCheckRerunsOnChangeAndBadgesResult(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh syntax")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	if err := os.WriteFile(path, []byte("ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1,
+		storage: storage.New(filepath.Join(dir, "configs.json"))}
+	m.configs = []models.ConfigEntry{{Name: "app", Path: path, Run: `grep -q ok "$ZAP_FILE"`}}
+	m.buildDisplayList()
+
+	m.toggleAutoCheck()
+	if m.watcher != nil {
+		defer m.watcher.Close()
+	}
+	if !m.configs[0].AutoCheck || m.checkBadge(m.configs[0]) != " ⟳" {
+		t.Fatalf("auto-check not started: %+v", m.configs[0])
+	}
+	key := checkKey(m.configs[0])
+	m.finishCheck(checkFinishedMsg{path: key, result: execEntryCommand(m.configs[0], m.configs[0].Run)})
+	if m.checkBadge(m.configs[0]) != " ✓" {
+		t.Fatalf("badge after passing check = %q", m.checkBadge(m.configs[0]))
+	}
+
+	if err := os.WriteFile(path, []byte("bad\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	msg := m.checkChanged(key)()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		msg = batch[0]()
+	}
+	updated, _ := m.Update(msg)
+	m = updated.(model)
+	if m.checkBadge(m.configs[0]) != " ✗" {
+		t.Fatalf("badge after failing check = %q", m.checkBadge(m.configs[0]))
+	}
+	if saved, _ := m.storage.Load(); len(saved) != 1 || !saved[0].AutoCheck {
+		t.Fatalf("auto_check not saved: %+v", saved)
+	}
+}
+
+func TestAutoCheckIgnoresSharedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{{Name: "app", Path: path, Run: "touch pwned", AutoCheck: true, Shared: true}}
+	m.buildDisplayList()
+
+	if m.hasAutoCheck() {
+		t.Fatal("a shared entry's auto_check should not start the watcher")
+	}
+	if cmd := m.checkChanged(checkKey(m.configs[0])); cmd != nil {
+		t.Fatal("a shared entry's command ran when its file changed")
+	}
+}
+
+func TestLateRunResultIsDropped(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	config := models.ConfigEntry{Name: "app", Path: filepath.Join(t.TempDir(), "app.conf")}
+	first := m.runFuncInPane(config, "slow", func() (string, error) { return "first\n", nil })
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	second := m.runFuncInPane(config, "fast", func() (string, error) { return "second\n", nil })
+
+	updated, _ = m.Update(first())
+	m = updated.(model)
+	if !m.run.running || len(m.run.lines) != 0 {
+		t.Fatalf("the closed run's result reached the new pane: %+v", m.run)
+	}
+	updated, _ = m.Update(second())
+	if m = updated.(model); m.run.running || len(m.run.lines) != 1 || m.run.lines[0] != "second" {
+		t.Fatalf("run state = %+v, want the second run's output", m.run)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// scriptIdle is how long a script step waits for follow-up messages, such
+// as status updates, before moving on.
+const scriptIdle = 20 * time.Millisecond
+
+// scriptKeys maps key names, as the model matches them, to key types
+var scriptKeys = func() map[string]tea.KeyType {
+	keys := map[string]tea.KeyType{"space": tea.KeySpace}
+	for t := tea.KeyType(-100); t < 128; t++ {
+		if name := t.String(); name != "" && t != tea.KeyRunes {
+			if _, taken := keys[name]; !taken {
+				keys[name] = t
+			}
+		}
+	}
+	return keys
+}()
+
+// parseScriptKey turns a key name such as "enter", "ctrl+o", "alt+j" or
+// "N" into the message a terminal would send for it.
+func parseScriptKey(name string) (tea.KeyMsg, error) {
+	if t, ok := scriptKeys[name]; ok {
+		return tea.KeyMsg{Type: t}, nil
+	}
+	alt := false
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && rest != "" {
+		alt, name = true, rest
+		if t, ok := scriptKeys[name]; ok {
+			return tea.KeyMsg{Type: t, Alt: true}, nil
+		}
+	}
+	if runes := []rune(name); len(runes) == 1 {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: runes, Alt: alt}, nil
+	}
+	return tea.KeyMsg{}, fmt.Errorf("unknown key %q", name)
+}
+
+// scriptRunner drives a model the way tea.Program does, without a
+// terminal: commands run in the background and their messages are fed back
+// to Update.
+type scriptRunner struct {
+	m    model
+	msgs chan tea.Msg
+	quit bool
+}
+
+func newScriptRunner(m model, width, height int) *scriptRunner {
+	m.dryRun = true
+	r := &scriptRunner{m: m, msgs: make(chan tea.Msg, 64)}
+	r.exec(m.Init())
+	r.update(tea.WindowSizeMsg{Width: width, Height: height})
+	r.settle(0)
+	return r
+}
+
+func (r *scriptRunner) exec(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() { r.msgs <- cmd() }()
+}
+
+func (r *scriptRunner) update(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case nil:
+	case tea.QuitMsg:
+		r.quit = true
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			r.exec(cmd)
+		}
+	default:
+		updated, cmd := r.m.Update(msg)
+		r.m = updated.(model)
+		r.exec(cmd)
+	}
+}
+
+// settle handles messages until none arrive for scriptIdle, waiting at
+// least d in total.
+func (r *scriptRunner) settle(d time.Duration) {
+	deadline := time.Now().Add(d)
+	for !r.quit {
+		wait := scriptIdle
+		if rest := time.Until(deadline); rest > wait {
+			wait = rest
+		}
+		select {
+		case msg := <-r.msgs:
+			r.update(msg)
+		case <-time.After(wait):
+			return
+		}
+	}
+}
+
+// screen is the current view as plain text.
+func (r *scriptRunner) screen() string {
+	return xansi.Strip(r.m.View())
+}
+
+func runScript(ctx *cliContext, args []string) error {
+	const usage = "script [--demo|--live] [--size WxH] <file|->"
+	fs := ctx.flagSet("script")
+	demo := fs.Bool("demo", false, "Run against the --demo registry instead of yours")
+	live := fs.Bool("live", false, "Save changes to your registry instead of a scratch copy")
+	size := fs.String("size", "100x30", "Terminal size the script runs at")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 || (*demo && *live) {
+		return usageError(usage)
+	}
+	width, height, ok := parseSize(*size)
+	if !ok {
+		return invalidf("invalid --size %q, want WIDTHxHEIGHT", *size)
+	}
+
+	var in io.Reader = os.Stdin
+	if rest[0] != "-" {
+		f, err := os.Open(rest[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var (
+		store   *storage.Storage
+		configs []models.ConfigEntry
+		prefs   settings.Settings
+	)
+	if *demo {
+		var cleanup func()
+		if store, configs, cleanup, err = setupDemo(); err != nil {
+			return err
+		}
+		defer cleanup()
+	} else if store, configs, prefs, err = loadRegistry(); err != nil {
+		return err
+	} else if !*live {
+		var cleanup func()
+		if store, cleanup, err = scratchRegistry(store, configs); err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+	r := newScriptRunner(newModel(store, configs, prefs), width, height)
+	return playScript(ctx, r, in, rest[0])
+}
+
+// scratchRegistry copies the registry and its settings into a temporary
+// directory, so a script's deletions and edits leave the real one alone.
+func scratchRegistry(store *storage.Storage, configs []models.ConfigEntry) (*storage.Storage, func(), error) {
+	dir, err := os.MkdirTemp("", "zap-script-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("create scratch directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	scratch := store.CopyTo(filepath.Join(dir, filepath.Base(store.GetFilePath())))
+	if err := scratch.Save(configs); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if data, err := os.ReadFile(settings.PathFor(store.GetFilePath())); err == nil {
+		if err := os.WriteFile(settings.PathFor(scratch.GetFilePath()), data, 0644); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+	}
+	return scratch, cleanup, nil
+}
+
+// playScript runs each line of a script, stopping at the first failed
+// expectation. Lines are:
+//
+//	key <name>...   press keys, e.g. key N or key ctrl+o down enter
+//	type <text>     type text one character at a time
+//	expect <text>   fail unless the screen shows text
+//	reject <text>   fail if the screen shows text
+//	wait <duration> let slow commands finish, e.g. wait 500ms
+//	size <W>x<H>    resize the terminal
+//	print           write the screen to stdout
+//
+// Blank lines and lines starting with # are ignored.
+func playScript(ctx *cliContext, r *scriptRunner, in io.Reader, name string) error {
+	scanner := bufio.NewScanner(in)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if r.quit {
+			return invalidf("%s:%d: zap has already quit", name, lineNo)
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		switch verb {
+		case "key":
+			for _, key := range strings.Fields(arg) {
+				msg, err := parseScriptKey(key)
+				if err != nil {
+					return invalidf("%s:%d: %v", name, lineNo, err)
+				}
+				r.update(msg)
+				r.settle(0)
+			}
+		case "type":
+			for _, ch := range arg {
+				r.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{ch}})
+			}
+			r.settle(0)
+		case "expect", "reject":
+			screen := r.screen()
+			if strings.Contains(screen, arg) != (verb == "expect") {
+				fmt.Fprintln(ctx.stdout, screen)
+				if verb == "expect" {
+					return invalidf("%s:%d: expected %q on screen", name, lineNo, arg)
+				}
+				return invalidf("%s:%d: did not expect %q on screen", name, lineNo, arg)
+			}
+		case "wait":
+			d, err := time.ParseDuration(arg)
+			if err != nil {
+				return invalidf("%s:%d: %v", name, lineNo, err)
+			}
+			r.settle(d)
+		case "size":
+			width, height, ok := parseSize(arg)
+			if !ok {
+				return invalidf("%s:%d: invalid size %q, want WIDTHxHEIGHT", name, lineNo, arg)
+			}
+			r.update(tea.WindowSizeMsg{Width: width, Height: height})
+			r.settle(0)
+		case "print":
+			fmt.Fprintln(ctx.stdout, r.screen())
+		default:
+			return invalidf("%s:%d: unknown step %q", name, lineNo, verb)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return r.m.flushUsage()
+}
+
+// parseSize reads a terminal size written as WIDTHxHEIGHT.
+func parseSize(s string) (width, height int, ok bool) {
+	w, h, found := strings.Cut(s, "x")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !found || errW != nil || errH != nil || width < 1 || height < 1 {
+		return 0, 0, false
+	}
+	return width, height, true
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+)
+
+func TestScriptDrivesTheModelAndChecksTheScreen(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.New(filepath.Join(dir, "configs.json"))
+	configs := []models.ConfigEntry{
+		{Name: "nginx", Path: filepath.Join(dir, "nginx.conf"), Project: "web"},
+		{Name: "zshrc", Path: filepath.Join(dir, "zshrc"), Project: "dotfiles"},
+	}
+	r := newScriptRunner(newModel(store, configs, settings.Settings{}), 100, 30)
+
+	script := `
+# search narrows the list
+key /
+type zsh
+key enter
+expect 1/2 files
+reject nginx
+key esc ctrl+o
+expect Quick open
+`
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out}
+	if err := playScript(ctx, r, strings.NewReader(script), "test"); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out.String())
+	}
+
+	err := playScript(ctx, r, strings.NewReader("expect nothing like this"), "test")
+	if exitCode(err) != exitInvalid || !strings.Contains(err.Error(), "test:1") {
+		t.Fatalf("failed expectation returned %v (exit %d)", err, exitCode(err))
+	}
+	if !strings.Contains(out.String(), "Quick open") {
+		t.Fatal("failed expectation didn't print the screen")
+	}
+}
+
+func TestParseScriptKey(t *testing.T) {
+	for name, want := range map[string]string{"enter": "enter", "ctrl+o": "ctrl+o", "N": "N", "space": " ", "alt+j": "alt+j", "shift+tab": "shift+tab"} {
+		msg, err := parseScriptKey(name)
+		if err != nil || msg.String() != want {
+			t.Errorf("parseScriptKey(%q) = %q, %v; want %q", name, msg.String(), err, want)
+		}
+	}
+	if _, err := parseScriptKey("hyper+x"); err == nil {
+		t.Error("unknown key accepted")
+	}
+}
+
+func TestScriptLeavesTheRegistryAndSystemAlone(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh syntax")
+	}
+	dir := t.TempDir()
+	registry := filepath.Join(dir, "zap-registry.json")
+	t.Setenv(registryPathEnv, registry)
+	marker := filepath.Join(dir, "ran")
+	if err := storage.New(registry).Save([]models.ConfigEntry{
+		{Name: "nginx", Path: filepath.Join(dir, "nginx.conf"), Run: "touch " + marker},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	steps := filepath.Join(dir, "steps.zap")
+	if err := os.WriteFile(steps, []byte("key x\nwait 100ms\nexpect dry run\nkey esc D y\nexpect 0 files\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runScript(&cliContext{stdout: &out, stderr: &out}, []string{steps}); err != nil {
+		t.Fatalf("script: %v\n%s", err, out.String())
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatal("the run command was executed")
+	}
+	if configs, _ := storage.New(registry).Load(); len(configs) != 1 {
+		t.Fatalf("the deletion reached the real registry: %+v", configs)
+	}
+}
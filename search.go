@@ -0,0 +1,183 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldCase folds s for case-insensitive matching using full Unicode case
+// folding, so "STRASSE" matches "straße" and "ΣΟΦΊΑ" matches "σοφία".
+func foldCase(s string) string {
+	return cases.Fold().String(norm.NFC.String(s))
+}
+
+// stripAccents removes combining marks so "café" matches "cafe".
+func stripAccents(s string) string {
+	out, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		return s
+	}
+	return out
+}
+
+// searchOptions controls how queries are compared with entries.
+type searchOptions struct {
+	fold     func(string) string // applied to queries and entry fields before matching
+	basename bool                // rank the file's base name as highly as the entry name
+	fuzzy    bool                // match terms as fuzzy patterns rather than substrings
+}
+
+// searchOptions returns the matching behaviour chosen in settings and the
+// current fuzzy toggle.
+func (m model) searchOptions() searchOptions {
+	opts := searchOptionsFor(m.settings)
+	opts.fuzzy = m.fuzzyMode
+	return opts
+}
+
+func searchOptionsFor(prefs settings.Settings) searchOptions {
+	opts := searchOptions{fold: foldCase, basename: prefs.MatchBasename}
+	if prefs.IgnoreAccents {
+		opts.fold = func(s string) string { return foldCase(stripAccents(s)) }
+	}
+	return opts
+}
+
+// basenameIs reports whether config's file is named query, which has
+// already been folded.
+func (o searchOptions) basenameIs(config models.ConfigEntry, query string) bool {
+	return query != "" && o.fold(filepath.Base(config.Path)) == query
+}
+
+// queryTerm is one word or "quoted phrase" of a search, already folded.
+// Negated terms, written -term, must not match.
+type queryTerm struct {
+	text   string
+	negate bool
+}
+
+// parseQuery splits a search into groups separated by OR. An entry matches
+// when every term of any one group does, so `yaml -test` finds yaml files
+// that aren't test fixtures and `nginx OR caddy` finds either. OR is only an
+// operator in capitals, so the query is folded term by term afterwards.
+func parseQuery(query string, fold func(string) string) [][]queryTerm {
+	var groups [][]queryTerm
+	var group []queryTerm
+	for _, token := range splitQuery(query) {
+		if token.text == "OR" && !token.quoted {
+			if len(group) > 0 {
+				groups = append(groups, group)
+			}
+			group = nil
+			continue
+		}
+		term := queryTerm{text: token.text}
+		if rest, ok := strings.CutPrefix(token.text, "-"); ok && rest != "" && !token.literal {
+			term = queryTerm{text: rest, negate: true}
+		}
+		term.text = fold(term.text)
+		group = append(group, term)
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// queryToken is a space-separated part of a query with its quotes removed.
+type queryToken struct {
+	text    string
+	quoted  bool // contained a quoted section
+	literal bool // started with a quote, so a leading - is part of the text
+}
+
+// splitQuery breaks a query at spaces outside "double quotes".
+func splitQuery(query string) []queryToken {
+	var tokens []queryToken
+	var text strings.Builder
+	token := queryToken{}
+	inQuotes, started := false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if !started {
+				token.literal = true
+			}
+			inQuotes = !inQuotes
+			token.quoted, started = true, true
+		case unicode.IsSpace(r) && !inQuotes:
+			if started {
+				token.text = text.String()
+				tokens = append(tokens, token)
+			}
+			text.Reset()
+			token, started = queryToken{}, false
+		default:
+			text.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		token.text = text.String()
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// matchesSearch reports whether config matches any group of a parsed query,
+// that is every term of the group. An empty query matches everything.
+func matchesSearch(config models.ConfigEntry, groups [][]queryTerm, opts searchOptions) bool {
+	if len(groups) == 0 {
+		return true
+	}
+	for _, group := range groups {
+		matched := true
+		for _, term := range group {
+			if matchesTerm(config, term.text, opts) == term.negate {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTerm reports whether config matches one search term, which has
+// already been passed through opts.fold.
+func matchesTerm(config models.ConfigEntry, query string, opts searchOptions) bool {
+	fold := opts.fold
+	if config.Alias != "" && fold(config.Alias) == query {
+		return true
+	}
+	// Keywords and names the entry had before being renamed still find it
+	for _, term := range config.SearchTerms() {
+		if (opts.fuzzy && fuzzyMatch(query, fold(term))) || strings.Contains(fold(term), query) {
+			return true
+		}
+	}
+	if opts.fuzzy {
+		return fuzzyMatch(query, fold(config.Name)) ||
+			fuzzyMatch(query, fold(config.Project)) ||
+			fuzzyMatch(query, fold(config.Path)) ||
+			fuzzyMatch(query, fold(config.Description))
+	}
+
+	// Normal substring search
+	return strings.Contains(fold(config.Name), query) ||
+		strings.Contains(fold(config.Project), query) ||
+		strings.Contains(fold(config.Type), query) ||
+		strings.Contains(fold(config.Path), query) ||
+		strings.Contains(fold(config.Description), query)
+}
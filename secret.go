@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// secretTimeout stops secret lookups waiting forever on an unanswered
+// passphrase or sign-in prompt.
+const secretTimeout = time.Minute
+
+// secretMask stands in for a hidden secret; it doesn't give away the length.
+const secretMask = "••••••••••••"
+
+// secretState is the secret pane. The value lives only here, and only while
+// the pane is open; it is never written to the registry.
+type secretState struct {
+	config   models.ConfigEntry
+	loading  bool
+	value    string
+	err      error
+	revealed bool
+}
+
+// secretFetchedMsg carries the value looked up for a secret reference.
+type secretFetchedMsg struct {
+	ref   string
+	value string
+	err   error
+}
+
+// parseSecretRef splits a secret reference into the command that reads it:
+// pass:<path> for pass, op://vault/item/field for the 1Password CLI.
+func parseSecretRef(ref string) (name string, args []string, err error) {
+	switch {
+	case strings.HasPrefix(ref, "pass:"):
+		path := strings.TrimPrefix(ref, "pass:")
+		if path == "" {
+			return "", nil, fmt.Errorf("pass reference needs a path, e.g. pass:web/stripe")
+		}
+		if strings.HasPrefix(path, "-") {
+			return "", nil, fmt.Errorf("pass path %q can't start with -", path)
+		}
+		return "pass", []string{"show", "--", path}, nil
+	case strings.HasPrefix(ref, "op://"):
+		if strings.Count(strings.TrimPrefix(ref, "op://"), "/") < 2 {
+			return "", nil, fmt.Errorf("1Password reference must be op://vault/item/field")
+		}
+		return "op", []string{"read", "--no-newline", ref}, nil
+	}
+	return "", nil, fmt.Errorf("secret must be pass:<path> or op://vault/item/field")
+}
+
+// readSecret asks the secret manager for ref's value. For pass only the
+// first line is used, where pass keeps the password itself.
+func readSecret(ref string) (string, error) {
+	name, args, err := parseSecretRef(ref)
+	if err != nil {
+		return "", err
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed", name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s timed out after %s", name, secretTimeout)
+		}
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	value := strings.TrimRight(stdout.String(), "\r\n")
+	if name == "pass" {
+		value, _, _ = strings.Cut(value, "\n")
+	}
+	return value, nil
+}
+
+// openSecret looks up the selected entry's secret in the background and
+// shows it, masked, in the secret pane.
+func (m *model) openSecret() tea.Cmd {
+	config := m.getConfigByDisplayIndex(m.cursor)
+	if config == nil {
+		return nil
+	}
+	if config.Secret == "" {
+		return showWarning("No secret set for " + config.Name + " (add one with e)")
+	}
+	ref := config.Secret
+	m.secret = secretState{config: *config, loading: true}
+	m.mode = ModeSecret
+	return func() tea.Msg {
+		value, err := readSecret(ref)
+		return secretFetchedMsg{ref: ref, value: value, err: err}
+	}
+}
+
+func (m *model) finishSecret(msg secretFetchedMsg) {
+	if m.mode != ModeSecret || m.secret.config.Secret != msg.ref {
+		return // the pane was closed meanwhile; drop the value
+	}
+	m.secret.loading = false
+	m.secret.value = msg.value
+	m.secret.err = msg.err
+}
+
+func (m model) updateSecret(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	ready := !m.secret.loading && m.secret.err == nil
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = ModeNormal
+		m.secret = secretState{}
+	case " ":
+		if ready {
+			m.secret.revealed = !m.secret.revealed
+		}
+	case "y":
+		if !ready {
+			return m, nil
+		}
+		if err := copyToClipboard(m.secret.value); err != nil {
+			return m, showError(fmt.Sprintf("Clipboard error: %v", err))
+		}
+		return m, showSuccess("Copied secret for " + m.secret.config.Name)
+	case "x":
+		if !ready {
+			return m, nil
+		}
+		config := m.secret.config
+		if config.Run == "" {
+			return m, showWarning("No run command set for " + config.Name + " (add one with e)")
+		}
+		env := "ZAP_SECRET=" + m.secret.value
+		m.secret = secretState{}
+		exec := m.commandRunner()
+		return m, m.runFuncInPane(config, config.Run+"  (with $ZAP_SECRET)", func() (string, error) {
+			result := exec(config, config.Run, env)
+			return result.output, result.err
+		})
+	}
+	return m, nil
+}
+
+// renderSecretPanel shows the looked-up secret in place of the file list,
+// masked until space reveals it.
+func (m model) renderSecretPanel() string {
+	panelStyle := m.runPanelStyle()
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render("🔑 " + m.secret.config.Secret)
+	var value string
+	switch {
+	case m.secret.loading:
+		value = suitechrome.Dim("reading secret…")
+	case m.secret.err != nil:
+		value = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("✗ " + m.secret.err.Error())
+	case m.secret.revealed:
+		value = truncate(m.secret.value, innerWidth)
+	default:
+		value = secretMask + suitechrome.Dim("  (space to reveal)")
+	}
+
+	body := []string{truncate(title, innerWidth), "", value}
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(m.mainContentHeight() - panelStyle.GetVerticalFrameSize()).
+		Render(strings.Join(body, "\n"))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	for ref, want := range map[string]string{
+		"pass:web/stripe":         "pass show -- web/stripe",
+		"pass:--clip":             "",
+		"pass:-c web/stripe":      "",
+		"op://Private/db/pass":    "op read --no-newline op://Private/db/pass",
+		"pass:":                   "",
+		"op://Private/db":         "",
+		"vault:secret/data/db":    "",
+		"/home/me/.secrets/token": "",
+	} {
+		name, args, err := parseSecretRef(ref)
+		got := strings.Join(append([]string{name}, args...), " ")
+		if want == "" {
+			if err == nil {
+				t.Errorf("parseSecretRef(%q) = %q, want an error", ref, got)
+			}
+		} else if err != nil || got != want {
+			t.Errorf("parseSecretRef(%q) = %q, %v, want %q", ref, got, err, want)
+		}
+	}
+}
+
+func TestSecretPaneMasksValueAndRunsWithIt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a sh script as pass")
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\n[ \"$1\" = show ] && [ \"$2\" = -- ] && [ \"$3\" = web/stripe ] || exit 1\nprintf 'sk_live_42\\nuser: shop\\n'\n"
+	if err := os.WriteFile(filepath.Join(bin, "pass"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{{Name: "env", Path: filepath.Join(dir, ".env"), Secret: "pass:web/stripe", Run: `echo "key=$ZAP_SECRET"`}}
+	m.buildDisplayList()
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'V'}})
+	m = updated.(model)
+	if m.mode != ModeSecret || cmd == nil {
+		t.Fatalf("V should open the secret pane, mode = %v", m.mode)
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	if m.secret.value != "sk_live_42" {
+		t.Fatalf("secret = %q, want the first line of pass show", m.secret.value)
+	}
+	if screen := xansi.Strip(m.View()); strings.Contains(screen, "sk_live_42") || !strings.Contains(screen, secretMask) {
+		t.Fatalf("secret should be masked until revealed:\n%s", screen)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updated.(model)
+	if screen := xansi.Strip(m.View()); !strings.Contains(screen, "sk_live_42") {
+		t.Fatalf("space should reveal the secret:\n%s", screen)
+	}
+
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	if m.mode != ModeRunResult || m.secret.value != "" {
+		t.Fatalf("x should run the command and drop the pane, mode = %v", m.mode)
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	if m.run.err != nil || len(m.run.lines) != 1 || m.run.lines[0] != "key=sk_live_42" {
+		t.Fatalf("run = %q, %v, want the secret in $ZAP_SECRET", m.run.lines, m.run.err)
+	}
+	if strings.Contains(m.run.command, "sk_live_42") {
+		t.Fatalf("pane title %q leaks the secret", m.run.command)
+	}
+}
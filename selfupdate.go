@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Release locations, the same ones install.sh uses. Variables so tests can
+// point them at a local server.
+var (
+	releaseAPIURL      = "https://api.github.com/repos/LFroesch/zap/releases/latest"
+	releaseDownloadURL = "https://github.com/LFroesch/zap/releases/download"
+)
+
+var updateClient = &http.Client{Timeout: 60 * time.Second}
+
+// releaseAsset is the binary name the release workflow builds for this
+// platform, e.g. zap-linux-amd64.
+func releaseAsset() string {
+	name := fmt.Sprintf("zap-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+func runUpdate(ctx *cliContext, args []string) error {
+	const usage = "update [--check] [--force] [--version tag]"
+	fs := ctx.flagSet("update")
+	check := fs.Bool("check", false, "Only report whether a newer release exists")
+	force := fs.Bool("force", false, "Replace a development build that has no release version")
+	want := fs.String("version", "", "Install this release tag instead of the latest")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageError(usage)
+	}
+
+	tag := *want
+	if tag == "" {
+		if tag, err = latestRelease(); err != nil {
+			return err
+		}
+	}
+	target, ok := parseVersion(tag)
+	if !ok {
+		return invalidf("%q is not a release version like v1.2.3", tag)
+	}
+	current, released := parseVersion(version)
+	if released {
+		switch cmp := compareVersions(target, current); {
+		case cmp == 0:
+			fmt.Fprintf(ctx.stdout, "zap %s is up to date\n", version)
+			return nil
+		case cmp < 0:
+			if *want == "" {
+				fmt.Fprintf(ctx.stdout, "zap %s is newer than the latest release %s\n", version, tag)
+				return nil
+			}
+			return invalidf("zap %s is newer than %s; update won't downgrade", version, tag)
+		}
+	}
+	if *check {
+		fmt.Fprintf(ctx.stdout, "zap %s is available (running %s); run zap update to install it\n", tag, version)
+		return nil
+	}
+	// A dev or git describe build may be ahead of every release, so only
+	// replace it when asked to
+	if !released && !*force {
+		return invalidf("zap %s is not a release build; use --force to replace it with %s", version, tag)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	if err := installRelease(tag, exe); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.stdout, "updated zap %s → %s (%s)\n", version, tag, exe)
+	return nil
+}
+
+// parseVersion reads a release version such as v1.2.3. Pre-releases and
+// git describe output (v1.2.3-4-gabcdef) aren't releases and don't parse.
+func parseVersion(s string) ([3]int, bool) {
+	var v [3]int
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) != len(v) {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part != strconv.Itoa(n) {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, the same as or
+// newer than b.
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// latestRelease returns the tag of the newest GitHub release.
+func latestRelease() (string, error) {
+	data, err := download(releaseAPIURL)
+	if err != nil {
+		return "", fmt.Errorf("check for releases: %w", err)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.Unmarshal(data, &release); err != nil || release.TagName == "" {
+		return "", fmt.Errorf("check for releases: unexpected response from %s", releaseAPIURL)
+	}
+	return release.TagName, nil
+}
+
+// installRelease downloads this platform's binary for tag, checks it
+// against the release's checksums.txt and replaces exe with it.
+func installRelease(tag, exe string) error {
+	asset := releaseAsset()
+	base := releaseDownloadURL + "/" + tag + "/"
+	binary, err := download(base + asset)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", asset, err)
+	}
+	sums, err := download(base + "checksums.txt")
+	if err != nil {
+		return fmt.Errorf("download checksums: %w", err)
+	}
+	expected, ok := checksumFor(sums, asset)
+	if !ok {
+		return fmt.Errorf("checksums.txt for %s has no entry for %s", tag, asset)
+	}
+	sum := sha256.Sum256(binary)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", asset, actual, expected)
+	}
+	return replaceExecutable(exe, binary)
+}
+
+// checksumFor finds name in sha256sum output.
+func checksumFor(sums []byte, name string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+// replaceExecutable swaps exe for data. The new binary is written next to
+// it and renamed into place so a failed write leaves the old one intact.
+// Windows can't overwrite a running program, so the old file is moved aside
+// first and left for the next update to remove, or moved back if the new
+// one can't take its place.
+func replaceExecutable(exe string, data []byte) error {
+	dir := filepath.Dir(exe)
+	tmp, err := os.CreateTemp(dir, ".zap-update-*")
+	if err != nil {
+		return fmt.Errorf("can't write to %s: %w", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), exe); err != nil {
+			// Put the old binary back rather than leave no zap at all
+			os.Rename(old, exe)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), exe)
+}
+
+func download(url string) ([]byte, error) {
+	resp, err := updateClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
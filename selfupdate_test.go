@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunUpdateVerifiesAndReplacesBinary(t *testing.T) {
+	newBinary := []byte("new zap binary")
+	sum := sha256.Sum256(newBinary)
+	checksums := hex.EncodeToString(sum[:]) + "  " + releaseAsset() + "\n"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v9.9.9"}`)
+	})
+	mux.HandleFunc("/download/v9.9.9/"+releaseAsset(), func(w http.ResponseWriter, r *http.Request) {
+		w.Write(newBinary)
+	})
+	mux.HandleFunc("/download/v9.9.9/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, checksums)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	oldAPI, oldDownload := releaseAPIURL, releaseDownloadURL
+	releaseAPIURL, releaseDownloadURL = server.URL+"/latest", server.URL+"/download"
+	defer func() { releaseAPIURL, releaseDownloadURL = oldAPI, oldDownload }()
+
+	oldVersion := version
+	version = "v1.2.0"
+	defer func() { version = oldVersion }()
+
+	var out bytes.Buffer
+	ctx := &cliContext{stdout: &out, stderr: &out}
+	if err := runUpdate(ctx, []string{"--check"}); err != nil || !strings.Contains(out.String(), "v9.9.9 is available") {
+		t.Fatalf("update --check = %v, output %q", err, out.String())
+	}
+
+	exe := filepath.Join(t.TempDir(), "zap")
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := installRelease("v9.9.9", exe); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(exe); !bytes.Equal(data, newBinary) {
+		t.Fatalf("executable = %q, want the downloaded binary", data)
+	}
+
+	checksums = strings.Repeat("0", 64) + "  " + releaseAsset() + "\n"
+	if err := installRelease("v9.9.9", exe); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("installRelease with a bad checksum = %v, want a mismatch error", err)
+	}
+}
+
+func TestRunUpdateRefusesDowngradesAndDevBuilds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v1.10.0"}`)
+	}))
+	defer server.Close()
+	oldAPI := releaseAPIURL
+	releaseAPIURL = server.URL
+	defer func() { releaseAPIURL = oldAPI }()
+	oldVersion := version
+	defer func() { version = oldVersion }()
+
+	for _, tc := range []struct {
+		version string
+		args    []string
+		want    string // in the output, or the error if wantErr
+		wantErr bool
+	}{
+		{version: "v1.10.0", want: "is up to date"},
+		{version: "v1.11.2", want: "newer than the latest release"},
+		{version: "v1.9.0", args: []string{"--check"}, want: "v1.10.0 is available"},
+		{version: "v1.10.0", args: []string{"--version", "v1.2.0"}, want: "won't downgrade", wantErr: true},
+		{version: "v1.10.0", args: []string{"--version", "latest"}, want: "not a release version", wantErr: true},
+		{version: "dev", want: "not a release build", wantErr: true},
+		{version: "v1.9.0-3-g1a2b3c4", want: "not a release build", wantErr: true},
+		{version: "dev", args: []string{"--check"}, want: "v1.10.0 is available"},
+	} {
+		version = tc.version
+		var out bytes.Buffer
+		err := runUpdate(&cliContext{stdout: &out, stderr: &out}, tc.args)
+		if tc.wantErr {
+			if err == nil || !strings.Contains(err.Error(), tc.want) || exitCode(err) != exitInvalid {
+				t.Errorf("zap %s: update %v = %v, want an error containing %q", tc.version, tc.args, err, tc.want)
+			}
+			continue
+		}
+		if err != nil || !strings.Contains(out.String(), tc.want) {
+			t.Errorf("zap %s: update %v = %v, output %q, want %q", tc.version, tc.args, err, out.String(), tc.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.10.0", "v1.9.9", 1},
+		{"1.2.3", "v1.2.4", -1},
+		{"v2.0.0", "v1.99.99", 1},
+	} {
+		a, okA := parseVersion(tc.a)
+		b, okB := parseVersion(tc.b)
+		if !okA || !okB {
+			t.Fatalf("parseVersion(%q, %q) failed", tc.a, tc.b)
+		}
+		if got := compareVersions(a, b); got != tc.want {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+	for _, s := range []string{"dev", "v1.2", "v1.2.3-rc1", "v1.2.3-4-gabcdef", "v1.02.3", "abc123def"} {
+		if _, ok := parseVersion(s); ok {
+			t.Errorf("parseVersion(%q) should not be a release", s)
+		}
+	}
+}
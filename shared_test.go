@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSharedEntriesAreOverlaidAndNeverSaved(t *testing.T) {
+	local := []models.ConfigEntry{{Name: "my nginx", Path: "/etc/nginx/nginx.conf"}}
+	shared := []models.ConfigEntry{
+		{Name: "team nginx", Path: "/etc/nginx/nginx.conf"},
+		{Name: "team hosts", Path: "/etc/hosts"},
+	}
+	merged := storage.MergeShared(local, shared)
+	if len(merged) != 2 || merged[0].Name != "my nginx" || merged[1].Name != "team hosts" || !merged[1].Shared {
+		t.Fatalf("MergeShared = %+v, want local override plus shared hosts", merged)
+	}
+
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	if err := store.Save(merged); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := store.Load()
+	if len(saved) != 1 || saved[0].Name != "my nginx" {
+		t.Fatalf("saved = %+v, want only the local entry", saved)
+	}
+}
+
+func TestEditingSharedEntrySavesLocalOverride(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	configs := storage.MergeShared(nil, []models.ConfigEntry{{Name: "hosts", Path: "/etc/hosts"}})
+	m := model{width: 100, height: 24, storage: store, configs: configs, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.buildDisplayList()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	if updated.(model).mode == ModeConfirmDelete {
+		t.Fatal("shared entry offered for deletion")
+	}
+
+	m.startEdit()
+	m.textInput.SetValue("my hosts")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	saved, _ := store.Load()
+	if len(saved) != 1 || saved[0].Name != "my hosts" {
+		t.Fatalf("saved = %+v, want the edit stored as a local override", saved)
+	}
+}
+
+func TestRegistryInsideRepoStoresRelativePaths(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "team")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	registry := filepath.Join(repo, "zap", "zap-registry.json")
+	store := storage.New(registry)
+	if err := store.Save([]models.ConfigEntry{{Name: "app", Path: filepath.Join(repo, "infra", "app.conf")}}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(registry); strings.Contains(string(data), "../infra") {
+		t.Fatalf("paths were made relative without relative_paths:\n%s", data)
+	}
+
+	store.SetRelativePaths(true)
+	store.SetProjects([]models.Project{{Name: "infra", Root: filepath.Join(repo, "infra")}})
+	if err := store.Save([]models.ConfigEntry{
+		{Name: "app", Path: filepath.Join(repo, "infra", "app.conf")},
+		{Name: "hosts", Path: "/etc/hosts"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(registry)
+	for _, want := range []string{`"path": "../infra/app.conf"`, `"path": "/etc/hosts"`, `"root": "../infra"`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("registry missing %s:\n%s", want, data)
+		}
+	}
+
+	copied := filepath.Join(repo, "zap", "copy.json")
+	if err := store.CopyTo(copied).Save([]models.ConfigEntry{{Name: "app", Path: filepath.Join(repo, "infra", "app.conf")}}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(copied); !strings.Contains(string(data), `"path": "../infra/app.conf"`) {
+		t.Fatalf("a copy should keep saving relative paths:\n%s", data)
+	}
+
+	clone := filepath.Join(base, "elsewhere")
+	if err := os.Rename(repo, clone); err != nil {
+		t.Fatal(err)
+	}
+	moved := storage.New(filepath.Join(clone, "zap", "zap-registry.json"))
+	configs, err := moved.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(clone, "infra", "app.conf"); configs[0].Path != want {
+		t.Fatalf("loaded path = %q, want %q", configs[0].Path, want)
+	}
+	if root := moved.Projects()[0].Root; root != filepath.Join(clone, "infra") {
+		t.Fatalf("loaded project root = %q", root)
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statsNeverOpenedLimit caps the never-opened list on the dashboard.
+const statsNeverOpenedLimit = 5
+
+// statsDeletedLimit caps the recently deleted list, each with a number key
+// that restores it.
+const statsDeletedLimit = 5
+
+// statCount is one bar in a stats chart.
+type statCount struct {
+	label string
+	count int
+}
+
+// registryStats summarises the registry for the stats dashboard.
+type registryStats struct {
+	total       int
+	byProject   []statCount
+	byType      []statCount
+	byTag       []statCount
+	broken      int
+	totalSize   int64
+	neverOpened []models.ConfigEntry  // oldest registrations first
+	activity    map[string]int        // opens per day, from the registry's activity log
+	deleted     []models.DeletedEntry // recent removals, most recent first
+}
+
+// computeStats gathers counts and file sizes. It stats every registered
+// path, so it runs once when the dashboard opens rather than per frame.
+func computeStats(configs []models.ConfigEntry) registryStats {
+	stats := registryStats{total: len(configs)}
+	projects := map[string]int{}
+	types := map[string]int{}
+	tags := map[string]int{}
+
+	for _, config := range configs {
+		project := config.Project
+		if project == "" {
+			project = "General"
+		}
+		projects[project]++
+		fileType := config.Type
+		if fileType == "" {
+			fileType = "(none)"
+		}
+		types[fileType]++
+		for _, tag := range config.Tags {
+			tags[tag]++
+		}
+
+		info, err := os.Stat(editor.ExpandPath(config.Path))
+		if err != nil {
+			stats.broken++
+		} else if !info.IsDir() {
+			stats.totalSize += info.Size()
+		}
+
+		if config.LastOpened.IsZero() {
+			stats.neverOpened = append(stats.neverOpened, config)
+		}
+	}
+
+	stats.byProject = sortedCounts(projects)
+	stats.byType = sortedCounts(types)
+	stats.byTag = sortedCounts(tags)
+
+	sort.SliceStable(stats.neverOpened, func(i, j int) bool {
+		return stats.neverOpened[i].Added.Before(stats.neverOpened[j].Added)
+	})
+	return stats
+}
+
+// sortedCounts orders counts by size, then label.
+func sortedCounts(counts map[string]int) []statCount {
+	out := make([]statCount, 0, len(counts))
+	for label, count := range counts {
+		out = append(out, statCount{label: label, count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].count != out[j].count {
+			return out[i].count > out[j].count
+		}
+		return strings.ToLower(out[i].label) < strings.ToLower(out[j].label)
+	})
+	return out
+}
+
+// formatSize renders a byte count in human units.
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func (m model) updateStats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxScroll := len(m.statsLines()) - 1
+	switch msg.String() {
+	case "esc", "q", "i":
+		m.mode = ModeNormal
+		m.statsScroll = 0
+	case "j", "down":
+		m.statsScroll++
+	case "k", "up":
+		m.statsScroll--
+	case "g", "home":
+		m.statsScroll = 0
+	case "G", "end":
+		m.statsScroll = maxScroll
+	case "1", "2", "3", "4", "5":
+		i := int(msg.String()[0] - '1')
+		if i < len(m.stats.deleted) {
+			return m, m.restoreDeleted(i)
+		}
+	}
+	if m.statsScroll > maxScroll {
+		m.statsScroll = maxScroll
+	}
+	if m.statsScroll < 0 {
+		m.statsScroll = 0
+	}
+	return m, nil
+}
+
+// statsLines renders the dashboard body, one string per line.
+func (m model) statsLines() []string {
+	stats := m.stats
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("117"))
+	valueStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+
+	lines := []string{
+		fmt.Sprintf("%s files · %s tracked · %s missing",
+			valueStyle.Render(fmt.Sprintf("%d", stats.total)),
+			valueStyle.Render(formatSize(stats.totalSize)),
+			valueStyle.Render(fmt.Sprintf("%d", stats.broken))),
+		"",
+	}
+
+	lines = append(lines, headerStyle.Render("Activity"))
+	lines = append(lines, renderHeatmap(stats.activity, time.Now(), heatmapWeeks(m.width-8))...)
+	lines = append(lines, "")
+
+	chart := func(title string, counts []statCount) {
+		lines = append(lines, headerStyle.Render(title))
+		if len(counts) == 0 {
+			lines = append(lines, suitechrome.Dim("  none"), "")
+			return
+		}
+		lines = append(lines, renderBars(counts, m.width-8)...)
+		lines = append(lines, "")
+	}
+	chart("By project", stats.byProject)
+	chart("By type", stats.byType)
+	chart("By tag", stats.byTag)
+
+	lines = append(lines, headerStyle.Render("Never opened"))
+	if len(stats.neverOpened) == 0 {
+		lines = append(lines, suitechrome.Dim("  every file has been opened"))
+	}
+	for i, config := range stats.neverOpened {
+		if i == statsNeverOpenedLimit {
+			lines = append(lines, suitechrome.Dim(fmt.Sprintf("  … %d more", len(stats.neverOpened)-i)))
+			break
+		}
+		added := "added date unknown"
+		if !config.Added.IsZero() {
+			added = "added " + m.formatTime(config.Added, true)
+		}
+		lines = append(lines, "  "+config.Name+"  "+suitechrome.Dim(added))
+	}
+
+	if len(stats.deleted) > 0 {
+		lines = append(lines, "", headerStyle.Render("Recently deleted"))
+	}
+	for i, entry := range stats.deleted {
+		if i == statsDeletedLimit {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s  %s", valueStyle.Render(fmt.Sprint(i+1)), entry.Name,
+			suitechrome.Dim("deleted "+m.formatTime(entry.Deleted, true)+" · "+contractHome(entry.Path))))
+	}
+	return lines
+}
+
+// restoreDeleted puts the i-th recently deleted entry back in the registry.
+func (m *model) restoreDeleted(i int) tea.Cmd {
+	entry := m.stats.deleted[i]
+	if dup := storage.FindDuplicates(m.configs, entry.Path); dup != nil {
+		return showWarning(fmt.Sprintf("%s is registered again as '%s'", contractHome(entry.Path), dup.Name))
+	}
+	config := entry.ConfigEntry
+	if config.Alias != "" && models.FindByAlias(m.configs, config.Alias) != nil {
+		config.Alias = ""
+	}
+	configs := append(append([]models.ConfigEntry(nil), m.configs...), config)
+	m.storage.ForgetDeleted(i)
+	if err := m.storage.Save(configs); err != nil {
+		return showError(fmt.Sprintf("Failed to save: %v", err))
+	}
+	m.configs = configs
+	m.stats = computeStats(m.configs)
+	m.stats.activity = m.storage.Activity()
+	m.stats.deleted = m.storage.Deleted()
+	m.cacheValid = false
+	m.buildDisplayList()
+	m.refreshRightViewport()
+	return showSuccess("Restored " + config.Name)
+}
+
+// renderBars draws a horizontal bar chart scaled to the largest count.
+func renderBars(counts []statCount, width int) []string {
+	labelWidth := 4
+	maxCount := 0
+	for _, c := range counts {
+		if w := lipgloss.Width(c.label); w > labelWidth {
+			labelWidth = w
+		}
+		if c.count > maxCount {
+			maxCount = c.count
+		}
+	}
+	if labelWidth > 20 {
+		labelWidth = 20
+	}
+	barWidth := width - labelWidth - 10
+	if barWidth < 5 {
+		barWidth = 5
+	}
+
+	barStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+	var lines []string
+	for _, c := range counts {
+		n := c.count * barWidth / maxCount
+		if n < 1 {
+			n = 1
+		}
+		label := truncate(c.label, labelWidth)
+		lines = append(lines, fmt.Sprintf("  %-*s %s %d", labelWidth, label, barStyle.Render(strings.Repeat("█", n)), c.count))
+	}
+	return lines
+}
+
+// renderStatsPanel renders the dashboard in place of the file list.
+func (m model) renderStatsPanel() string {
+	height := m.mainContentHeight()
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("117")).
+		Padding(0, 1)
+	bodyHeight := height - panelStyle.GetVerticalFrameSize() - 2
+	if bodyHeight < 1 {
+		bodyHeight = 1
+	}
+
+	lines := m.statsLines()
+	start := m.statsScroll
+	if start > len(lines) {
+		start = len(lines)
+	}
+	end := start + bodyHeight
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render("Registry stats")
+	body := append([]string{title, ""}, lines[start:end]...)
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(height - panelStyle.GetVerticalFrameSize()).
+		Render(strings.Join(body, "\n"))
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestComputeStatsCountsBrokenAndNeverOpened(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(present, []byte("key: value\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	stats := computeStats([]models.ConfigEntry{
+		{Name: "app", Path: present, Type: "yaml", Project: "web", LastOpened: time.Now()},
+		{Name: "newer", Path: filepath.Join(dir, "gone.json"), Type: "json", Added: time.Now()},
+		{Name: "older", Path: present + ".bak", Type: "yaml", Added: time.Now().Add(-time.Hour)},
+	})
+
+	if stats.broken != 2 {
+		t.Fatalf("broken = %d, want 2", stats.broken)
+	}
+	if stats.totalSize != int64(len("key: value\n")) {
+		t.Fatalf("totalSize = %d, want size of the existing file", stats.totalSize)
+	}
+	if len(stats.neverOpened) != 2 || stats.neverOpened[0].Name != "older" {
+		t.Fatalf("neverOpened = %+v, want oldest registration first", stats.neverOpened)
+	}
+	if stats.byType[0] != (statCount{label: "yaml", count: 2}) {
+		t.Fatalf("byType[0] = %+v, want yaml x2", stats.byType[0])
+	}
+}
+
+func TestActivitySurvivesSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "configs.json")
+	store := storage.New(path)
+	day := time.Date(2026, 3, 4, 12, 0, 0, 0, time.Local)
+	store.RecordActivity(day)
+	store.RecordActivity(day)
+	if err := store.Save(nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	reloaded := storage.New(path)
+	if _, err := reloaded.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := reloaded.Activity()["2026-03-04"]; got != 2 {
+		t.Fatalf("activity = %d, want 2", got)
+	}
+}
+
+func TestHeatmapLevelScalesToBusiestDay(t *testing.T) {
+	if heatmapLevel(0, 10) != 0 {
+		t.Fatal("idle day should use the empty shade")
+	}
+	if heatmapLevel(1, 10) != 1 {
+		t.Fatal("any activity should be visible")
+	}
+	if heatmapLevel(10, 10) != len(heatmapShades)-1 {
+		t.Fatal("busiest day should use the darkest shade")
+	}
+}
+
+func TestRecentlyDeletedCanBeRestoredFromStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zap-registry.json")
+	store := storage.New(path)
+	configs := []models.ConfigEntry{
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf", Alias: "ngx"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+	if err := store.Save(configs); err != nil {
+		t.Fatal(err)
+	}
+	m := model{width: 100, height: 40, storage: store, configs: configs, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.deleteConfigs(configs[:1])
+
+	reloaded := storage.New(path)
+	if _, err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	deleted := reloaded.Deleted()
+	if len(deleted) != 1 || deleted[0].Name != "nginx" || deleted[0].Deleted.IsZero() {
+		t.Fatalf("deleted = %+v, want nginx with its deletion time", deleted)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	m = updated.(model)
+	saved, err := storage.New(path).Load()
+	if err != nil || len(saved) != 2 || saved[1].Name != "nginx" || saved[1].Alias != "ngx" {
+		t.Fatalf("saved = %+v (%v), want nginx restored with its alias", saved, err)
+	}
+	if len(m.stats.deleted) != 0 || len(m.storage.Deleted()) != 0 {
+		t.Fatalf("restored entry is still listed as deleted: %+v", m.stats.deleted)
+	}
+}
+
+func TestOnlyTheMostRecentDeletionsAreKept(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	for i := 0; i < 12; i++ {
+		store.RecordDeleted([]models.ConfigEntry{{Name: fmt.Sprint(i)}}, time.Now())
+	}
+	deleted := store.Deleted()
+	if len(deleted) != 10 || deleted[0].Name != "11" || deleted[9].Name != "2" {
+		t.Fatalf("deleted = %d entries from %s to %s, want the last 10 newest first", len(deleted), deleted[0].Name, deleted[len(deleted)-1].Name)
+	}
+}
+
+func TestFailedDeleteKeepsTheEntryAndHistory(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := storage.New(filepath.Join(blocker, "zap-registry.json"))
+	configs := []models.ConfigEntry{
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+	m := model{width: 100, height: 40, storage: store, configs: configs, editRow: -1, editCol: -1, mode: ModeConfirmDelete, deleteIndex: 0}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(model)
+	if len(m.configs) != 2 || m.configs[0].Name != "nginx" || m.configs[1].Name != "zshrc" {
+		t.Fatalf("configs after a failed delete = %+v, want both entries untouched", m.configs)
+	}
+	if len(store.Deleted()) != 0 {
+		t.Fatalf("deleted = %+v, want nothing recorded", store.Deleted())
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/ui"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statusItem is one indicator in the list's status bar: a highlighted value
+// between plain text, any of which may be empty.
+type statusItem struct {
+	prefix string
+	value  string
+	suffix string
+	style  *lipgloss.Style // replaces the highlight style for value
+}
+
+// sortLabel names the current sort and its direction, such as "name ↑".
+func (m model) sortLabel() string {
+	switch m.sortMode {
+	case 1:
+		return "recent ↓"
+	case 2:
+		return "name ↑"
+	case 3:
+		return "path ↑"
+	case sortCustom:
+		return m.sortSpec.String()
+	case sortColumn:
+		return m.columnSortName()
+	default:
+		return "project ↑"
+	}
+}
+
+// countStatus reports how many entries the list shows out of those it could
+// show, e.g. "12/40 files" while a search or filter hides some. Archived and
+// hidden missing files don't count towards the total.
+func (m model) countStatus() statusItem {
+	shown, total := 0, 0
+	for _, d := range m.displayConfigs {
+		if !d.isHeader {
+			shown++
+		}
+	}
+	for _, config := range m.configs {
+		if m.visible(config) {
+			total++
+		}
+	}
+	if shown == total {
+		return statusItem{value: fmt.Sprint(shown), suffix: " files"}
+	}
+	return statusItem{value: fmt.Sprintf("%d/%d", shown, total), suffix: " files"}
+}
+
+// listStatusItems returns the status bar's indicators while browsing the
+// list, most important first so narrow terminals cut the least useful.
+func (m model) listStatusItems() []statusItem {
+	items := []statusItem{m.countStatus()}
+	if m.statusMsg != "" && time.Now().Before(m.statusExpiry) {
+		style := ui.StatusStyle(m.statusLevel, m.settings.Colorblind)
+		items = append(items, statusItem{value: ui.StatusMarker(m.statusLevel) + m.statusMsg, style: &style})
+	}
+
+	if m.searchQuery != "" {
+		search := fmt.Sprintf("🔍 '%s'", m.searchQuery)
+		if m.rankedSearch() {
+			search += " ranked"
+		}
+		items = append(items, statusItem{value: search})
+	}
+	items = append(items, statusItem{prefix: "sort ", value: m.sortLabel()})
+	if m.storage != nil {
+		if name := registryName(m.storage.GetFilePath()); name != defaultRegistryName {
+			items = append(items, statusItem{prefix: "registry ", value: name})
+		}
+	}
+	if m.workspace != "" {
+		items = append(items, statusItem{prefix: "ws ", value: m.workspace})
+	}
+
+	if len(m.tagFilter) > 0 {
+		items = append(items, statusItem{value: formatTags(m.tagFilter)})
+	}
+	if m.overdueOnly {
+		items = append(items, statusItem{suffix: "due only"})
+	}
+	if m.unopenedOnly {
+		items = append(items, statusItem{suffix: "never opened"})
+	}
+	if m.showArchived {
+		items = append(items, statusItem{suffix: "📦 shown"})
+	}
+	if m.hideMissing {
+		items = append(items, statusItem{suffix: "missing hidden"})
+	}
+	if len(m.marked) > 0 {
+		items = append(items, statusItem{value: fmt.Sprint(len(m.marked)), suffix: " marked"})
+	}
+	if len(m.changed) > 0 {
+		items = append(items, statusItem{value: fmt.Sprintf("✱ %d", len(m.changed)), suffix: " changed"})
+	}
+	if overdue := m.overdueCount(); overdue > 0 {
+		items = append(items, statusItem{value: fmt.Sprintf("⏰ %d", overdue), suffix: " due"})
+	}
+	if expired := len(m.expiredConfigs()); expired > 0 {
+		items = append(items, statusItem{value: fmt.Sprintf("⌛ %d", expired), suffix: " expired"})
+	}
+
+	if m.usageDirty {
+		items = append(items, statusItem{suffix: "● saving"})
+	}
+	if m.settings.Shared != "" {
+		shared := 0
+		for _, config := range m.configs {
+			if config.Shared {
+				shared++
+			}
+		}
+		items = append(items, statusItem{value: fmt.Sprintf("⇅ %d", shared), suffix: " shared"})
+	}
+	return items
+}
+
+// renderStatusItems joins indicators with separators, highlighting values.
+func renderStatusItems(items []statusItem, valueStyle, labelStyle lipgloss.Style) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		var part string
+		if item.prefix != "" {
+			part += labelStyle.Render(item.prefix)
+		}
+		if item.value != "" && item.style != nil {
+			part += item.style.Render(item.value)
+		} else if item.value != "" {
+			part += valueStyle.Render(item.value)
+		}
+		if item.suffix != "" {
+			part += labelStyle.Render(item.suffix)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, labelStyle.Render(" | "))
+}
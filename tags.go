@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tagState is the tag editor or the tag filter prompt, whose input lives in
+// textInput.
+type tagState struct {
+	targets []models.ConfigEntry // entries being tagged; empty when filtering
+}
+
+// formatTags shows tags the way the list and details pane do: "#a #b".
+func formatTags(tags []string) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = "#" + tag
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasAnyTag reports whether config has at least one of tags.
+func hasAnyTag(config models.ConfigEntry, tags []string) bool {
+	for _, tag := range tags {
+		if hasTag(config, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// allTags returns every tag in the registry, for completing tag input.
+func (m model) allTags() []string {
+	return uniqueValues(m.configs, func(c models.ConfigEntry) []string {
+		return c.Tags
+	})
+}
+
+// startTagEdit edits the selected entry's tags, or adds tags to every
+// marked entry.
+func (m *model) startTagEdit() tea.Cmd {
+	targets := m.markedConfigs()
+	value := ""
+	if len(targets) == 0 {
+		config := m.getConfigByDisplayIndex(m.cursor)
+		if config == nil {
+			return nil
+		}
+		targets = []models.ConfigEntry{*config}
+		value = strings.Join(config.Tags, ", ")
+	}
+	m.tags = tagState{targets: targets}
+	m.mode = ModeTagEdit
+	m.textInput.SetValue(value)
+	m.textInput.SetSuggestions(m.editSuggestions())
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
+	return nil
+}
+
+// startTagFilter asks which tags to narrow the list to.
+func (m *model) startTagFilter() tea.Cmd {
+	if len(m.allTags()) == 0 {
+		return showWarning("No files are tagged yet; press t to tag one")
+	}
+	m.tags = tagState{}
+	m.mode = ModeTagFilter
+	m.textInput.SetValue(strings.Join(m.tagFilter, ", "))
+	m.textInput.SetSuggestions(m.editSuggestions())
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
+	return nil
+}
+
+func (m model) updateTags(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeNormal
+		m.textInput.Blur()
+		m.textInput.SetValue("")
+		return m, nil
+	case "enter":
+		tags := models.ParseKeywords(m.textInput.Value())
+		filtering := m.mode == ModeTagFilter
+		m.mode = ModeNormal
+		m.textInput.Blur()
+		m.textInput.SetValue("")
+		if filtering {
+			return m, m.setTagFilter(tags)
+		}
+		return m, m.saveTags(tags)
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	// Completions follow the tag being typed after the last comma
+	m.textInput.SetSuggestions(m.editSuggestions())
+	return m, cmd
+}
+
+// saveTags stores the edited tags: they replace a single entry's tags and
+// are added to each of several marked entries.
+func (m *model) saveTags(tags []string) tea.Cmd {
+	targets := m.tags.targets
+	m.tags = tagState{}
+	configs := make([]models.ConfigEntry, len(m.configs))
+	copy(configs, m.configs)
+	for _, target := range targets {
+		for i := range configs {
+			if !configs[i].Equals(&target) {
+				continue
+			}
+			if len(targets) == 1 {
+				configs[i].Tags = tags
+			} else {
+				configs[i].Tags = models.ParseKeywords(strings.Join(append(append([]string(nil), configs[i].Tags...), tags...), ","))
+			}
+			configs[i].Shared = false
+		}
+	}
+	if err := m.storage.Save(configs); err != nil {
+		return showError(fmt.Sprintf("Failed to save: %v", err))
+	}
+	m.configs = configs
+	m.marked = nil
+	m.cacheValid = false
+	m.buildDisplayList()
+	m.refreshRightViewport()
+	if len(targets) == 1 {
+		if len(tags) == 0 {
+			return showSuccess("Tags cleared")
+		}
+		return showSuccess("Tagged " + formatTags(tags))
+	}
+	return showSuccess(fmt.Sprintf("Tagged %d files %s", len(targets), formatTags(tags)))
+}
+
+// setTagFilter narrows the list to entries with any of tags; no tags shows
+// everything again.
+func (m *model) setTagFilter(tags []string) tea.Cmd {
+	m.tagFilter = tags
+	m.buildDisplayList()
+	m.refreshRightViewport()
+	if len(tags) == 0 {
+		return showStatus("Showing all files")
+	}
+	return showStatus(fmt.Sprintf("Showing %d files tagged %s", m.getFilteredConfigsCount(), strings.Join(tags, " or ")))
+}
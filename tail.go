@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/suitechrome"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// tailInterval is how often a followed file is checked for new data.
+	tailInterval = 500 * time.Millisecond
+	// tailMaxLines caps the lines kept in memory while following.
+	tailMaxLines = 2000
+	// tailInitialBytes is how much of the end of the file is shown at first.
+	tailInitialBytes = 64 * 1024
+)
+
+// tailState follows a registered log file like tail -f.
+type tailState struct {
+	config  models.ConfigEntry
+	path    string
+	offset  int64  // bytes read so far
+	partial string // trailing text not yet ended by a newline
+	lines   []string
+	scroll  int
+	follow  bool // keep the view pinned to the newest line
+	err     error
+}
+
+// tailTickMsg triggers the next poll of the followed file. gen ties it to
+// one opening of the view so ticks from an earlier one stop.
+type tailTickMsg struct{ gen int }
+
+func tailTick(gen int) tea.Cmd {
+	return tea.Tick(tailInterval, func(time.Time) tea.Msg { return tailTickMsg{gen: gen} })
+}
+
+// startTail switches to the log view for config.
+func (m *model) startTail(config models.ConfigEntry) tea.Cmd {
+	m.tail = tailState{config: config, path: editor.ExpandPath(config.Path), follow: true}
+	m.tailGen++
+	if info, err := os.Stat(m.tail.path); err == nil && info.Size() > tailInitialBytes {
+		m.tail.offset = info.Size() - tailInitialBytes
+	}
+	skipFirst := m.tail.offset > 0
+	m.tail.read()
+	if skipFirst && len(m.tail.lines) > 0 {
+		m.tail.lines = m.tail.lines[1:] // starts mid-line
+	}
+	m.mode = ModeTail
+	m.tail.pin(m.tailBodyHeight())
+	return tailTick(m.tailGen)
+}
+
+// read appends anything written since the last read. A file that shrank is
+// assumed to have been truncated or rotated and is read from the start.
+func (t *tailState) read() {
+	f, err := os.Open(t.path)
+	if err != nil {
+		t.err = err
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		t.err = err
+		return
+	}
+	t.err = nil
+	if info.Size() < t.offset {
+		t.offset, t.partial, t.lines = 0, "", nil
+	}
+	if info.Size() == t.offset {
+		return
+	}
+
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		t.err = err
+		return
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.err = err
+		return
+	}
+	t.offset += int64(len(data))
+
+	text := t.partial + strings.ReplaceAll(string(data), "\r\n", "\n")
+	parts := strings.Split(text, "\n")
+	t.partial = parts[len(parts)-1]
+	t.lines = append(t.lines, parts[:len(parts)-1]...)
+	if over := len(t.lines) - tailMaxLines; over > 0 {
+		t.lines = t.lines[over:]
+		t.scroll -= over
+	}
+}
+
+// pin scrolls to the newest line when following.
+func (t *tailState) pin(height int) {
+	if t.follow {
+		t.scroll = len(t.lines) - height
+	}
+	if t.scroll > len(t.lines)-1 {
+		t.scroll = len(t.lines) - 1
+	}
+	if t.scroll < 0 {
+		t.scroll = 0
+	}
+}
+
+func (m model) tailPanelStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("117")).
+		Padding(0, 1)
+}
+
+// tailBodyHeight is the number of log lines visible in the panel.
+func (m model) tailBodyHeight() int {
+	height := m.mainContentHeight() - m.tailPanelStyle().GetVerticalFrameSize() - 2
+	if height < 1 {
+		height = 1
+	}
+	return height
+}
+
+func (m model) updateTail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	height := m.tailBodyHeight()
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = ModeNormal
+		m.tail = tailState{}
+		return m, nil
+	case "o":
+		config := m.tail.config
+		m.mode = ModeNormal
+		m.tail = tailState{}
+		return m, m.openConfigs([]models.ConfigEntry{config}, m.editorFor(config), false)
+	case "f":
+		m.tail.follow = !m.tail.follow
+	case "j", "down":
+		m.tail.scroll++
+		m.tail.follow = m.tail.scroll >= len(m.tail.lines)-height
+	case "k", "up":
+		m.tail.scroll--
+		m.tail.follow = false
+	case "pgdown", "ctrl+d":
+		// Scrolling down to the end resumes following
+		m.tail.scroll += height
+		m.tail.follow = m.tail.scroll >= len(m.tail.lines)-height
+	case "pgup", "ctrl+u":
+		m.tail.scroll -= height
+		m.tail.follow = false
+	case "g", "home":
+		m.tail.scroll = 0
+		m.tail.follow = false
+	case "G", "end":
+		m.tail.follow = true
+	}
+	m.tail.pin(height)
+	return m, nil
+}
+
+// renderTailPanel shows the followed file in place of the file list.
+func (m model) renderTailPanel() string {
+	panelStyle := m.tailPanelStyle()
+	height := m.mainContentHeight()
+	bodyHeight := m.tailBodyHeight()
+	innerWidth := m.width - panelStyle.GetHorizontalFrameSize()
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render("📜 " + m.tail.config.Name)
+	title = suitechrome.JoinLine(innerWidth, title, suitechrome.Dim(m.tail.config.Path))
+
+	var body []string
+	if m.tail.err != nil {
+		body = append(body, suitechrome.Dim("  unavailable: "+m.tail.err.Error()))
+	} else if len(m.tail.lines) == 0 {
+		body = append(body, suitechrome.Dim("  waiting for output…"))
+	}
+	end := m.tail.scroll + bodyHeight
+	if end > len(m.tail.lines) {
+		end = len(m.tail.lines)
+	}
+	for _, line := range m.tail.lines[m.tail.scroll:end] {
+		body = append(body, truncate(strings.ReplaceAll(line, "\t", "    "), innerWidth))
+	}
+
+	return panelStyle.
+		Width(m.width - panelStyle.GetHorizontalBorderSize()).
+		Height(height - panelStyle.GetVerticalFrameSize()).
+		Render(strings.Join(append([]string{title, ""}, body...), "\n"))
+}
+
+// tailStatus describes the follow state for the status bar.
+func (m model) tailStatus() string {
+	state := "paused"
+	if m.tail.follow {
+		state = "following"
+	}
+	return fmt.Sprintf("📜 %s (%s, %d lines)", m.tail.config.Name, state, len(m.tail.lines))
+}
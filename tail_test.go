@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/zap/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel returns a 100x24 model in normal mode holding configs, with
+// nothing selected for editing or deletion.
+func newTestModel(t *testing.T, configs ...models.ConfigEntry) model {
+	t.Helper()
+	return model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, configs: configs}
+}
+
+func TestTailFollowsAppendsAndTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\npart"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(t, models.ConfigEntry{Name: "app", Path: path, Type: "log"})
+	m.buildDisplayList()
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.mode != ModeTail || cmd == nil {
+		t.Fatalf("enter on a log entry should start following, mode = %v", m.mode)
+	}
+	if len(m.tail.lines) != 2 {
+		t.Fatalf("lines = %q, want the two complete lines", m.tail.lines)
+	}
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("ial\nthree\n")
+	f.Close()
+	updated, _ = m.Update(tailTickMsg{gen: m.tailGen})
+	m = updated.(model)
+	if got := m.tail.lines; len(got) != 4 || got[2] != "partial" {
+		t.Fatalf("lines after append = %q", got)
+	}
+
+	os.WriteFile(path, []byte("fresh\n"), 0644)
+	updated, _ = m.Update(tailTickMsg{gen: m.tailGen})
+	if got := updated.(model).tail.lines; len(got) != 1 || got[0] != "fresh" {
+		t.Fatalf("lines after truncation = %q, want a fresh read", got)
+	}
+}
+
+func TestTailFPausesFollowing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("line\n", 50)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(t, models.ConfigEntry{Name: "app", Path: path, Type: "log"})
+	m.buildDisplayList()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if !m.tail.follow {
+		t.Fatal("tail should start out following")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = updated.(model)
+	if m.tail.follow {
+		t.Fatal("f while following should pause")
+	}
+	scroll := m.tail.scroll
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(strings.Repeat("more\n", 10))
+	f.Close()
+	updated, _ = m.Update(tailTickMsg{gen: m.tailGen})
+	m = updated.(model)
+	if m.tail.follow || m.tail.scroll != scroll {
+		t.Fatalf("paused view moved: follow = %v, scroll %d → %d", m.tail.follow, scroll, m.tail.scroll)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	if m = updated.(model); !m.tail.follow || m.tail.scroll != len(m.tail.lines)-m.tailBodyHeight() {
+		t.Fatalf("G should resume following at the bottom, scroll = %d", m.tail.scroll)
+	}
+}
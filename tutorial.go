@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Steps of the first-run tour, in the order they are taught
+const (
+	tutorialAdd = iota
+	tutorialSearch
+	tutorialOpen
+	tutorialDone
+)
+
+// tutorialSteps are the tour's instructions, indexed by step
+var tutorialSteps = []struct {
+	key  string
+	text string
+}{
+	{"N", "add a file, fill in its path and press enter"},
+	{"/", "search, type part of its name and press enter"},
+	{"enter", "open the selected file in your editor"},
+}
+
+// advanceTutorial moves the tour past step once the user has done it. Steps
+// done out of order are ignored so the tour is followed as written.
+func (m *model) advanceTutorial(step int) tea.Cmd {
+	if !m.tutorial || m.tutorialStep != step {
+		return nil
+	}
+	m.tutorialStep++
+	if m.tutorialStep < tutorialDone {
+		return nil
+	}
+	m.finishTutorial()
+	return tea.Batch(showSuccess("Tour complete, press ? for every key"), flushUsageLater())
+}
+
+// finishTutorial ends the tour for good. The registry records it so the
+// tour isn't shown again; it is saved with the next usage flush.
+func (m *model) finishTutorial() {
+	m.tutorial = false
+	m.storage.SetOnboarded(true)
+	m.usageDirty = true
+}
+
+// renderTutorial shows the tour's steps, with finished ones checked off and
+// the key for the current one highlighted.
+func (m model) renderTutorial(width int) string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	doneStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	keyStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62")).Padding(0, 1)
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Width(width)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	lines := []string{titleStyle.Render("Welcome to zap"), ""}
+	for i, step := range tutorialSteps {
+		switch {
+		case i < m.tutorialStep:
+			lines = append(lines, doneStyle.Render("✓ "+step.key+"  "+step.text))
+		case i == m.tutorialStep:
+			lines = append(lines, "→ "+keyStyle.Render(step.key)+" "+textStyle.Render(step.text))
+		default:
+			lines = append(lines, doneStyle.Render("  "+step.key+"  "+step.text))
+		}
+	}
+	lines = append(lines, "", hintStyle.Render("esc skip the tour"))
+	return strings.Join(lines, "\n")
+}
@@ -2,12 +2,17 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/internal/watch"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -18,13 +23,66 @@ type reposViewMsg struct{}
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Handle editor finished messages globally
+	if msg, ok := msg.(watch.ChangedMsg); ok {
+		if m.changed == nil {
+			m.changed = make(map[string]bool)
+		}
+		m.changed[msg.Path] = true
+		delete(m.exists, msg.Path) // it may have been created or removed
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		return m, tea.Batch(m.watcher.Next(), m.checkChanged(msg.Path))
+	}
+
 	if statusStr, ok := editor.HandleEditorFinished(msg); ok {
-		return m, showStatus(statusStr)
+		if paths := editor.OpenedPaths(msg); paths != nil {
+			for _, path := range paths {
+				delete(m.exists, filepath.Clean(editor.ExpandPath(path))) // saving may have created it
+			}
+			m.recordOpens(paths)
+			if cmd := m.advanceTutorial(tutorialOpen); cmd != nil {
+				return m, cmd
+			}
+			return m, tea.Batch(showSuccess(statusStr), flushUsageLater())
+		}
+		return m, showError(statusStr)
+	}
+
+	if statusStr, ok := editor.HandlePagerFinished(msg); ok {
+		if statusStr != "" {
+			return m, showError(statusStr)
+		}
+		return m, nil
 	}
 
 	switch msg := msg.(type) {
+	case checkFinishedMsg:
+		return m, m.finishCheck(msg)
+
+	case secretFetchedMsg:
+		m.finishSecret(msg)
+		return m, nil
+
+	case runFinishedMsg:
+		if m.mode == ModeRunResult && msg.gen == m.runGen {
+			m.finishRun(msg)
+		}
+		return m, nil
+	case tailTickMsg:
+		if m.mode != ModeTail || msg.gen != m.tailGen {
+			return m, nil // view closed or reopened; stop this poll loop
+		}
+		m.tail.read()
+		m.tail.pin(m.tailBodyHeight())
+		return m, tailTick(m.tailGen)
+	case flushUsageMsg:
+		if err := m.flushUsage(); err != nil {
+			return m, showError(fmt.Sprintf("Failed to save usage: %v", err))
+		}
+		return m, nil
 	case statusMsg:
 		m.statusMsg = msg.message
+		m.statusLevel = msg.level
 		m.statusExpiry = time.Now().Add(3 * time.Second)
 		return m, nil
 
@@ -38,7 +96,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" {
+		if msg.String() == "ctrl+c" && m.mode != ModeConfirmQuit {
+			if m.hasUnsavedChanges() {
+				m.quitReturnMode = m.mode
+				m.mode = ModeConfirmQuit
+				return m, nil
+			}
 			return m, tea.Quit
 		}
 		switch m.mode {
@@ -52,6 +115,40 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateSearch(msg)
 		case ModeConfirmDelete:
 			return m.updateDeleteConfirm(msg)
+		case ModeConfirmQuit:
+			return m.updateQuitConfirm(msg)
+		case ModeOpenWith:
+			return m.updateOpenWith(msg)
+		case ModeImportDir:
+			return m.updateImportDir(msg)
+		case ModeImport:
+			return m.updateImport(msg)
+		case ModeStats:
+			return m.updateStats(msg)
+		case ModeProjectEdit:
+			return m.updateProjectEdit(msg)
+		case ModeTail:
+			return m.updateTail(msg)
+		case ModeRunResult:
+			return m.updateRunResult(msg)
+		case ModeActions:
+			return m.updateActions(msg)
+		case ModeJump:
+			return m.updateJump(msg)
+		case ModeQuickOpen:
+			return m.updateQuickOpen(msg)
+		case ModeSecret:
+			return m.updateSecret(msg)
+		case ModeColumns:
+			return m.updateColumns(msg)
+		case ModeConfirmBatchDelete:
+			return m.updateBatchDelete(msg)
+		case ModeConfirmOpen:
+			return m.updateOpenConfirm(msg)
+		case ModeTagEdit, ModeTagFilter:
+			return m.updateTags(msg)
+		case ModeRegistryPicker:
+			return m.updateRegistryPicker(msg)
 		default:
 			return m.updateNormal(msg)
 		}
@@ -101,17 +198,20 @@ func (m model) updateDeleteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "y", "Y":
 		if m.deleteIndex >= 0 && m.deleteIndex < len(m.configs) {
 			configName := m.configs[m.deleteIndex].Name
-			m.configs = append(m.configs[:m.deleteIndex], m.configs[m.deleteIndex+1:]...)
-			if err := m.storage.Save(m.configs); err != nil {
+			undo := m.storage.RecordDeleted(m.configs[m.deleteIndex:m.deleteIndex+1], time.Now())
+			kept := append(m.configs[:m.deleteIndex:m.deleteIndex], m.configs[m.deleteIndex+1:]...)
+			if err := m.storage.Save(kept); err != nil {
+				undo()
 				m.mode = ModeNormal
-				return m, showStatus(fmt.Sprintf("Failed to save: %v", err))
+				return m, showError(fmt.Sprintf("Failed to save: %v", err))
 			}
+			m.configs = kept
 			m.cacheValid = false
 			m.buildDisplayList()
 			m.refreshRightViewport()
 			m.mode = ModeNormal
 			m.deleteIndex = -1
-			return m, showStatus(fmt.Sprintf("Deleted %s", configName))
+			return m, showSuccess(fmt.Sprintf("Deleted %s", configName))
 		}
 		m.mode = ModeNormal
 		m.deleteIndex = -1
@@ -124,6 +224,41 @@ func (m model) updateDeleteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m model) updateQuitConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "ctrl+c":
+		return m, tea.Quit
+	case "n", "N", "esc":
+		m.mode = m.quitReturnMode
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m model) updateOpenWith(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeNormal
+		m.textInput.Blur()
+		m.textInput.SetValue("")
+		return m, nil
+	case "enter":
+		editorCmd := strings.TrimSpace(m.textInput.Value())
+		m.mode = ModeNormal
+		m.textInput.Blur()
+		m.textInput.SetValue("")
+		config := m.getConfigByDisplayIndex(m.cursor)
+		if config == nil || editorCmd == "" {
+			return m, nil
+		}
+		return m, m.openConfigs([]models.ConfigEntry{*config}, editorCmd, true)
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
 func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
@@ -138,6 +273,12 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.buildDisplayList()
 		m.refreshRightViewport()
 		return m, showStatus("Search cleared")
+	case "ctrl+f":
+		m.fuzzyMode = !m.fuzzyMode
+		m.cacheValid = false
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		return m, nil
 	case "enter":
 		m.mode = ModeNormal
 		m.searchQuery = m.searchInput.Value()
@@ -146,6 +287,7 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.buildDisplayList()
 		m.refreshRightViewport()
 		if m.searchQuery != "" {
+			m.advanceTutorial(tutorialSearch)
 			matchCount := m.getFilteredConfigsCount()
 			return m, showStatus(fmt.Sprintf("Found %d matches", matchCount))
 		}
@@ -163,37 +305,61 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if m.editCol == 3 { // Type is picked from a list
+		options := m.typeOptions()
+		switch msg.String() {
+		case "up", "ctrl+p":
+			if m.typeCursor > 0 {
+				m.typeCursor--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.typeCursor < len(options)-1 {
+				m.typeCursor++
+			}
+			return m, nil
+		case "enter", "tab", "shift+tab":
+			if m.typeCursor < len(options) {
+				m.textInput.SetValue(options[m.typeCursor])
+			}
+		}
+	}
+
 	switch msg.String() {
 	case "esc":
 		m.cancelEdit()
 		return m, nil
 	case "enter":
 		wasAdding := m.mode == ModeAdd
-		if err := m.saveEdit(); err != nil {
-			return m, showStatus(fmt.Sprintf("Failed to save: %v", err))
+		if err := m.commitEdit(); err != nil {
+			return m, showError(fmt.Sprintf("Failed to save: %v", err))
 		}
 		m.cancelEdit()
 		if wasAdding {
-			return m, showStatus("File added")
+			m.advanceTutorial(tutorialAdd)
+			return m, showSuccess("File added")
 		}
-		return m, showStatus("File updated")
+		return m, showSuccess("File updated")
 	case "tab":
-		if err := m.saveEdit(); err != nil {
-			return m, showStatus(fmt.Sprintf("Failed to save: %v", err))
+		if err := m.applyEditField(); err != nil {
+			return m, showError(fmt.Sprintf("Invalid value: %v", err))
 		}
-		m.editCol = (m.editCol + 1) % 4
+		m.editCol = (m.editCol + 1) % editFieldCount
 		m.loadEditField()
 		return m, nil
 	case "shift+tab":
-		if err := m.saveEdit(); err != nil {
-			return m, showStatus(fmt.Sprintf("Failed to save: %v", err))
+		if err := m.applyEditField(); err != nil {
+			return m, showError(fmt.Sprintf("Invalid value: %v", err))
 		}
-		m.editCol = (m.editCol - 1 + 4) % 4
+		m.editCol = (m.editCol - 1 + editFieldCount) % editFieldCount
 		m.loadEditField()
 		return m, nil
 	}
 
 	m.textInput, cmd = m.textInput.Update(msg)
+	if m.editCol == 3 {
+		m.typeCursor = 0
+	}
 	return m, cmd
 }
 
@@ -204,9 +370,9 @@ func (m model) updateFileEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, showStatus("Inline edit cancelled")
 	case "ctrl+s":
 		if err := m.saveFileEdit(); err != nil {
-			return m, showStatus(fmt.Sprintf("Save failed: %v", err))
+			return m, showError(fmt.Sprintf("Save failed: %v", err))
 		}
-		return m, showStatus("File saved")
+		return m, showSuccess("File saved")
 	case "ctrl+d":
 		// Delete current line, reposition cursor to the same line number.
 		value := m.fileEditArea.Value()
@@ -251,28 +417,186 @@ func (m model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "/":
 		m.mode = ModeSearch
-		m.fuzzyMode = false
 		m.searchInput.Focus()
 		m.searchInput.SetValue(m.searchQuery)
 		return m, nil
 
 	case "S":
-		m.sortMode = (m.sortMode + 1) % 4
+		if m.sortMode == sortColumn {
+			m.sortMode = 0
+		} else {
+			m.sortMode = (m.sortMode + 1) % m.sortModeCount()
+		}
 		m.cacheValid = false
 		m.buildDisplayList()
 		m.refreshRightViewport()
+		if m.sortMode == sortCustom {
+			return m, showStatus(fmt.Sprintf("Sorted by %s", m.sortSpec))
+		}
 		sortNames := []string{"Project", "Recent", "Name", "Path"}
 		return m, showStatus(fmt.Sprintf("Sorted by %s", sortNames[m.sortMode]))
 
+	case "<", ">":
+		// The first press switches to the column the current mode already
+		// sorts by; later presses step to the neighbouring column
+		if m.sortMode != sortColumn {
+			switch m.sortMode {
+			case 0:
+				m.sortCol = 1
+			case 1:
+				m.sortCol = 4
+			case 3:
+				m.sortCol = 3
+			default:
+				m.sortCol = 0
+			}
+			m.sortMode = sortColumn
+		} else if msg.String() == ">" {
+			m.sortCol = (m.sortCol + 1) % len(sortColumns)
+		} else {
+			m.sortCol = (m.sortCol + len(sortColumns) - 1) % len(sortColumns)
+		}
+		m.sortReverse = false
+		m.cacheValid = false
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		return m, showStatus("Sorted by " + m.columnSortName())
+
+	case "~":
+		if m.sortMode != sortColumn {
+			return m, showWarning("Pick a column sort with < or > first")
+		}
+		m.sortReverse = !m.sortReverse
+		m.cacheValid = false
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		return m, showStatus("Sorted by " + m.columnSortName())
+
+	case "z":
+		m.expandRow = !m.expandRow
+		if m.expandRow {
+			return m, showStatus("Selected row expanded")
+		}
+		return m, showStatus("Selected row collapsed")
+
+	case "R":
+		m.overdueOnly = !m.overdueOnly
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		if m.overdueOnly {
+			return m, showStatus(fmt.Sprintf("Showing %d files due for review", m.overdueCount()))
+		}
+		return m, showStatus("Showing all files")
+
+	case "U":
+		m.unopenedOnly = !m.unopenedOnly
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		if m.unopenedOnly {
+			return m, showStatus(fmt.Sprintf("Showing %d never-opened files", m.getFilteredConfigsCount()))
+		}
+		return m, showStatus("Showing all files")
+
+	case "b":
+		return m, m.startRegistryPicker()
+
+	case "t":
+		return m, m.startTagEdit()
+
+	case "T":
+		return m, m.startTagFilter()
+
 	case "e":
 		return m, m.startEdit()
 
+	case "P":
+		return m, m.startProjectEdit()
+
+	case "x":
+		return m, m.startRun()
+
+	case "c":
+		return m, m.toggleAutoCheck()
+
+	case "d":
+		return m, m.startSourceDiff()
+
+	case "X":
+		return m, m.startActions()
+
+	case "ctrl+o":
+		m.startQuickOpen()
+		return m, nil
+
+	case "@":
+		if config := m.getConfigByDisplayIndex(m.cursor); config != nil {
+			return m, m.startJump(*config)
+		}
+		return m, nil
+
+	case "a":
+		return m, m.toggleArchived()
+
+	case "A":
+		m.showArchived = !m.showArchived
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		if m.showArchived {
+			return m, showStatus("Showing archived files")
+		}
+		return m, showStatus("Hiding archived files")
+
+	case "M":
+		m.hideMissing = !m.hideMissing
+		m.buildDisplayList()
+		m.refreshRightViewport()
+		if m.hideMissing {
+			return m, showStatus("Hiding files that no longer exist")
+		}
+		return m, showStatus("Showing files that no longer exist")
+
+	case "tab", "shift+tab":
+		if len(m.settings.Workspaces) == 0 {
+			return m, showWarning("No workspaces defined in " + settings.FileName)
+		}
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = -1
+		}
+		m.cycleWorkspace(step)
+		if m.workspace == "" {
+			return m, showStatus("Workspace: all files")
+		}
+		return m, showStatus("Workspace: " + m.workspace)
+
 	case "E":
 		return m, m.startFileEdit()
 
 	case "N":
 		return m, m.addNewConfig()
 
+	case "ctrl+v":
+		return m, m.addFromClipboard()
+
+	case "p":
+		m.hidePreview = !m.hidePreview
+		m.refreshRightViewport()
+		if m.hidePreview {
+			return m, showStatus("Preview hidden")
+		}
+		return m, showStatus("Preview shown")
+
+	case "I":
+		return m, m.startImportPrompt()
+
+	case "i":
+		m.stats = computeStats(m.configs)
+		m.stats.activity = m.storage.Activity()
+		m.stats.deleted = m.storage.Deleted()
+		m.statsScroll = 0
+		m.mode = ModeStats
+		return m, nil
+
 	case "D":
 		if len(m.configs) > 0 {
 			displayIndex := m.cursor
@@ -280,38 +604,126 @@ func (m model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if originalIndex == -1 {
 				return m, nil
 			}
+			if m.configs[originalIndex].Shared {
+				return m, showWarning("Shared entries can't be deleted here; archive it with a to hide it")
+			}
 			m.mode = ModeConfirmDelete
 			m.deleteIndex = originalIndex
+			if !m.settings.ConfirmDelete() {
+				return m.updateDeleteConfirm(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+			}
 			return m, showStatus(fmt.Sprintf("Delete '%s'? (y/n)", m.configs[originalIndex].Name))
 		}
 		return m, nil
 
+	case "ctrl+x":
+		return m, m.startBatchDelete()
+
+	case "!":
+		return m, m.toggleConfirmOpen()
+
+	case "l":
+		return m, m.toggleFlag(func(c *models.ConfigEntry) *bool { return &c.ReadOnly },
+			"%d file(s) locked read-only", "%d file(s) unlocked")
+
 	case "enter", "o":
+		if marked := m.markedConfigs(); len(marked) > 0 {
+			m.marked = nil
+			return m, m.openConfigs(marked, m.editor, false)
+		}
 		if len(m.configs) > 0 {
 			displayIndex := m.cursor
 			config := m.getConfigByDisplayIndex(displayIndex)
-			if config != nil {
-				for i := range m.configs {
-					if m.configs[i].Equals(config) {
-						m.configs[i].LastOpened = time.Now()
-						m.storage.Save(m.configs)
-						m.cacheValid = false
-						m.buildDisplayList()
-						break
-					}
+			if config != nil && config.Type == "log" {
+				return m, m.startTail(*config)
+			}
+			if config != nil && isKind(*config, "ssh") {
+				// Without Host blocks to pick from the file just opens
+				if targets, err := jumpTargetsFor(*config); err == nil && len(targets) > 0 {
+					return m, m.startJump(*config)
 				}
-				m.refreshRightViewport()
-				return m, editor.OpenConfig(*config, m.editor)
 			}
+			if config != nil {
+				return m, m.openConfigs([]models.ConfigEntry{*config}, m.editorFor(*config), false)
+			}
+		}
+		return m, nil
+
+	case "1", "2", "3":
+		recent := m.recentConfigs(recentStripSize)
+		slot := int(msg.String()[0] - '1')
+		if slot >= len(recent) {
+			return m, nil
+		}
+		return m, m.openConfigs([]models.ConfigEntry{recent[slot]}, m.editorFor(recent[slot]), false)
+
+	case " ":
+		config := m.getConfigByDisplayIndex(m.cursor)
+		if config == nil {
+			return m, nil
+		}
+		if m.marked == nil {
+			m.marked = make(map[string]bool)
+		}
+		if m.marked[config.Path] {
+			delete(m.marked, config.Path)
+		} else {
+			m.marked[config.Path] = true
+		}
+		m.moveCursorDown()
+		m.refreshRightViewport()
+		return m, nil
+
+	case "esc":
+		if len(m.marked) > 0 {
+			m.marked = nil
+			return m, showStatus("Selection cleared")
+		}
+		if m.tutorial {
+			m.finishTutorial()
+			return m, tea.Batch(showStatus("Tour skipped, press ? for every key"), flushUsageLater())
 		}
 		return m, nil
 
+	case "v":
+		config := m.getConfigByDisplayIndex(m.cursor)
+		if config == nil {
+			return m, nil
+		}
+		return m, editor.Page(config.Path, config.Name, editor.Options{DryRun: m.dryRun})
+
+	case "V":
+		return m, m.openSecret()
+
+	case "L":
+		return m, m.startColumns()
+
+	case "left":
+		m.scrollColumns(-1)
+		return m, nil
+
+	case "right":
+		m.scrollColumns(1)
+		return m, nil
+
+	case "W":
+		config := m.getConfigByDisplayIndex(m.cursor)
+		if config == nil {
+			return m, nil
+		}
+		m.mode = ModeOpenWith
+		m.textInput.SetSuggestions(m.knownEditors())
+		m.textInput.SetValue(m.editorFor(*config))
+		m.textInput.CursorEnd()
+		m.textInput.Focus()
+		return m, nil
+
 	case "O":
 		if len(m.configs) > 0 {
 			config := m.getConfigByDisplayIndex(m.cursor)
 			if config != nil {
 				dir := filepath.Dir(editor.ExpandPath(config.Path))
-				return m, editor.OpenPath(dir, m.editor, filepath.Base(dir))
+				return m, editor.OpenPathWith(dir, m.editor, filepath.Base(dir), editor.Options{DryRun: m.dryRun, Kind: editorKind(m.settings, m.editor)})
 			}
 		}
 		return m, nil
@@ -322,28 +734,84 @@ func (m model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if config != nil {
 				path := editor.ExpandPath(config.Path)
 				if err := copyToClipboard(path); err != nil {
-					return m, showStatus(fmt.Sprintf("Clipboard error: %v", err))
+					return m, showError(fmt.Sprintf("Clipboard error: %v", err))
 				}
-				return m, showStatus(fmt.Sprintf("Copied: %s", path))
+				return m, showSuccess(fmt.Sprintf("Copied: %s", path))
 			}
 		}
 		return m, nil
 
+	case "Y":
+		config := m.getConfigByDisplayIndex(m.cursor)
+		if config == nil {
+			return m, nil
+		}
+		path := editor.ExpandPath(config.Path)
+		if err := m.checkFileSize(path); err != nil {
+			return m, showWarning(fmt.Sprintf("Not copied: %v", err))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return m, showError(fmt.Sprintf("Failed to read file: %v", err))
+		}
+		if editor.IsBinary(data) {
+			return m, showWarning("Binary files can't be copied as text")
+		}
+		if err := copyToClipboard(string(data)); err != nil {
+			return m, showError(fmt.Sprintf("Clipboard error: %v", err))
+		}
+		return m, showSuccess(fmt.Sprintf("Copied contents of %s (%s)", config.Name, formatSize(int64(len(data)))))
+
 	case ",":
 		configPath := m.storage.GetFilePath()
-		return m, editor.OpenPath(configPath, m.editor, "zap config")
+		return m, editor.OpenPathWith(configPath, m.editor, "zap config", editor.Options{DryRun: m.dryRun, Kind: editorKind(m.settings, m.editor)})
 
 	case "r":
 		configs, err := m.storage.Load()
 		if err != nil {
-			return m, showStatus(fmt.Sprintf("Failed to reload: %v", err))
+			return m, showError(fmt.Sprintf("Failed to reload: %v", err))
+		}
+		prefs, err := settings.Load(settings.PathFor(m.storage.GetFilePath()))
+		if err != nil {
+			return m, showError(fmt.Sprintf("Failed to reload settings: %v", err))
+		}
+		if configs, err = withShared(configs, prefs); err != nil {
+			return m, showError(fmt.Sprintf("Failed to reload: %v", err))
+		}
+		collation, err := storage.NewCollation(prefs.Collation)
+		if err != nil {
+			return m, showError(err.Error())
+		}
+		var spec storage.SortSpec
+		if prefs.Sort != "" {
+			if spec, err = storage.ParseSortSpec(prefs.Sort); err != nil {
+				return m, showError(fmt.Sprintf("Invalid sort setting: %v", err))
+			}
 		}
 		m.configs = configs
-		m.editor = m.storage.GetEditor()
+		m.projects = m.storage.Projects()
+		m.settings = prefs
+		m.collation = collation
+		m.sortSpec = spec
+		if m.sortMode == sortCustom && spec == nil {
+			m.sortMode = 0
+		}
+		if _, ok := prefs.Workspaces[m.workspace]; !ok {
+			m.workspace = ""
+		}
+		m.editor = defaultEditor(m.storage, prefs)
+		m.exists = nil // check every file again
+		var watchCmd tea.Cmd
+		if (prefs.Watch || m.hasAutoCheck()) && m.watcher == nil {
+			if watcher, err := watch.New(m.watchPaths()); err == nil {
+				m.watcher = watcher
+				watchCmd = watcher.Next()
+			}
+		}
 		m.cacheValid = false
 		m.buildDisplayList()
 		m.refreshRightViewport()
-		return m, showStatus("Refreshed")
+		return m, tea.Batch(showStatus("Refreshed"), watchCmd)
 
 	case "k", "up":
 		m.moveCursorUp()
@@ -429,3 +897,29 @@ func copyToClipboard(text string) error {
 
 	return fmt.Errorf("no clipboard tool found (tried wl-copy, xclip, xsel, clip.exe)")
 }
+
+func readClipboard() (string, error) {
+	// Try clipboard commands in order of preference
+	cmds := []struct {
+		name string
+		args []string
+	}{
+		{"wl-paste", []string{"--no-newline"}},
+		{"xclip", []string{"-selection", "clipboard", "-o"}},
+		{"xsel", []string{"--clipboard", "--output"}},
+		{"pbpaste", nil},
+		{"powershell.exe", []string{"-NoProfile", "-Command", "Get-Clipboard"}},
+	}
+
+	for _, c := range cmds {
+		path, err := exec.LookPath(c.name)
+		if err != nil {
+			continue
+		}
+		if out, err := exec.Command(path, c.args...).Output(); err == nil {
+			return string(out), nil
+		}
+	}
+
+	return "", fmt.Errorf("no clipboard tool found (tried wl-paste, xclip, xsel, pbpaste, powershell.exe)")
+}
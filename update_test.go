@@ -0,0 +1,1258 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/settings"
+	"github.com/LFroesch/zap/internal/storage"
+	"github.com/LFroesch/zap/internal/ui"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestCtrlCAsksBeforeDroppingUnfinishedAdd(t *testing.T) {
+	m := model{width: 100, height: 24, mode: ModeAdd}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd != nil {
+		t.Fatal("expected ctrl+c to wait for confirmation instead of quitting")
+	}
+	if got := updated.(model).mode; got != ModeConfirmQuit {
+		t.Fatalf("mode = %v, want ModeConfirmQuit", got)
+	}
+
+	updated, _ = updated.(model).Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := updated.(model).mode; got != ModeAdd {
+		t.Fatalf("mode after esc = %v, want ModeAdd", got)
+	}
+}
+
+func TestCtrlCQuitsImmediatelyWhenClean(t *testing.T) {
+	m := model{width: 100, height: 24, mode: ModeNormal}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd == nil {
+		t.Fatal("expected ctrl+c to quit without unsaved changes")
+	}
+}
+
+func TestEscDuringAddRollsBackPartialSaves(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	m := model{width: 100, height: 24, storage: store, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.addNewConfig()
+
+	m.textInput.SetValue("renamed")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	updated, _ = updated.(model).Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if got := len(updated.(model).configs); got != 0 {
+		t.Fatalf("configs after cancelled add = %d, want 0", got)
+	}
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	if len(saved) != 0 {
+		t.Fatalf("registry on disk = %+v, want partial add rolled back", saved)
+	}
+}
+
+func TestEditIsSavedAsOneTransaction(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	original := []models.ConfigEntry{{Name: "nginx", Path: "/etc/nginx/nginx.conf", Type: "ini"}}
+	if err := store.Save(original); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	m := model{width: 100, height: 24, storage: store, configs: original, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.buildDisplayList()
+	m.startEdit()
+
+	m.textInput.SetValue("nginx main")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	saved, _ := store.Load()
+	if saved[0].Name != "nginx" {
+		t.Fatalf("tab wrote %q to disk before enter", saved[0].Name)
+	}
+
+	m = updated.(model)
+	m.textInput.SetValue("infra")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	saved, _ = store.Load()
+	if saved[0].Name != "nginx main" || saved[0].Project != "infra" {
+		t.Fatalf("saved entry = %+v, want both fields committed", saved[0])
+	}
+	if updated.(model).mode != ModeNormal {
+		t.Fatalf("mode after enter = %v, want ModeNormal", updated.(model).mode)
+	}
+}
+
+func TestProjectFieldCompletesFromRegistry(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	configs := []models.ConfigEntry{
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf", Project: "infrastructure"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+	if err := store.Save(configs); err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(store, configs, settings.Settings{})
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(configs[1])
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("e")},
+		{Type: tea.KeyTab},
+		{Type: tea.KeyRunes, Runes: []rune("i")},
+		{Type: tea.KeyRunes, Runes: []rune("n")},
+	} {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	if got := m.visibleSuggestions(); !reflect.DeepEqual(got, []string{"infrastructure"}) {
+		t.Fatalf("suggestions for %q = %v, want the registry's project", m.textInput.Value(), got)
+	}
+
+	for _, msg := range []tea.KeyMsg{{Type: tea.KeyRight}, {Type: tea.KeyEnter}} {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved[1].Name != "zshrc" || saved[1].Project != "infrastructure" {
+		t.Fatalf("saved entry = %+v, want the completed project", saved[1])
+	}
+}
+
+func TestTypeFieldCompletesFromRegistry(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	configs := []models.ConfigEntry{
+		{Name: "job", Path: "/srv/app/job.hcl", Type: "nomad"},
+		{Name: "app", Path: "/srv/app/settings", Type: "txt"},
+	}
+	if err := store.Save(configs); err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(store, configs, settings.Settings{})
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(configs[1])
+	press := func(msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			updated, _ := m.Update(msg)
+			m = updated.(model)
+		}
+	}
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, tab, tab, tab)
+	m.textInput.SetValue("")
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("no")})
+	if got := m.textInput.MatchedSuggestions(); !reflect.DeepEqual(got, []string{"nomad"}) {
+		t.Fatalf("suggestions for %q = %v, want the registry's type", m.textInput.Value(), got)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyEnter})
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved[1].Name != "app" || saved[1].Type != "nomad" {
+		t.Fatalf("saved entry = %+v, want the completed type", saved[1])
+	}
+}
+
+func TestTagsCompleteFromRegistry(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	configs := []models.ConfigEntry{
+		{Name: "deploy", Path: "/srv/k8s/deploy.yaml", Tags: []string{"kubernetes", "work"}},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+	if err := store.Save(configs); err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(store, configs, settings.Settings{})
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(configs[1])
+	press := func(msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			updated, _ := m.Update(msg)
+			m = updated.(model)
+		}
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("wo")})
+	if got := m.visibleSuggestions(); !reflect.DeepEqual(got, []string{"work"}) {
+		t.Fatalf("suggestions for %q = %v, want the registry's tag", m.textInput.Value(), got)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(", ")})
+	if got := m.visibleSuggestions(); !reflect.DeepEqual(got, []string{"work, kubernetes"}) {
+		t.Fatalf("suggestions for %q = %v, want the tags not listed yet", m.textInput.Value(), got)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")}, tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyEnter})
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved[1].Name != "zshrc" || !reflect.DeepEqual(saved[1].Tags, []string{"work", "kubernetes"}) {
+		t.Fatalf("saved entry = %+v, want the completed tags", saved[1])
+	}
+}
+
+func TestTypeIsPickedFromFilteredList(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	configs := []models.ConfigEntry{
+		{Name: "app", Path: "/srv/app/settings", Type: "txt"},
+		{Name: "job", Path: "/srv/app/job.hcl", Type: "nomad"},
+	}
+	if err := store.Save(configs); err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(store, configs, settings.Settings{})
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(configs[0])
+	press := func(msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			updated, _ := m.Update(msg)
+			m = updated.(model)
+		}
+	}
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, tab, tab, tab)
+	if m.editCol != 3 {
+		t.Fatalf("edit column = %d, want the Type field", m.editCol)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ymal")}, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeEdit {
+		t.Fatal("a type matching nothing in the list should not be saved")
+	}
+
+	m.textInput.SetValue("")
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("om")})
+	options := m.typeOptions()
+	nomad := -1
+	for i, option := range options {
+		if !strings.Contains(option, "om") {
+			t.Fatalf("options for %q = %v, want only matches", "om", options)
+		}
+		if option == "nomad" {
+			nomad = i
+		}
+	}
+	if nomad < 1 {
+		t.Fatalf("options for %q = %v, want the registry's nomad after a known type", "om", options)
+	}
+	for i := 0; i < nomad; i++ {
+		press(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved[0].Type != "nomad" {
+		t.Fatalf("saved type = %q, want the highlighted nomad", saved[0].Type)
+	}
+}
+
+func TestOverdueFilterShowsOnlyDueReviews(t *testing.T) {
+	now := time.Now()
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "creds", Path: "/tmp/creds", ReviewEvery: "90d", LastOpened: now.AddDate(0, -4, 0)},
+		{Name: "fresh", Path: "/tmp/fresh", ReviewEvery: "90d", LastOpened: now},
+		{Name: "plain", Path: "/tmp/plain"},
+		{Name: "undated", Path: "/tmp/undated", ReviewEvery: "90d"},
+	}
+	m.buildDisplayList()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	m = updated.(model)
+	var names []string
+	for _, d := range m.displayConfigs {
+		if !d.isHeader {
+			names = append(names, d.config.Name)
+		}
+	}
+	if len(names) != 1 || names[0] != "creds" {
+		t.Fatalf("overdue filter shows %v, want [creds]", names)
+	}
+}
+
+func TestParseIntervalUnits(t *testing.T) {
+	for input, want := range map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"6m":  180 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	} {
+		got, err := models.ParseInterval(input)
+		if err != nil || got != want {
+			t.Fatalf("ParseInterval(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := models.ParseInterval("soon"); err == nil {
+		t.Fatal("expected an error for an unparseable interval")
+	}
+}
+
+func TestFuzzySearchRanksFrequentlyOpenedFirst(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.searchInput = textinput.New()
+	m.configs = []models.ConfigEntry{
+		{Name: "angular.json", Path: "/web/angular.json", Project: "web"},
+		{Name: "nginx.conf", Path: "/etc/nginx/nginx.conf", Project: "infra", OpenCount: 40, LastOpened: time.Now()},
+	}
+	m.buildDisplayList()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	updated, _ = updated.(model).Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	updated, _ = updated.(model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ng")})
+	m = updated.(model)
+
+	if len(m.displayConfigs) != 2 || m.displayConfigs[0].isHeader {
+		t.Fatalf("ranked results should be a flat list, got %+v", m.displayConfigs)
+	}
+	if got := m.displayConfigs[0].config.Name; got != "nginx.conf" {
+		t.Fatalf("top result = %q, want nginx.conf", got)
+	}
+}
+
+func TestTabCyclesWorkspaces(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.settings.Workspaces = map[string]settings.Workspace{
+		"personal": {Projects: []string{"dots"}},
+		"work":     {Projects: []string{"infra"}, Tags: []string{"work"}},
+	}
+	m.configs = []models.ConfigEntry{
+		{Name: "nginx", Path: "/etc/nginx.conf", Project: "infra"},
+		{Name: "zshrc", Path: "/home/u/.zshrc", Project: "dots"},
+		{Name: "vpn", Path: "/etc/vpn.conf", Tags: []string{"Work"}},
+	}
+	m.buildDisplayList()
+
+	visible := func(m model) int {
+		count := 0
+		for _, d := range m.displayConfigs {
+			if !d.isHeader {
+				count++
+			}
+		}
+		return count
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m = updated.(model); m.workspace != "personal" || visible(m) != 1 {
+		t.Fatalf("workspace %q shows %d files, want personal with 1", m.workspace, visible(m))
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m = updated.(model); m.workspace != "work" || visible(m) != 2 {
+		t.Fatalf("workspace %q shows %d files, want work with 2", m.workspace, visible(m))
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m = updated.(model); m.workspace != "" || visible(m) != 3 {
+		t.Fatalf("workspace %q shows %d files, want all 3", m.workspace, visible(m))
+	}
+}
+
+func TestArchivedFilesAreHiddenUntilToggled(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	m := model{width: 100, height: 24, storage: store, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "old", Path: "/tmp/old.conf"},
+		{Name: "new", Path: "/tmp/new.conf"},
+	}
+	m.buildDisplayList()
+	m.cursor = 1 // first file row, below the General header
+
+	archived := m.getConfigByDisplayIndex(m.cursor).Name
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = updated.(model)
+	for _, d := range m.displayConfigs {
+		if !d.isHeader && d.config.Name == archived {
+			t.Fatalf("%s still listed after archiving", archived)
+		}
+	}
+	saved, _ := store.Load()
+	if !saved[0].Archived && !saved[1].Archived {
+		t.Fatal("archive flag was not saved")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	if got := len(updated.(model).displayConfigs); got != 3 {
+		t.Fatalf("display rows with archived shown = %d, want header + 2 files", got)
+	}
+}
+
+func TestAliasMustBeUnique(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf", Alias: "ng"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+
+	m.editRow = 1
+	if err := m.editFieldError(7, "NG"); err == nil {
+		t.Fatal("duplicate alias accepted")
+	}
+	if err := m.editFieldError(7, "z s"); err == nil {
+		t.Fatal("alias with a space accepted")
+	}
+	m.editRow = 0
+	if err := m.editFieldError(7, "ng"); err != nil {
+		t.Fatalf("entry's own alias rejected: %v", err)
+	}
+}
+
+func TestQuickOpenLeavesSearchUntouched(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf"},
+		{Name: "zshrc", Path: "/home/me/.zshrc", Alias: "z"},
+		{Name: "old zsh", Path: "/home/me/.zshrc.bak", Archived: true},
+	}
+	m.textInput = textinput.New()
+	m.searchQuery = "nginx"
+	m.buildDisplayList()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = updated.(model)
+	for _, r := range "zsh" {
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(model)
+	}
+	if m.mode != ModeQuickOpen {
+		t.Fatalf("mode = %v, want quick open", m.mode)
+	}
+	matches := quickOpenMatches(m.configs, m.textInput.Value(), time.Now(), m.searchOptions())
+	if len(matches) != 1 || matches[0].Name != "zshrc" {
+		t.Fatalf("matches = %+v, want only zshrc", matches)
+	}
+	if m.searchQuery != "nginx" {
+		t.Fatalf("searchQuery = %q, want it untouched", m.searchQuery)
+	}
+	if got := quickOpenMatches(m.configs, "Z", time.Now(), m.searchOptions()); got[0].Name != "zshrc" {
+		t.Fatalf("alias match = %+v, want zshrc first", got)
+	}
+}
+
+func TestSearchFoldsUnicodeCaseAndOptionallyAccents(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "Straße.conf", Path: "/etc/strasse.conf"},
+		{Name: "ÉCOLE settings", Path: "/srv/ecole.yaml"},
+	}
+
+	m.searchQuery = "STRASSE.CONF"
+	m.buildDisplayList()
+	if got := m.getFilteredConfigs(); len(got) != 1 || got[0].Name != "Straße.conf" {
+		t.Fatalf("case-folded search = %+v", got)
+	}
+
+	m.searchQuery = "école"
+	m.cacheValid = false
+	if got := m.getFilteredConfigs(); len(got) != 1 {
+		t.Fatalf("search for école = %+v, want ÉCOLE settings", got)
+	}
+
+	m.searchQuery = `"ecole set"`
+	if got := m.getFilteredConfigs(); len(got) != 0 {
+		t.Fatalf("accent-sensitive search matched %+v", got)
+	}
+	m.settings.IgnoreAccents = true
+	if got := m.getFilteredConfigs(); len(got) != 1 {
+		t.Fatalf("accent-insensitive search = %+v, want ÉCOLE settings", got)
+	}
+}
+
+func TestMatchBasenameRanksFileNameFirst(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "caddyfile notes", Path: "/home/me/notes/caddy.md", OpenCount: 40},
+		{Name: "web proxy", Path: "/etc/caddy/Caddyfile"},
+	}
+	m.searchQuery = "caddyfile"
+
+	m.buildDisplayList()
+	if got := m.getFilteredConfigs(); len(got) != 2 || got[0].Name != "caddyfile notes" {
+		t.Fatalf("default order = %+v, want list order kept", got)
+	}
+
+	m.settings.MatchBasename = true
+	for _, fuzzy := range []bool{false, true} {
+		m.fuzzyMode = fuzzy
+		m.cacheValid = false
+		if got := m.getFilteredConfigs(); got[0].Name != "web proxy" {
+			t.Fatalf("fuzzy=%v: first match = %q, want the file named Caddyfile", fuzzy, got[0].Name)
+		}
+	}
+}
+
+func TestSortSpecOrdersByEachKey(t *testing.T) {
+	now := time.Now()
+	configs := []models.ConfigEntry{
+		{Name: "b", Project: "web", Type: "yaml", LastOpened: now.Add(-time.Hour)},
+		{Name: "a", Project: "web", Type: "nginx"},
+		{Name: "c", Project: "web", Type: "yaml", LastOpened: now},
+		{Name: "d", Project: "api", Type: "yaml"},
+	}
+	spec, err := storage.ParseSortSpec("project asc, type, last_opened DESC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, config := range storage.SortBySpec(configs, spec, storage.Collation{}) {
+		got = append(got, config.Name)
+	}
+	if strings.Join(got, ",") != "d,a,c,b" {
+		t.Fatalf("SortBySpec = %v, want d,a,c,b", got)
+	}
+
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, configs: configs, sortSpec: spec, sortMode: sortCustom}
+	m.buildDisplayList()
+	if !m.displayConfigs[0].isHeader {
+		t.Fatal("expected project headers when the expression sorts by project first")
+	}
+	if m.sortModeCount() != 5 {
+		t.Fatalf("sortModeCount = %d, want the custom mode included", m.sortModeCount())
+	}
+
+	for _, bad := range []string{"colour asc", "name sideways", " , "} {
+		if _, err := storage.ParseSortSpec(bad); err == nil {
+			t.Fatalf("ParseSortSpec(%q) accepted", bad)
+		}
+	}
+}
+
+func TestColumnSortStepsAndReverses(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, configs: []models.ConfigEntry{
+		{Name: "b", Path: "/b", Type: "yaml"},
+		{Name: "a", Path: "/a", Type: "toml"},
+	}}
+	m.buildDisplayList()
+
+	updated, _ := m.updateNormal(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+	m = updated.(model)
+	if m.sortMode != sortColumn || sortColumns[m.sortCol].label != "project" {
+		t.Fatalf("first > = mode %d column %d, want the project column", m.sortMode, m.sortCol)
+	}
+	updated, _ = m.updateNormal(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+	m = updated.(model)
+	if got := m.displayConfigs[0].config.Name; got != "a" {
+		t.Fatalf("sorted by %s: first = %s, want a (toml)", m.columnSortName(), got)
+	}
+	updated, _ = m.updateNormal(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("~")})
+	m = updated.(model)
+	if got := m.displayConfigs[0].config.Name; got != "b" || m.columnSortName() != "type ↓" {
+		t.Fatalf("reversed %s: first = %s, want b", m.columnSortName(), got)
+	}
+	updated, _ = m.updateNormal(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if updated.(model).sortMode != 0 {
+		t.Fatal("S from a column sort should return to the project sort")
+	}
+}
+
+func TestRecordedOpensAreSavedByDelayedFlush(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "configs.json"))
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, storage: store,
+		configs: []models.ConfigEntry{{Name: "zshrc", Path: "/home/me/.zshrc"}}}
+
+	m.recordOpens([]string{"/home/me/.zshrc"})
+	if saved, _ := store.Load(); len(saved) != 0 {
+		t.Fatal("registry saved before the flush")
+	}
+	updated, _ := m.Update(flushUsageMsg{})
+	m = updated.(model)
+	saved, err := store.Load()
+	if err != nil || len(saved) != 1 || saved[0].OpenCount != 1 || m.usageDirty {
+		t.Fatalf("after flush: saved = %+v, %v; dirty = %v", saved, err, m.usageDirty)
+	}
+}
+
+func TestFailedLaunchDoesNotRecordOpen(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1,
+		storage: storage.New(filepath.Join(t.TempDir(), "configs.json")),
+		configs: []models.ConfigEntry{{Name: "gone", Path: "/nonexistent/zap-test/file.conf"}}}
+
+	cmd := m.openConfigs(m.configs, "vi", false)
+	updated, _ := m.Update(cmd())
+	m = updated.(model)
+	if m.configs[0].OpenCount != 0 || !m.configs[0].LastOpened.IsZero() {
+		t.Fatalf("failed launch recorded an open: %+v", m.configs[0])
+	}
+}
+
+// fakeEditor installs a sh script named name that takes a moment, as an
+// editor would, then appends its arguments as one line to the returned log.
+func fakeEditor(t *testing.T, name string) (log string) {
+	t.Helper()
+	bin := t.TempDir()
+	log = filepath.Join(bin, "invocations")
+	script := "#!/bin/sh\nsleep 0.1\necho \"$@\" >> " + log + "\n"
+	if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestEditorWaitBlocksOnGUIEditorUntilItExits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a sh script as code")
+	}
+	log := fakeEditor(t, "code")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := storage.New(filepath.Join(dir, "zap-registry.json"))
+	m := newModel(store, []models.ConfigEntry{{Name: "settings", Path: path}}, settings.Settings{Editor: "code", EditorWait: true})
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(m.configs[0])
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter should launch the editor")
+	}
+	msg := cmd()
+	data, err := os.ReadFile(log)
+	if err != nil || strings.TrimSpace(string(data)) != "--wait "+path {
+		t.Fatalf("editor ran with %q (%v), want --wait and the file, finished before the launch returned", data, err)
+	}
+	if status, _ := editor.HandleEditorFinished(msg); status != "Finished editing settings" {
+		t.Fatalf("status = %q, want the edit reported as finished", status)
+	}
+	updated, _ := m.Update(msg)
+	if got := updated.(model).configs[0].OpenCount; got != 1 {
+		t.Fatalf("open count = %d, want the finished edit recorded", got)
+	}
+}
+
+func TestMarkedFilesOpenInOneEditorInvocation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a sh script as code")
+	}
+	log := fakeEditor(t, "code")
+	dir := t.TempDir()
+	var configs []models.ConfigEntry
+	for _, name := range []string{"a.yaml", "b.yaml", "c.yaml"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		configs = append(configs, models.ConfigEntry{Name: name, Path: path})
+	}
+	store := storage.New(filepath.Join(dir, "zap-registry.json"))
+	m := newModel(store, configs, settings.Settings{Editor: "code", EditorWait: true})
+	m.sortMode = 2
+	m.buildDisplayList()
+
+	for _, config := range []models.ConfigEntry{configs[0], configs[2]} {
+		m.cursor = m.findConfigDisplayIndex(config)
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		m = updated.(model)
+	}
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if cmd == nil || len(m.marked) != 0 {
+		t.Fatalf("enter should open the marked files and clear the marks, marked = %v", m.marked)
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(data)), "--wait "+configs[0].Path+" "+configs[2].Path; got != want {
+		t.Fatalf("editor invocations:\n%s\nwant one: %s", got, want)
+	}
+	for i, want := range []int{1, 0, 1} {
+		if got := m.configs[i].OpenCount; got != want {
+			t.Errorf("%s open count = %d, want %d", m.configs[i].Name, got, want)
+		}
+	}
+}
+
+func TestNumberKeysOpenRecentlyOpenedFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	var configs []models.ConfigEntry
+	for i, name := range []string{"old", "older", "newest", "unopened"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		config := models.ConfigEntry{Name: name, Path: path}
+		if name != "unopened" {
+			config.LastOpened = now.Add(-time.Duration(i) * time.Hour)
+		}
+		configs = append(configs, config)
+	}
+	configs[2].LastOpened = now.Add(time.Minute)
+	m := newTestModel(t, configs...)
+	m.dryRun = true
+	m.storage = storage.New(filepath.Join(dir, "zap-registry.json"))
+	m.buildDisplayList()
+
+	if strip := xansi.Strip(m.renderRecentStrip(100)); !strings.Contains(strip, "1 newest 2 old 3 older") {
+		t.Fatalf("recent strip = %q, want the three latest opens numbered", strip)
+	}
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	if cmd == nil {
+		t.Fatal("2 should open the second most recent file")
+	}
+	updated, _ = updated.(model).Update(cmd())
+	m = updated.(model)
+	for i, want := range []int{1, 0, 0, 0} {
+		if got := m.configs[i].OpenCount; got != want {
+			t.Errorf("%s open count = %d, want %d", m.configs[i].Name, got, want)
+		}
+	}
+}
+
+func TestComfortableDensitySpacesRows(t *testing.T) {
+	configs := []models.ConfigEntry{{Name: "alpha", Path: "/a"}, {Name: "beta", Path: "/b"}}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, configs: configs, sortMode: 2}
+	m.buildDisplayList()
+
+	lineOf := func(panel, text string) int {
+		for i, line := range strings.Split(panel, "\n") {
+			if strings.Contains(line, text) {
+				return i
+			}
+		}
+		return -1
+	}
+	compact := m.renderListPanel(40, 12)
+	if lineOf(compact, "beta")-lineOf(compact, "alpha") != 1 {
+		t.Fatalf("compact rows not adjacent:\n%s", compact)
+	}
+	m.settings.Density = "comfortable"
+	comfortable := m.renderListPanel(40, 12)
+	if lineOf(comfortable, "beta")-lineOf(comfortable, "alpha") != 2 {
+		t.Fatalf("comfortable rows not separated by a blank line:\n%s", comfortable)
+	}
+	if got := strings.Count(comfortable, "\n"); got != strings.Count(compact, "\n") {
+		t.Fatalf("comfortable panel is %d lines, compact %d", got, strings.Count(compact, "\n"))
+	}
+}
+
+func TestTruncateUsesEllipsisAndDisplayWidth(t *testing.T) {
+	if got := truncate("/etc/nginx/nginx.conf", 10); got != "/etc/ngin…" {
+		t.Fatalf("truncate = %q", got)
+	}
+	if got := truncate("日本語のファイル", 7); lipgloss.Width(got) > 7 || !strings.HasSuffix(got, "…") {
+		t.Fatalf("truncate wide = %q (width %d)", got, lipgloss.Width(got))
+	}
+	if got := truncate("short", 10); got != "short" {
+		t.Fatalf("truncate short = %q", got)
+	}
+}
+
+func TestExpandedRowShowsPathUnderSelection(t *testing.T) {
+	configs := []models.ConfigEntry{{Name: "nginx", Path: "/etc/nginx/nginx.conf", Project: "infra", Description: "main"}}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, configs: configs, sortMode: 2}
+	m.buildDisplayList()
+
+	updated, _ := m.updateNormal(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	m = updated.(model)
+	panel := m.renderListPanel(60, 10)
+	if !strings.Contains(panel, "nginx · infra") || !strings.Contains(panel, "/etc/nginx/nginx.conf · main") {
+		t.Fatalf("expanded row missing project or path:\n%s", panel)
+	}
+	if got := lipgloss.Height(panel); got != 12 {
+		t.Fatalf("panel height = %d, want 12", got)
+	}
+}
+
+func TestStatusBarShowsCountsSortAndWorkspace(t *testing.T) {
+	configs := []models.ConfigEntry{{Name: "nginx", Path: "/a", Project: "infra"}, {Name: "zshrc", Path: "/b"}, {Name: "old", Path: "/c", Archived: true}}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, configs: configs,
+		searchQuery: "ngi", workspace: "ops", sortMode: 2, usageDirty: true}
+	m.buildDisplayList()
+
+	var parts []string
+	for _, item := range m.listStatusItems() {
+		parts = append(parts, item.prefix+item.value+item.suffix)
+	}
+	got := strings.Join(parts, " | ")
+	for _, want := range []string{"1/2 files", "🔍 'ngi'", "sort name ↑", "ws ops", "● saving"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("status items %q missing %q", got, want)
+		}
+	}
+
+	m.searchQuery = ""
+	m.buildDisplayList()
+	if got := m.countStatus(); got.value != "2" {
+		t.Fatalf("count without a filter = %q, want 2 with the archived file left out", got.value)
+	}
+}
+
+func TestStatusMessagesCarryTheirLevel(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	updated, _ := m.Update(showError("Failed to save: disk full")())
+	m = updated.(model)
+	if m.statusLevel != ui.StatusError {
+		t.Fatalf("statusLevel = %v, want error", m.statusLevel)
+	}
+	items := m.listStatusItems()
+	if len(items) < 2 || items[1].value != "✗ Failed to save: disk full" || items[1].style == nil {
+		t.Fatalf("status items = %+v, want the marked error message", items)
+	}
+
+	plain := ui.StatusStyle(ui.StatusError, false).GetForeground()
+	safe := ui.StatusStyle(ui.StatusError, true).GetForeground()
+	if plain == safe {
+		t.Fatal("colorblind palette should change the error color")
+	}
+}
+
+func TestDateFormatSetting(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	at := now.Add(-3 * time.Hour)
+	cases := []struct {
+		format   string
+		dateOnly bool
+		want     string
+	}{
+		{"", false, "2024-05-10 09:00"},
+		{"iso", true, "2024-05-10"},
+		{"relative", false, "3h ago"},
+		{"02.01.2006", false, "10.05.2024"},
+	}
+	for _, c := range cases {
+		if got := ui.FormatTime(at, now, c.format, c.dateOnly); got != c.want {
+			t.Fatalf("FormatTime(%q) = %q, want %q", c.format, got, c.want)
+		}
+	}
+	if got := ui.FormatTime(now.Add(15*24*time.Hour), now, "relative", true); got != "in 2w" {
+		t.Fatalf("relative future = %q, want in 2w", got)
+	}
+}
+
+func TestTutorialAdvancesInOrderAndIsRememberedOnceSkipped(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "configs.json"))
+	m := newModel(store, nil, settings.Settings{})
+	if !m.tutorial || !strings.Contains(m.View(), "Welcome to zap") {
+		t.Fatal("tour not shown for a new, empty registry")
+	}
+
+	m.advanceTutorial(tutorialSearch)
+	if m.tutorialStep != tutorialAdd {
+		t.Fatalf("step done out of order advanced the tour to %d", m.tutorialStep)
+	}
+	m.advanceTutorial(tutorialAdd)
+	if m.tutorialStep != tutorialSearch {
+		t.Fatalf("step = %d after adding, want %d", m.tutorialStep, tutorialSearch)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.tutorial {
+		t.Fatal("esc didn't skip the tour")
+	}
+	updated, _ = m.Update(flushUsageMsg{})
+	m = updated.(model)
+
+	reloaded := storage.New(store.GetFilePath())
+	if _, err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if newModel(reloaded, nil, settings.Settings{}).tutorial {
+		t.Fatal("tour shown again after being skipped")
+	}
+}
+
+func TestViewInPagerDoesNotCountAsAnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	if err := os.WriteFile(path, []byte("port = 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, dryRun: true,
+		configs: []models.ConfigEntry{{Name: "app", Path: path}, {Name: "gone", Path: path + ".missing"}}, sortMode: 2}
+	m.buildDisplayList()
+
+	viewed := func(m model) model {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+		m = updated.(model)
+		updated, cmd = m.Update(cmd())
+		m = updated.(model)
+		if cmd != nil {
+			updated, _ = m.Update(cmd())
+			m = updated.(model)
+		}
+		return m
+	}
+
+	m = viewed(m)
+	if m.statusMsg != "" || m.configs[0].OpenCount != 0 || m.usageDirty {
+		t.Fatalf("viewing changed state: status %q, opens %d", m.statusMsg, m.configs[0].OpenCount)
+	}
+	m.cursor = 1
+	m = viewed(m)
+	if m.statusLevel != ui.StatusError || !strings.Contains(m.statusMsg, "Failed to view gone") {
+		t.Fatalf("missing file status = %q (%v)", m.statusMsg, m.statusLevel)
+	}
+}
+
+func TestBinaryFilesPreviewAsHexDump(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "app.db")
+	if err := os.WriteFile(binary, append([]byte("SQLite format 3\x00"), make([]byte, 500)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	text := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(text, []byte("héllo\tworld\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := buildPreviewLines(binary, 1<<20, "")
+	if err != nil || len(lines) != 1+previewDumpLen/16 {
+		t.Fatalf("binary preview = %d lines, %v", len(lines), err)
+	}
+	if !strings.HasPrefix(lines[0], "binary file, 516 B") || !strings.Contains(lines[1], "53 51 4c 69") || !strings.HasSuffix(lines[1], "|SQLite format 3.|") {
+		t.Fatalf("binary preview:\n%s", strings.Join(lines[:2], "\n"))
+	}
+
+	if lines, err := buildPreviewLines(text, 1<<20, ""); err != nil || lines[0] != "héllo\tworld" {
+		t.Fatalf("text preview = %q, %v", lines, err)
+	}
+}
+
+func TestPTogglesTheFilePreview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("name: zap\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(t, models.ConfigEntry{Name: "app", Path: path, Type: "yaml"})
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(m.configs[0])
+	if content := xansi.Strip(m.buildRightPanelContent()); !strings.Contains(content, "Preview:") || !strings.Contains(content, "name: zap") {
+		t.Fatalf("details without the preview:\n%s", content)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = updated.(model)
+	if content := xansi.Strip(m.buildRightPanelContent()); strings.Contains(content, "Preview:") || strings.Contains(content, "name: zap") {
+		t.Fatalf("p should hide the preview:\n%s", content)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = updated.(model)
+	if content := xansi.Strip(m.buildRightPanelContent()); !strings.Contains(content, "name: zap") {
+		t.Fatalf("a second p should bring the preview back:\n%s", content)
+	}
+}
+
+func TestLargeFilesArePartlyPreviewedAndNotCopied(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "huge.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("0123456789abcdef\n", 256)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := buildPreviewLines(path, 40, "")
+	if err != nil || len(lines) != 4 || lines[0] != "(large file: showing the first 40 B of 4.2 KB)" || lines[3] != "0123456789abcdef" {
+		t.Fatalf("preview of large file = %q, %v", lines, err)
+	}
+
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1,
+		configs: []models.ConfigEntry{{Name: "huge", Path: path}}, settings: settings.Settings{LargeFileKB: 2}}
+	m.buildDisplayList()
+	for _, key := range []rune{'Y', 'E'} {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		updated, _ = updated.(model).Update(cmd())
+		got := updated.(model)
+		if got.mode != ModeNormal || !strings.Contains(got.statusMsg, "over the 2.0 KB large_file_kb limit") {
+			t.Errorf("%c on a large file: mode %v, status %q", key, got.mode, got.statusMsg)
+		}
+	}
+}
+
+func TestClipboardPathStripsQuotesAndLineNumbers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nginx.conf")
+	if err := os.WriteFile(path, []byte("events {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for text, want := range map[string]string{
+		path + "\n":                      path,
+		`"` + path + `"`:                 path,
+		"file://" + path:                 path,
+		path + ":12":                     path,
+		path + ":40:7\nsecond line":      path,
+		filepath.Join(dir, "new.yml"):    filepath.Join(dir, "new.yml"),
+		"https://example.com/nginx.conf": "",
+		"just some words":                "",
+		"":                               "",
+	} {
+		got, ok := clipboardPath(text)
+		if ok != (want != "") || got != want {
+			t.Errorf("clipboardPath(%q) = %q, %v, want %q", text, got, ok, want)
+		}
+	}
+}
+
+func TestPasteStartsAddWithClipboardPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a sh script as wl-paste")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yml")
+	if err := os.WriteFile(path, []byte("services: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "wl-paste"), []byte("#!/bin/sh\necho '"+path+":3'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.projects = []models.Project{{Name: "webapp", Root: dir}}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlV})
+	m = updated.(model)
+	if m.mode != ModeAdd {
+		t.Fatalf("ctrl+v should start adding, mode = %v", m.mode)
+	}
+	want := models.ConfigEntry{Name: "docker-compose.yml", Path: path, Type: "compose", Project: "webapp"}
+	if m.draft.Name != want.Name || m.draft.Path != want.Path || m.draft.Type != want.Type || m.draft.Project != want.Project {
+		t.Fatalf("draft = %+v, want %+v", m.draft, want)
+	}
+
+	m.configs = []models.ConfigEntry{want}
+	m.mode = ModeNormal
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlV})
+	m = updated.(model)
+	if m.mode != ModeNormal || cmd == nil || !strings.Contains(cmd().(statusMsg).message, "already registered") {
+		t.Fatalf("pasting a registered path should warn instead of adding, mode = %v", m.mode)
+	}
+}
+
+func TestUnopenedFilterShowsOnlyNeverOpenedFiles(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "used", Path: "/tmp/used.conf", LastOpened: time.Now().Add(-time.Hour), OpenCount: 3},
+		{Name: "forgotten", Path: "/tmp/forgotten.conf"},
+	}
+	m.buildDisplayList()
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'U'}})
+	m = updated.(model)
+	filtered := m.getFilteredConfigs()
+	if len(filtered) != 1 || filtered[0].Name != "forgotten" {
+		t.Fatalf("filtered = %+v, want only the never-opened file", filtered)
+	}
+	if msg := cmd().(statusMsg).message; msg != "Showing 1 never-opened files" {
+		t.Fatalf("status = %q", msg)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'U'}})
+	m = updated.(model)
+	if len(m.getFilteredConfigs()) != 2 {
+		t.Fatal("U again should show every file")
+	}
+}
+
+func TestRenamedEntryIsStillFoundByItsOldName(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	original := []models.ConfigEntry{{Name: "httpd conf", Path: "/etc/apache2/apache2.conf", Type: "ini"}}
+	m := model{width: 100, height: 24, storage: store, configs: original, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.buildDisplayList()
+	m.startEdit()
+	m.textInput.SetValue("apache2.conf")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	saved, _ := store.Load()
+	if saved[0].Name != "apache2.conf" || !reflect.DeepEqual(saved[0].PreviousNames, []string{"httpd conf"}) {
+		t.Fatalf("saved entry = %+v, want the old name kept", saved[0])
+	}
+	for _, fuzzy := range []bool{false, true} {
+		m.fuzzyMode = fuzzy
+		m.searchQuery = "httpd conf"
+		if got := m.getFilteredConfigs(); len(got) != 1 {
+			t.Fatalf("search (fuzzy %v) for the old name found %d entries", fuzzy, len(got))
+		}
+	}
+
+	// Renaming back moves the name out of the history instead of repeating it
+	entry := saved[0]
+	entry.Rename("httpd conf")
+	entry.Rename("apache2.conf")
+	if !reflect.DeepEqual(entry.PreviousNames, []string{"httpd conf"}) {
+		t.Fatalf("history = %q, want each old name once", entry.PreviousNames)
+	}
+}
+
+func TestKeywordsAreSearchedButNotShown(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	original := []models.ConfigEntry{{Name: "cluster", Path: "/home/me/.kube/config", Type: "yaml"}}
+	m := model{width: 100, height: 24, storage: store, configs: original, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.buildDisplayList()
+	m.startEdit()
+	m.editCol = 10
+	m.loadEditField()
+	m.textInput.SetValue("k8s, kubernetes,, K8S ")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	saved, _ := store.Load()
+	if !reflect.DeepEqual(saved[0].Keywords, []string{"k8s", "kubernetes"}) {
+		t.Fatalf("keywords = %q, want k8s and kubernetes once each", saved[0].Keywords)
+	}
+	m.searchQuery = "kubernetes"
+	if got := m.getFilteredConfigs(); len(got) != 1 {
+		t.Fatalf("search by keyword found %d entries", len(got))
+	}
+	if matches := quickOpenMatches(m.configs, "k8s", time.Now(), m.searchOptions()); len(matches) != 1 {
+		t.Fatalf("quick open by keyword found %d entries", len(matches))
+	}
+	m.searchQuery = ""
+	m.buildDisplayList()
+	if strings.Contains(m.buildRightPanelContent(), "kubernetes") {
+		t.Fatal("keywords should not be shown in the details pane")
+	}
+}
+
+func TestSearchOperators(t *testing.T) {
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.configs = []models.ConfigEntry{
+		{Name: "deploy", Path: "/srv/app/deploy.yaml", Type: "yaml"},
+		{Name: "fixture", Path: "/srv/app/test/fixture.yaml", Type: "yaml"},
+		{Name: "nginx", Path: "/etc/nginx/nginx.conf", Type: "ini"},
+		{Name: "caddy", Path: "/etc/caddy/Caddyfile", Type: "caddyfile"},
+		{Name: "main site", Path: "/etc/apache2/site.conf", Type: "ini"},
+	}
+	for query, want := range map[string][]string{
+		"yaml -test":          {"deploy"},
+		"nginx OR caddy":      {"nginx", "caddy"},
+		"nginx or caddy":      nil, // lowercase or is just a word
+		"etc -nginx -caddy":   {"main site"},
+		`"main site"`:         {"main site"},
+		`site main`:           {"main site"},
+		`-"main site" -yaml`:  {"nginx", "caddy"},
+		"yaml -test OR caddy": {"deploy", "caddy"},
+	} {
+		m.searchQuery = query
+		var got []string
+		for _, config := range m.getFilteredConfigs() {
+			got = append(got, config.Name)
+		}
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("search %q = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestReadOnlyEntriesOpenInViewModeAndCantBeEditedInline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reference.conf")
+	if err := os.WriteFile(path, []byte("keep = as is\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1,
+		storage: storage.New(filepath.Join(dir, "zap-registry.json")), fileEditArea: textarea.New()}
+	m.configs = []models.ConfigEntry{{Name: "reference", Path: path}, {Name: "notes", Path: path + ".md"}}
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(m.configs[0])
+	key := func(s string) {
+		t.Helper()
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+		m = updated.(model)
+	}
+
+	key("l")
+	if !m.configs[0].ReadOnly {
+		t.Fatal("l should lock the file")
+	}
+	key("E")
+	if m.mode == ModeFileEdit {
+		t.Fatal("a read-only file must not open in the inline editor")
+	}
+
+	if opts, cmd := m.readOnlyOptions(m.configs[:1], "vim"); cmd != nil || !opts.ReadOnly {
+		t.Fatalf("vim should open it with its read-only flag, opts = %+v", opts)
+	}
+	if opts, cmd := m.readOnlyOptions(m.configs[1:], "code"); cmd != nil || opts.ReadOnly {
+		t.Fatal("unlocked files open normally")
+	}
+	if _, cmd := m.readOnlyOptions(m.configs[:1], "code"); cmd == nil {
+		t.Fatal("an editor without a read-only mode should page the file instead")
+	}
+
+	key("l")
+	key("E")
+	if m.configs[0].ReadOnly || m.mode != ModeFileEdit {
+		t.Fatalf("unlocked file should edit inline, mode = %v", m.mode)
+	}
+}
+
+func TestTagEditAndTagFilter(t *testing.T) {
+	store := storage.New(filepath.Join(t.TempDir(), "zap-registry.json"))
+	m := model{width: 100, height: 24, storage: store, editRow: -1, editCol: -1, deleteIndex: -1}
+	m.textInput = textinput.New()
+	m.configs = []models.ConfigEntry{
+		{Name: "grafana", Path: "/etc/grafana.ini", Tags: []string{"monitoring"}},
+		{Name: "nginx", Path: "/etc/nginx.conf"},
+		{Name: "zshrc", Path: "/home/me/.zshrc"},
+	}
+	m.buildDisplayList()
+	m.cursor = m.findConfigDisplayIndex(m.configs[1])
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = updated.(model)
+	if m.mode != ModeTagEdit {
+		t.Fatalf("t should edit tags, mode = %v", m.mode)
+	}
+	m.textInput.SetValue("web, Monitoring, web")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	saved, err := store.Load()
+	if err != nil || len(saved) != 3 || strings.Join(saved[1].Tags, ",") != "web,Monitoring" {
+		t.Fatalf("saved tags = %+v (%v), want web and Monitoring once each", saved, err)
+	}
+	if row := m.columnRow(m.configs[1], "", 80); row != "nginx #web #Monitoring" {
+		t.Fatalf("row = %q, want the tags after the name", row)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	m = updated.(model)
+	m.textInput.SetValue("monitoring")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	filtered := m.getFilteredConfigs()
+	if len(filtered) != 2 || filtered[0].Name != "grafana" || filtered[1].Name != "nginx" {
+		t.Fatalf("tag filter = %+v, want grafana and nginx", filtered)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	m = updated.(model)
+	m.textInput.SetValue("")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if got := m.getFilteredConfigsCount(); got != 3 {
+		t.Fatalf("empty tag filter shows %d files, want all 3", got)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/LFroesch/zap/internal/editor"
+	"github.com/LFroesch/zap/internal/importer"
+	"github.com/LFroesch/zap/internal/models"
+)
+
+// verifyResult describes one registered file that no longer exists.
+type verifyResult struct {
+	index      int
+	candidates []string // likely new locations, best first
+}
+
+// verifyConfigs checks every registered path, refreshing the remembered
+// size of files that exist and looking for renames of those that don't.
+// It reports whether any remembered size changed.
+func verifyConfigs(configs []models.ConfigEntry, opts importer.Options) (missing []verifyResult, changed bool) {
+	registered := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		registered[editor.ExpandPath(config.Path)] = true
+	}
+
+	for i := range configs {
+		path := editor.ExpandPath(configs[i].Path)
+		info, err := os.Stat(path)
+		if err == nil {
+			if !info.IsDir() && configs[i].Size != info.Size() {
+				configs[i].Size = info.Size()
+				changed = true
+			}
+			continue
+		}
+		missing = append(missing, verifyResult{
+			index:      i,
+			candidates: importer.FindRenames(path, configs[i].Size, opts, registered),
+		})
+	}
+	return missing, changed
+}
+
+func runVerify(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("verify")
+	fix := fs.Bool("fix", false, "Update entries that have exactly one likely new location")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageError("verify [--fix]")
+	}
+
+	store, configs, prefs, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+
+	missing, changed := verifyConfigs(configs, importer.DefaultOptions(prefs.IgnorePatterns()))
+	unresolved, fixable := 0, 0
+	for _, result := range missing {
+		config := &configs[result.index]
+		fmt.Fprintf(ctx.stdout, "missing  %s  %s\n", config.Name, config.Path)
+
+		switch {
+		case len(result.candidates) == 1 && *fix:
+			config.Path = result.candidates[0]
+			changed = true
+			fmt.Fprintf(ctx.stdout, "  fixed → %s\n", config.Path)
+			continue
+		case len(result.candidates) == 1:
+			fixable++
+			fmt.Fprintf(ctx.stdout, "  renamed? → %s\n", result.candidates[0])
+		case len(result.candidates) > 1:
+			fmt.Fprintf(ctx.stdout, "  possible new locations:\n")
+			for _, candidate := range result.candidates {
+				fmt.Fprintf(ctx.stdout, "    %s\n", candidate)
+			}
+		}
+		unresolved++
+	}
+
+	pinned, modified := 0, 0
+	for _, config := range configs {
+		if config.Checksum == "" {
+			continue
+		}
+		pinned++
+		sum, err := fileChecksum(editor.ExpandPath(config.Path))
+		if os.IsNotExist(err) {
+			continue // reported as missing above
+		}
+		switch {
+		case err != nil:
+			fmt.Fprintf(ctx.stdout, "unreadable  %s  %v\n", config.Name, err)
+		case sum != config.Checksum:
+			fmt.Fprintf(ctx.stdout, "modified  %s  %s\n  expected %s\n  found    %s\n", config.Name, config.Path, config.Checksum, sum)
+		default:
+			continue
+		}
+		modified++
+	}
+
+	if changed {
+		if err := store.Save(configs); err != nil {
+			return err
+		}
+	}
+	if pinned > 0 {
+		fmt.Fprintf(ctx.stdout, "%d files checked, %d missing, %d of %d pinned files modified\n", len(configs), unresolved, modified, pinned)
+	} else {
+		fmt.Fprintf(ctx.stdout, "%d files checked, %d missing\n", len(configs), unresolved)
+	}
+	if fixable > 0 {
+		fmt.Fprintf(ctx.stdout, "run zap verify --fix to update %d renamed entries\n", fixable)
+	}
+	switch {
+	case unresolved > 0 && modified > 0:
+		return invalidf("%d registered files are missing and %d no longer match their checksum", unresolved, modified)
+	case unresolved > 0:
+		return invalidf("%d registered files are missing", unresolved)
+	case modified > 0:
+		return invalidf("%d registered files no longer match their checksum", modified)
+	}
+	return nil
+}
+
+// fileChecksum returns the SHA-256 of a file's contents as "sha256:<hex>",
+// the form stored in an entry's checksum.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// shortChecksum abbreviates a checksum for display.
+func shortChecksum(sum string) string {
+	algo, digest, ok := strings.Cut(sum, ":")
+	if !ok || len(digest) <= 12 {
+		return sum
+	}
+	return algo + ":" + digest[:12]
+}
+
+func runChecksum(ctx *cliContext, args []string) error {
+	const usage = "checksum [--clear] (--all | <name>...)"
+	fs := ctx.flagSet("checksum")
+	all := fs.Bool("all", false, "Pin every registered file that exists")
+	clearSums := fs.Bool("clear", false, "Remove the pinned checksums instead")
+	names, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if *all == (len(names) > 0) {
+		return usageError(usage)
+	}
+
+	store, configs, _, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+	var targets []int
+	if *all {
+		for i := range configs {
+			if !configs[i].Shared {
+				targets = append(targets, i)
+			}
+		}
+	}
+	for _, name := range names {
+		config, err := resolveEntry(configs, name, false)
+		if err != nil {
+			return err
+		}
+		for i := range configs {
+			if configs[i].Equals(&config) {
+				targets = append(targets, i)
+			}
+		}
+	}
+
+	for _, i := range targets {
+		config := &configs[i]
+		if *clearSums {
+			config.Checksum = ""
+			fmt.Fprintf(ctx.stdout, "cleared  %s\n", config.Name)
+			continue
+		}
+		sum, err := fileChecksum(editor.ExpandPath(config.Path))
+		if err != nil {
+			if *all && os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		config.Checksum = sum
+		config.Shared = false
+		fmt.Fprintf(ctx.stdout, "pinned  %s  %s\n", config.Name, shortChecksum(sum))
+	}
+	return store.Save(configs)
+}
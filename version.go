@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Build details, set with -ldflags "-X main.version=... -X main.commit=...
+// -X main.buildDate=..." by the Makefile and the release workflow.
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// buildInfo returns the commit and build time, falling back to the VCS
+// details the Go toolchain embeds in binaries built from a checkout.
+func buildInfo() (rev, date string, modified bool) {
+	rev, date = commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if rev == "" {
+					rev = s.Value
+				}
+			case "vcs.time":
+				if date == "" {
+					date = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	return rev, date, modified
+}
+
+// versionLine is the one-line version shown by zap version and the help
+// screen, e.g. "zap v1.4.0 (3f2c1a9b0d4e, built 2024-05-01T10:00:00Z)".
+func versionLine() string {
+	rev, date, modified := buildInfo()
+	var details []string
+	if rev != "" {
+		if modified {
+			rev += "-dirty"
+		}
+		details = append(details, rev)
+	}
+	if date != "" {
+		details = append(details, "built "+date)
+	}
+	if len(details) == 0 {
+		return "zap " + version
+	}
+	return fmt.Sprintf("zap %s (%s)", version, strings.Join(details, ", "))
+}
+
+func runVersion(ctx *cliContext, args []string) error {
+	fs := ctx.flagSet("version")
+	rest, err := ctx.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageError("version")
+	}
+	fmt.Fprintln(ctx.stdout, versionLine())
+	fmt.Fprintf(ctx.stdout, "%s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return nil
+}
@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/LFroesch/zap/suitechrome"
+	"github.com/LFroesch/zap/internal/models"
 	"github.com/LFroesch/zap/internal/ui"
 
 	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
 )
 
 func (m model) View() string {
@@ -34,34 +36,89 @@ func (m model) View() string {
 
 	// Main content
 	var mainContent string
-	if len(m.configs) == 0 {
+	if m.mode == ModeImport {
+		mainContent = m.renderImportPanel()
+	} else if m.mode == ModeStats {
+		mainContent = m.renderStatsPanel()
+	} else if m.mode == ModeTail {
+		mainContent = m.renderTailPanel()
+	} else if m.mode == ModeRunResult {
+		mainContent = m.renderRunPanel()
+	} else if m.mode == ModeActions {
+		mainContent = m.renderActionsPanel()
+	} else if m.mode == ModeRegistryPicker {
+		mainContent = m.renderRegistryPanel()
+	} else if m.mode == ModeJump {
+		mainContent = m.renderJumpPanel()
+	} else if m.mode == ModeQuickOpen {
+		mainContent = m.renderQuickOpenPanel()
+	} else if m.mode == ModeConfirmBatchDelete {
+		mainContent = m.renderBatchDeletePanel()
+	} else if m.mode == ModeSecret {
+		mainContent = m.renderSecretPanel()
+	} else if len(m.configs) == 0 {
 		mainContent = m.renderEmptyState()
 	} else {
 		mainContent = m.renderConfigList()
 	}
 
 	// Combine all sections
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		header,
-		mainContent,
-		statusBar,
-	)
+	sections := []string{header, mainContent}
+	if m.editAssistHeight() > 0 {
+		sections = append(sections, m.renderEditAssistBar())
+	}
+	sections = append(sections, statusBar)
 
-	return content
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
-func (m model) renderHeader() string {
-	sortIcons := []string{"📂", "🕐", "🔤", "📁"}
-	sortNames := []string{"project", "recent", "name", "path"}
+// renderEditAssistBar shows validation feedback for the active edit field,
+// or its completion candidates with the one → would accept highlighted.
+func (m model) renderEditAssistBar() string {
+	if hint, isError := m.editFieldHint(); hint != "" {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Inline(true)
+		icon := "⚠ "
+		if isError {
+			style = style.Foreground(lipgloss.Color("196")).Bold(true)
+			icon = "✖ "
+		}
+		return suitechrome.JoinLine(m.width, style.Render(icon+hint), "")
+	}
 
-	var searchIndicator string
-	if m.searchQuery != "" {
-		searchIndicator = " [searching]"
+	current := m.textInput.CurrentSuggestion()
+	suggestions := m.visibleSuggestions()
+	hintActions := []suitechrome.Action{
+		{Key: "→", Label: "accept"},
+		{Key: "↑/↓", Label: "cycle"},
+	}
+	if m.editCol == 3 {
+		if m.typeCursor < len(suggestions) {
+			current = suggestions[m.typeCursor]
+		}
+		// Keep the highlighted type in view on narrow terminals.
+		if start := m.typeCursor - 2; start > 0 && start < len(suggestions) {
+			suggestions = suggestions[start:]
+		}
+		hintActions = []suitechrome.Action{
+			{Key: "type", Label: "filter"},
+			{Key: "↑/↓", Label: "choose"},
+		}
+	}
+	parts := []string{suitechrome.Dim("↳")}
+	for _, s := range suggestions {
+		if s == current {
+			parts = append(parts, lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62")).Render(s))
+		} else {
+			parts = append(parts, lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Render(s))
+		}
 	}
+	hint := suitechrome.RenderActions(hintActions)
+	return suitechrome.JoinLine(m.width, strings.Join(parts, " "), hint)
+}
 
+func (m model) renderHeader() string {
 	left := suitechrome.RenderTitle("zap", version) + " - files registry"
-	right := fmt.Sprintf("[%s %s]%s", sortIcons[m.sortMode], sortNames[m.sortMode], searchIndicator)
-	return suitechrome.JoinHeader(m.width, left, suitechrome.Dim(right))
+	return suitechrome.JoinHeader(m.width, left, "")
 }
 
 func (m model) renderEmptyState() string {
@@ -71,7 +128,10 @@ func (m model) renderEmptyState() string {
 		Foreground(lipgloss.Color("244")).
 		Padding(1, 0)
 
-	emptyContent := emptyStyle.Render("📋 No files registered yet.\n\n💡 Press 'n' to add your first file!")
+	emptyContent := emptyStyle.Render("📋 No files registered yet.\n\n💡 Press 'N' to add your first file!")
+	if m.tutorial {
+		emptyContent = lipgloss.NewStyle().Padding(1, 0).Render(m.renderTutorial(m.width - 6))
+	}
 
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -83,33 +143,56 @@ func (m model) renderEmptyState() string {
 }
 
 func (m model) renderConfigList() string {
-	availableHeight := m.mainContentHeight()
+	// One line below the panels is the selected entry's detail bar
+	availableHeight := m.mainContentHeight() - 1
 	panelHeight := availableHeight - 2
 	if panelHeight < 3 {
 		panelHeight = 3
 	}
 
-	leftWidth := m.width * 38 / 100
-	if leftWidth < 18 {
-		leftWidth = 18
-	}
-	rightWidth := m.width - leftWidth - 1
-	if rightWidth < 12 {
-		rightWidth = 12
-		leftWidth = m.width - rightWidth - 1
-	}
-
+	leftWidth, rightWidth := m.panelWidths()
 	leftPanel := m.renderListPanel(leftWidth, panelHeight)
 	rightPanel := m.renderDetailsPanel(rightWidth, panelHeight)
 
 	leftStyled := lipgloss.NewStyle().Height(availableHeight).Render(leftPanel)
 	rightStyled := lipgloss.NewStyle().Height(availableHeight).Render(rightPanel)
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, leftStyled, " ", rightStyled)
+	panels := lipgloss.JoinHorizontal(lipgloss.Top, leftStyled, " ", rightStyled)
+	return lipgloss.JoinVertical(lipgloss.Left, panels, m.renderDetailBar())
+}
+
+// panelWidths splits the screen between the file list and the details
+// panel, with a one-cell gap between them.
+func (m model) panelWidths() (left, right int) {
+	left = m.width * 38 / 100
+	if left < 18 {
+		left = 18
+	}
+	right = m.width - left - 1
+	if right < 12 {
+		right = 12
+		left = m.width - right - 1
+	}
+	return left, right
+}
+
+// renderDetailBar shows the full name, path and description of the selected
+// entry, which the list and details panel may cut short.
+func (m model) renderDetailBar() string {
+	config := m.getConfigByDisplayIndex(m.cursor)
+	if config == nil {
+		return ""
+	}
+	line := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")).Render(config.Name) +
+		"  " + lipgloss.NewStyle().Foreground(lipgloss.Color("117")).Render(config.Path)
+	if config.Description != "" {
+		line += suitechrome.Dim("  · " + config.Description)
+	}
+	return " " + truncate(line, m.width-2)
 }
 
 func (m model) renderHelpPanel() string {
-	return ui.HelpPanel(m.width, m.mainContentHeight(), m.helpScroll)
+	return ui.HelpPanel(m.width, m.mainContentHeight(), m.helpScroll, versionLine())
 }
 
 func (m model) renderListPanel(width, panelHeight int) string {
@@ -118,13 +201,33 @@ func (m model) renderListPanel(width, panelHeight int) string {
 		innerWidth = 12
 	}
 	var items []string
-	items = append(items, lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("117")).Render("Files"))
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("117")).Render("Files")
+	if scrolled := scrolledColumns(m.columnLayout(innerWidth - m.markWidth())); scrolled != "" {
+		title += suitechrome.Dim("  " + truncate(scrolled, innerWidth-7))
+	}
+	items = append(items, title)
+	if strip := m.renderRecentStrip(innerWidth); strip != "" {
+		items = append(items, strip)
+	}
 	items = append(items, "")
+	spacerIdx := len(items) - 1
+	if header := m.columnHeader(innerWidth); header != "" {
+		items = append(items, header)
+	}
 
 	maxVisible := panelHeight - len(items)
 	// Match sb behavior: keep one row available for potential bottom indicator
 	// so visible entries never get clipped when indicator appears.
 	maxVisible--
+	if m.expandRow {
+		// The selected row takes a second line
+		maxVisible--
+	}
+	comfortable := m.settings.Comfortable()
+	if comfortable {
+		// Rows are separated by a blank line
+		maxVisible = (maxVisible + 1) / 2
+	}
 	if maxVisible < 1 {
 		maxVisible = 1
 	}
@@ -149,35 +252,72 @@ func (m model) renderListPanel(width, panelHeight int) string {
 		endIdx = totalRows
 	}
 
+	now := time.Now()
 	for i := startIdx; i < endIdx && i < len(m.displayConfigs); i++ {
 		display := m.displayConfigs[i]
+		if comfortable && i > startIdx {
+			items = append(items, "")
+		}
 
 		if display.isHeader {
 			header := truncate(display.headerText, innerWidth)
-			line := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render(header)
+			line := lipgloss.NewStyle().Bold(true).Foreground(m.projectColor(display.project)).Render(header)
+			if project := m.findProject(display.project); project != nil && project.Description != "" {
+				if room := innerWidth - lipgloss.Width(header) - 3; room > 3 {
+					line += suitechrome.Dim(" · " + truncate(project.Description, room))
+				}
+			}
 			items = append(items, line)
 			continue
 		}
 
 		config := display.config
-		name := config.Name
-		rawLine := name
+		var badges string
+		if config.ReviewOverdue(now) {
+			badges += " ⏰"
+		}
+		if config.Expired(now) {
+			badges += " ⌛"
+		}
+		if config.Archived {
+			badges += " 📦"
+		}
+		if config.Shared {
+			badges += " ⇅"
+		}
+		if m.changedSinceStart(*config) {
+			badges += " ✱"
+		}
+		badges += m.checkBadge(*config)
+		rawLine := m.columnRow(*config, badges, innerWidth-m.markWidth())
+		if len(m.marked) > 0 {
+			if m.marked[config.Path] {
+				rawLine = "● " + rawLine
+			} else {
+				rawLine = "  " + rawLine
+			}
+		}
 		rawLine = truncate(rawLine, innerWidth)
 
 		if i == m.cursor {
-			items = append(items, lipgloss.NewStyle().
+			selected := lipgloss.NewStyle().
 				Foreground(lipgloss.Color("230")).
 				Background(lipgloss.Color("62")).
-				Width(innerWidth).
-				Render(rawLine))
+				Width(innerWidth)
+			if m.expandRow {
+				for _, line := range expandedRowLines(*config, rawLine, innerWidth) {
+					items = append(items, selected.Render(line))
+				}
+				continue
+			}
+			items = append(items, selected.Render(rawLine))
 		} else {
-			line := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Render(rawLine)
-			items = append(items, lipgloss.NewStyle().Width(innerWidth).Render(line))
+			items = append(items, ui.RowStyle(i, m.settings.Stripes).Width(innerWidth).Render(rawLine))
 		}
 	}
 
-	if startIdx > 0 && len(items) > 1 {
-		items[1] = lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(fmt.Sprintf("▲ %d more", startIdx))
+	if startIdx > 0 && len(items) > spacerIdx {
+		items[spacerIdx] = lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(fmt.Sprintf("▲ %d more", startIdx))
 	}
 	if endIdx < totalRows {
 		items = append(items, lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(fmt.Sprintf("▼ %d more", totalRows-endIdx)))
@@ -198,6 +338,40 @@ func (m model) renderListPanel(width, panelHeight int) string {
 		Render(panelContent)
 }
 
+// expandedRowLines splits the selected row across two lines: the name line
+// with the project appended, then the path and description indented below.
+func expandedRowLines(config models.ConfigEntry, nameLine string, width int) []string {
+	project := config.Project
+	if project == "" {
+		project = "General"
+	}
+	first := nameLine
+	if room := width - lipgloss.Width(nameLine) - 3; room > 3 {
+		first += " · " + truncate(project, room)
+	}
+	second := "  " + config.Path
+	if config.Description != "" {
+		second += " · " + config.Description
+	}
+	return []string{first, truncate(second, width)}
+}
+
+// renderRecentStrip renders the speed-dial line of recently opened files,
+// each prefixed with the number key that opens it.
+func (m model) renderRecentStrip(width int) string {
+	recent := m.recentConfigs(recentStripSize)
+	if len(recent) == 0 {
+		return ""
+	}
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	parts := []string{"⚡"}
+	for i, config := range recent {
+		parts = append(parts, keyStyle.Render(fmt.Sprintf("%d", i+1))+" "+nameStyle.Render(config.Name))
+	}
+	return suitechrome.JoinLine(width, strings.Join(parts, " "), "")
+}
+
 func (m model) renderDetailsPanel(width, panelHeight int) string {
 	contentWidth := width - 4
 	if contentWidth < 12 {
@@ -209,6 +383,8 @@ func (m model) renderDetailsPanel(width, panelHeight int) string {
 		header := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("117")).Render("Editing") +
 			lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("  ctrl+s save · ctrl+d del line · esc cancel")
 		panelContent = lipgloss.JoinVertical(lipgloss.Left, header, "", m.fileEditArea.View())
+	} else if m.tutorial {
+		panelContent = m.renderTutorial(contentWidth - 8)
 	} else {
 		m.rightViewport.Width = contentWidth
 		m.rightViewport.Height = panelHeight
@@ -244,7 +420,7 @@ func (m model) renderStatusBar() string {
 	// Mode-specific status
 	switch m.mode {
 	case ModeEdit, ModeAdd:
-		colNames := []string{"Name", "Project", "Path", "Description"}
+		colNames := []string{"Name", "Project", "Path", "Type", "Description", "Review every", "Run", "Alias", "Source", "Secret", "Keywords", "Expires"}
 		colName := colNames[m.editCol]
 
 		prefix := "✏️  Editing"
@@ -259,6 +435,14 @@ func (m model) renderStatusBar() string {
 			suitechrome.Action{Key: "esc", Label: "cancel"},
 		)
 
+	case ModeProjectEdit:
+		statusText = orangeStyle.Render("📂 "+m.projDraft.Name+" "+projectFieldNames[m.projCol]+": ") + whiteStyle.Render(m.textInput.View())
+		rightSide = actions(
+			suitechrome.Action{Key: "tab", Label: "next"},
+			suitechrome.Action{Key: "enter", Label: "save"},
+			suitechrome.Action{Key: "esc", Label: "cancel"},
+		)
+
 	case ModeFileEdit:
 		statusText = orangeStyle.Render("Editing file inline: ") + whiteStyle.Render(m.fileEditLabel)
 		rightSide = actions(
@@ -267,11 +451,184 @@ func (m model) renderStatusBar() string {
 			suitechrome.Action{Key: "esc", Label: "cancel"},
 		)
 
+	case ModeTagEdit:
+		label := "🏷  Tags for " + m.tags.targets[0].Name + ": "
+		if len(m.tags.targets) > 1 {
+			label = fmt.Sprintf("🏷  Add tags to %d files: ", len(m.tags.targets))
+		}
+		statusText = orangeStyle.Render(label) + whiteStyle.Render(m.textInput.View())
+		rightSide = actions(
+			suitechrome.Action{Key: "→", Label: "complete"},
+			suitechrome.Action{Key: "enter", Label: "save"},
+			suitechrome.Action{Key: "esc", Label: "cancel"},
+		)
+
+	case ModeRegistryPicker:
+		if m.registries.creating {
+			statusText = orangeStyle.Render("🗂  New registry: ") + whiteStyle.Render(m.textInput.View())
+			rightSide = actions(
+				suitechrome.Action{Key: "enter", Label: "create & switch"},
+				suitechrome.Action{Key: "esc", Label: "cancel"},
+			)
+		} else {
+			statusText = orangeStyle.Render("🗂  Registries")
+			rightSide = actions(
+				suitechrome.Action{Key: "j/k", Label: "move"},
+				suitechrome.Action{Key: "enter", Label: "switch"},
+				suitechrome.Action{Key: "n", Label: "new"},
+				suitechrome.Action{Key: "esc", Label: "close"},
+			)
+		}
+
+	case ModeTagFilter:
+		statusText = orangeStyle.Render("🏷  Show tags: ") + whiteStyle.Render(m.textInput.View())
+		rightSide = actions(
+			suitechrome.Action{Key: "→", Label: "complete"},
+			suitechrome.Action{Key: "enter", Label: "filter (empty shows all)"},
+			suitechrome.Action{Key: "esc", Label: "cancel"},
+		)
+
+	case ModeImportDir:
+		statusText = orangeStyle.Render("Scan directory or path list: ") + whiteStyle.Render(m.textInput.View())
+		rightSide = actions(
+			suitechrome.Action{Key: "enter", Label: "scan"},
+			suitechrome.Action{Key: "ctrl+r", Label: "recent files"},
+			suitechrome.Action{Key: "esc", Label: "cancel"},
+		)
+
+	case ModeImport:
+		statusText = orangeStyle.Render("📥 Import") + whiteStyle.Render(fmt.Sprintf(" %d files", m.imp.selectedCount()))
+		if m.imp.filtering {
+			rightSide = actions(suitechrome.Action{Key: "enter/esc", Label: "done filtering"})
+		} else {
+			rightSide = actions(
+				suitechrome.Action{Key: "space", Label: "toggle"},
+				suitechrome.Action{Key: "a", Label: "all"},
+				suitechrome.Action{Key: "/", Label: "filter"},
+				suitechrome.Action{Key: "enter", Label: "import"},
+				suitechrome.Action{Key: "esc", Label: "cancel"},
+			)
+		}
+
+	case ModeStats:
+		statusText = orangeStyle.Render("📊 Stats")
+		items := []suitechrome.Action{{Key: "j/k", Label: "scroll"}}
+		if n := min(len(m.stats.deleted), statsDeletedLimit); n == 1 {
+			items = append(items, suitechrome.Action{Key: "1", Label: "restore deleted"})
+		} else if n > 1 {
+			items = append(items, suitechrome.Action{Key: fmt.Sprintf("1-%d", n), Label: "restore deleted"})
+		}
+		rightSide = actions(append(items, suitechrome.Action{Key: "esc/i", Label: "close"})...)
+
+	case ModeActions:
+		if m.actions.confirming {
+			statusText = orangeStyle.Render(fmt.Sprintf("%s for %s?", m.actions.items[m.actions.cursor].label, m.actions.config.Name))
+			rightSide = actions(
+				suitechrome.Action{Key: "y", Label: "run"},
+				suitechrome.Action{Key: "n/esc", Label: "cancel"},
+			)
+			break
+		}
+		statusText = orangeStyle.Render("⚙ Actions")
+		rightSide = actions(
+			suitechrome.Action{Key: "j/k", Label: "move"},
+			suitechrome.Action{Key: "enter", Label: "run"},
+			suitechrome.Action{Key: "esc", Label: "close"},
+		)
+
+	case ModeJump:
+		statusText = orangeStyle.Render("@ " + m.jump.config.Name)
+		rightSide = actions(
+			suitechrome.Action{Key: "type", Label: "filter"},
+			suitechrome.Action{Key: "↑/↓", Label: "move"},
+			suitechrome.Action{Key: "enter", Label: "open here"},
+			suitechrome.Action{Key: "esc", Label: "close"},
+		)
+
+	case ModeQuickOpen:
+		statusText = orangeStyle.Render("⚡ Quick open")
+		rightSide = actions(
+			suitechrome.Action{Key: "type", Label: "search"},
+			suitechrome.Action{Key: "↑/↓", Label: "move"},
+			suitechrome.Action{Key: "enter", Label: "open"},
+			suitechrome.Action{Key: "esc", Label: "close"},
+		)
+
+	case ModeRunResult:
+		statusText = orangeStyle.Render("▶ " + m.run.config.Name)
+		rightSide = actions(
+			suitechrome.Action{Key: "j/k", Label: "scroll"},
+			suitechrome.Action{Key: "x", Label: "run again"},
+			suitechrome.Action{Key: "esc", Label: "close"},
+		)
+
+	case ModeConfirmBatchDelete:
+		statusText = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true).Inline(true).
+			Render(fmt.Sprintf("🗑️  Type %d to confirm: ", len(m.batchDelete.targets))) + whiteStyle.Render(m.textInput.View())
+		rightSide = actions(
+			suitechrome.Action{Key: "↑/↓", Label: "scroll"},
+			suitechrome.Action{Key: "enter", Label: "delete"},
+			suitechrome.Action{Key: "esc", Label: "cancel"},
+		)
+
+	case ModeColumns:
+		column := m.arranged()[m.columns.cursor]
+		width := "auto width"
+		if column.Width > 0 {
+			width = fmt.Sprintf("%d wide", column.Width)
+		}
+		label := "▥ Columns: "
+		if m.columns.preset >= 0 {
+			label = fmt.Sprintf("▥ Columns up to %d wide: ", m.settings.ColumnPresets[m.columns.preset].MaxWidth)
+		}
+		statusText = orangeStyle.Render(label) + whiteStyle.Render(columnLabels[column.Field]+", "+width)
+		rightSide = actions(
+			suitechrome.Action{Key: "←/→", Label: "select"},
+			suitechrome.Action{Key: "shift+←/→", Label: "move"},
+			suitechrome.Action{Key: "+/-", Label: "width"},
+			suitechrome.Action{Key: "a/x", Label: "add/remove"},
+			suitechrome.Action{Key: "enter", Label: "save"},
+			suitechrome.Action{Key: "esc", Label: "cancel"},
+		)
+
+	case ModeSecret:
+		statusText = orangeStyle.Render("🔑 " + m.secret.config.Name)
+		items := []suitechrome.Action{
+			{Key: "space", Label: "reveal/hide"},
+			{Key: "y", Label: "copy"},
+		}
+		if m.secret.config.Run != "" {
+			items = append(items, suitechrome.Action{Key: "x", Label: "run with $ZAP_SECRET"})
+		}
+		rightSide = actions(append(items, suitechrome.Action{Key: "esc", Label: "close"})...)
+
+	case ModeTail:
+		statusText = orangeStyle.Render(m.tailStatus())
+		rightSide = actions(
+			suitechrome.Action{Key: "j/k", Label: "scroll"},
+			suitechrome.Action{Key: "f", Label: "follow"},
+			suitechrome.Action{Key: "o", Label: "editor"},
+			suitechrome.Action{Key: "esc", Label: "close"},
+		)
+
+	case ModeOpenWith:
+		statusText = orangeStyle.Render("Open with: ") + whiteStyle.Render(m.textInput.View())
+		rightSide = actions(
+			suitechrome.Action{Key: "→", Label: "complete"},
+			suitechrome.Action{Key: "enter", Label: "open & remember"},
+			suitechrome.Action{Key: "esc", Label: "cancel"},
+		)
+
 	case ModeSearch:
 		matchCount := m.getFilteredConfigsCount()
-		statusText = orangeStyle.Render("🔍 Search: ") + whiteStyle.Render(m.searchInput.View())
+		label := "🔍 Search: "
+		if m.fuzzyMode {
+			label = "🔍 Fuzzy: "
+		}
+		statusText = orangeStyle.Render(label) + whiteStyle.Render(m.searchInput.View())
 		rightSide = whiteStyle.Render(fmt.Sprintf("%d matches  ", matchCount)) +
 			actions(
+				suitechrome.Action{Key: "ctrl+f", Label: "fuzzy"},
 				suitechrome.Action{Key: "enter", Label: "apply"},
 				suitechrome.Action{Key: "esc", Label: "cancel"},
 			)
@@ -299,42 +656,38 @@ func (m model) renderStatusBar() string {
 			suitechrome.Action{Key: "n/esc", Label: "no"},
 		)
 
-	default:
-		// File count
-		if len(m.displayConfigs) > 0 {
-			configCount := 0
-			for _, d := range m.displayConfigs {
-				if !d.isHeader {
-					configCount++
-				}
-			}
-			statusText = orangeStyle.Render(fmt.Sprintf("%d", configCount)) + whiteStyle.Render(" files")
-		} else {
-			statusText = orangeStyle.Render("0") + whiteStyle.Render(" files")
-		}
-
-		if m.statusMsg != "" && time.Now().Before(m.statusExpiry) {
-			statusText += whiteStyle.Render(" | " + m.statusMsg)
-		}
-
-		if m.searchQuery != "" {
-			statusText += whiteStyle.Render(" | ") + orangeStyle.Render(fmt.Sprintf("🔍 '%s'", m.searchQuery))
+	case ModeConfirmOpen:
+		what := "'" + m.pendingOpen.targets[0].Name + "'"
+		if n := len(m.pendingOpen.targets); n > 1 {
+			what = fmt.Sprintf("%d files", n)
 		}
-
-		greenStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("82")).
-			Background(lipgloss.Color("235")).
+		statusText = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
 			Bold(true).
-			Inline(true)
+			Inline(true).
+			Render(fmt.Sprintf("🔒 Open %s? ", what))
+		rightSide = actions(
+			suitechrome.Action{Key: "y", Label: "open"},
+			suitechrome.Action{Key: "n/esc", Label: "cancel"},
+		)
 
-		redStyle := lipgloss.NewStyle().
+	case ModeConfirmQuit:
+		what := "unsaved changes"
+		if m.quitReturnMode == ModeAdd {
+			what = "an unfinished new file"
+		}
+		statusText = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("196")).
-			Background(lipgloss.Color("235")).
 			Bold(true).
-			Inline(true)
+			Inline(true).
+			Render(fmt.Sprintf("⚠️  Quit with %s? ", what))
+		rightSide = actions(
+			suitechrome.Action{Key: "y", Label: "quit"},
+			suitechrome.Action{Key: "n/esc", Label: "keep editing"},
+		)
 
-		_ = greenStyle
-		_ = redStyle
+	default:
+		statusText = renderStatusItems(m.listStatusItems(), orangeStyle, whiteStyle)
 		rightSide = actions(
 			suitechrome.Action{Key: "o", Label: "open"},
 			suitechrome.Action{Key: "E", Label: "inline"},
@@ -357,13 +710,11 @@ func (m model) renderStatusBar() string {
 	return suitechrome.JoinLine(m.width, statusText, rightSide)
 }
 
-// truncate truncates a string to maxLen, adding "..." if truncated
+// truncate shortens s to maxLen terminal cells, ending it with "…" if cut.
+// Styled text and wide characters are measured by their displayed width.
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	if maxLen <= 3 {
-		return "..."
+	if maxLen < 1 {
+		return ""
 	}
-	return s[:maxLen-3] + "..."
+	return xansi.Truncate(s, maxLen, "…")
 }
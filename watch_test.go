@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LFroesch/zap/internal/models"
+	"github.com/LFroesch/zap/internal/watch"
+)
+
+func TestWatcherFlagsRewrittenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("a: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := watch.New([]string{path})
+	if err != nil {
+		t.Skipf("file watching unavailable: %v", err)
+	}
+	defer watcher.Close()
+
+	m := model{width: 100, height: 24, editRow: -1, editCol: -1, deleteIndex: -1, watcher: watcher}
+	m.configs = []models.ConfigEntry{{Name: "app", Path: path}}
+	m.buildDisplayList()
+
+	got := make(chan any, 1)
+	go func() { got <- watcher.Next()() }()
+	if err := os.WriteFile(path, []byte("a: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-got:
+		updated, cmd := m.Update(msg)
+		if !updated.(model).changedSinceStart(m.configs[0]) {
+			t.Fatalf("entry not flagged after %#v", msg)
+		}
+		if cmd == nil {
+			t.Fatal("expected the watcher to keep listening")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change reported")
+	}
+}